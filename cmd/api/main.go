@@ -2,15 +2,24 @@ package api
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/pkg/listener"
 	"github.com/oswaldom-code/api-template-gin/pkg/log"
+	"github.com/oswaldom-code/api-template-gin/pkg/upgrade"
 	"github.com/oswaldom-code/api-template-gin/src/adapters/cli"
+	grpcadapter "github.com/oswaldom-code/api-template-gin/src/adapters/grpc"
 	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/infrastructure"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/sse"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/websocket"
+	Service "github.com/oswaldom-code/api-template-gin/src/application/system_services"
 	"github.com/spf13/cobra"
 )
 
@@ -34,21 +43,77 @@ func newCliCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "cli",
 		Short: "Run CLI utilities",
-		Long:  `Execute CLI utility functions like database health checks.`,
+		Long:  `Execute CLI utility functions like database health checks and maintenance mode toggles.`,
 		RunE:  cli.RunCliCmd,
 	}
-	cmd.Flags().StringP("function", "f", "", "Function to execute (e.g., test)")
+	cmd.Flags().StringP("function", "f", "", "Function to execute: test, maintenance-on, maintenance-off or maintenance-status")
+	cmd.Flags().String("message", "", "Maintenance message (the configured one when empty)")
+	cmd.Flags().Duration("retry-after", 0, "Maintenance Retry-After (the configured one when 0)")
 	return cmd
 }
 
+// listen opens the listener described by spec with the configured socket mode, or
+// takes it over from the process replaced by an upgrade.
+func listen(upgrader *upgrade.Upgrader, spec string, serverConfig config.ServerConfig) net.Listener {
+	socketMode, err := strconv.ParseUint(serverConfig.SocketMode, 8, 32)
+	if err != nil {
+		log.Fatal("Invalid socket mode:", log.Fields{"mode": serverConfig.SocketMode, "error": err.Error()})
+	}
+	ln, err := upgrader.Listen(spec, listener.Options{SocketMode: os.FileMode(socketMode)})
+	if err != nil {
+		log.Fatal("Failed to listen:", log.Fields{"listen": spec, "error": err.Error()})
+	}
+	return ln
+}
+
+// serve runs srv on ln in the background until it is shut down.
+func serve(name string, srv *http.Server, ln net.Listener) {
+	go func() {
+		log.Info(name+" running", log.Fields{"listen": ln.Addr().String()})
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			log.Fatal(name+" failed:", log.Fields{"error": err.Error()})
+		}
+	}()
+}
+
+// maintenanceSync applies the maintenance settings of the configuration when they
+// changed since last applied, so a reload for other settings keeps the mode set
+// through the admin endpoint or the CLI.
+type maintenanceSync struct {
+	applied *config.MaintenanceConfig
+}
+
+func (m *maintenanceSync) apply(cfg config.MaintenanceConfig) {
+	if m.applied != nil && m.applied.Enabled == cfg.Enabled && m.applied.Message == cfg.Message &&
+		m.applied.RetryAfter == cfg.RetryAfter {
+		return
+	}
+	m.applied = &cfg
+	maintenance := Service.MaintenanceService()
+	if cfg.Enabled {
+		maintenance.Enable(cfg.Message, cfg.RetryAfter)
+	} else {
+		maintenance.Disable()
+	}
+	log.Info("Maintenance mode configured", log.Fields{"enabled": cfg.Enabled})
+}
+
 func StartServer() {
+	var maintenance maintenanceSync
+	maintenance.apply(config.GetMaintenanceConfig())
 	r := infrastructure.NewServer()
-	uri := config.GetServerConfig().AsUri()
+	serverConfig := config.GetServerConfig()
+	upgrader, err := upgrade.New(serverConfig.UpgradeTimeout)
+	if err != nil {
+		log.Fatal("Failed to take over the listeners:", log.Fields{"error": err.Error()})
+	}
 
 	srv := &http.Server{
-		Addr:    uri,
 		Handler: r,
 	}
+	// end the open event streams, Shutdown would otherwise wait for them until its deadline
+	srv.RegisterOnShutdown(sse.SharedBroker().Close)
+	servers := []*http.Server{srv}
 
 	logCfg := config.GetLogConfig()
 	logConfig := log.LogConfig{
@@ -60,24 +125,83 @@ func StartServer() {
 		log.Warn("Failed to configure logger, using defaults", log.Fields{"error": err.Error()})
 	}
 
-	go func() {
-		log.Info("Server running", log.Fields{"uri": uri})
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatal("Server failed:", log.Fields{"error": err.Error()})
+	serve("Server", srv, listen(upgrader, serverConfig.ListenSpec(), serverConfig))
+
+	if serverConfig.AdminEnabled() {
+		adminSrv := &http.Server{
+			Handler: infrastructure.NewAdminServer(),
 		}
-	}()
+		servers = append(servers, adminSrv)
+		serve("Admin server", adminSrv, listen(upgrader, serverConfig.AdminListen, serverConfig))
+	}
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
-	<-quit
+	var grpcSrv *grpcadapter.Server
+	if grpcConfig := config.GetGRPCConfig(); grpcConfig.Enabled() {
+		grpcSrv = grpcadapter.NewServer(grpcConfig, Service.HealthService)
+		ln := listen(upgrader, grpcConfig.Listen, serverConfig)
+		go func() {
+			log.Info("gRPC server running", log.Fields{"listen": ln.Addr().String()})
+			if err := grpcSrv.Serve(ln); err != nil {
+				log.Fatal("gRPC server failed:", log.Fields{"error": err.Error()})
+			}
+		}()
+	}
+
+	// the process replaced by an upgrade drains and exits once this one serves
+	if err := upgrader.Ready(); err != nil {
+		log.Error("Failed to report readiness to the previous process", log.Fields{"error": err.Error()})
+	} else if upgrader.Upgraded() {
+		log.Info("Listeners taken over from the previous process", log.Fields{"pid": os.Getpid()})
+	}
+
+	// SIGHUP reloads the configuration, turning maintenance mode on or off, and
+	// SIGUSR2 replaces the process with a new one of the binary
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	if upgrade.Signal != nil {
+		signal.Notify(signals, upgrade.Signal)
+	}
+wait:
+	for sig := range signals {
+		switch sig {
+		case syscall.SIGHUP:
+			if err := config.ReloadConfiguration(); err != nil {
+				log.Error("Configuration reload failed", log.Fields{"error": err.Error()})
+				continue
+			}
+			log.Info("Configuration reloaded")
+			maintenance.apply(config.GetMaintenanceConfig())
+		case upgrade.Signal:
+			log.Info("Upgrading, starting a new process")
+			if err := upgrader.Upgrade(); err != nil {
+				log.Error("Upgrade failed, still serving", log.Fields{"error": err.Error()})
+				continue
+			}
+			log.Info("New process ready, draining")
+			break wait
+		default:
+			break wait
+		}
+	}
 
 	log.Info("Shutting down server...")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal("Server forced to shutdown: ", log.Fields{"error": err.Error()})
+	for _, s := range servers {
+		if err := s.Shutdown(ctx); err != nil {
+			log.Fatal("Server forced to shutdown: ", log.Fields{"error": err.Error()})
+		}
+	}
+	if grpcSrv != nil {
+		if err := grpcSrv.Shutdown(ctx); err != nil {
+			log.Warn("gRPC calls forced to close", log.Fields{"error": err.Error()})
+		}
+	}
+	// WebSocket connections are hijacked, Shutdown does not wait for them
+	if err := websocket.SharedHub().Shutdown(ctx); err != nil {
+		log.Warn("WebSocket connections forced to close", log.Fields{"error": err.Error()})
 	}
 
 	log.Info("Server gracefully stopped.")
@@ -2,8 +2,11 @@ package config
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/oswaldom-code/api-template-gin/pkg/log"
@@ -33,9 +36,23 @@ type ServerConfig struct {
 	Port              string
 	Scheme            string
 	Mode              string
+	Listen            string
+	SocketMode        string
+	AdminListen       string
+	AdminSecret       string
+	DebugEndpoints    bool
 	PathToSSLKeyFile  string
 	PathToSSLCertFile string
 	Static            string
+	StaticMountPath   string
+	StaticMaxAge      int
+	StaticSPA         bool
+	StaticAPIPrefixes []string
+	// TrustedProxies are the proxy IPs/CIDRs whose ClientIPHeaders are honored.
+	TrustedProxies  []string
+	ClientIPHeaders []string
+	// UpgradeTimeout bounds how long a SIGUSR2 upgrade waits for the new process to be ready.
+	UpgradeTimeout time.Duration
 }
 
 func SetEnvironment(env string) {
@@ -59,6 +76,19 @@ func (s ServerConfig) AsUri() string {
 	return s.Host + ":" + s.Port
 }
 
+// ListenSpec returns the listener spec (tcp://, unix://, systemd:), falling back to Host:Port over tcp.
+func (s ServerConfig) ListenSpec() string {
+	if s.Listen != "" {
+		return s.Listen
+	}
+	return "tcp://" + s.AsUri()
+}
+
+// AdminEnabled reports whether operational endpoints run on their own listener.
+func (s ServerConfig) AdminEnabled() bool {
+	return s.AdminListen != ""
+}
+
 type LoggingConfig struct {
 	Level        string
 	ErrorLogFile string
@@ -68,6 +98,233 @@ type AuthenticateKeyConfig struct {
 	Secret string
 }
 
+// IPFilterConfig restricts a route group by client IP. Entries are IPs or CIDRs;
+// Deny wins over Allow and a non-empty Allow rejects every other address.
+type IPFilterConfig struct {
+	Allow []string
+	Deny  []string
+}
+
+// Enabled reports whether the filter restricts any address.
+func (c IPFilterConfig) Enabled() bool {
+	return len(c.Allow) > 0 || len(c.Deny) > 0
+}
+
+// IdempotencyConfig holds the Idempotency-Key settings for POST and PATCH requests.
+type IdempotencyConfig struct {
+	Enabled bool
+	// Store is memory (per instance) or postgres (shared).
+	Store       string
+	TTL         time.Duration
+	MaxBodySize int64
+}
+
+// CacheRoute makes a GET route cacheable. TTL applies when the response sets no
+// max-age; with 0 only responses that set one are cached.
+type CacheRoute struct {
+	Path string
+	TTL  time.Duration
+}
+
+// ResponseCacheConfig holds the in-process HTTP response cache settings. The key is
+// always the method and path, plus the query, KeyHeaders and the principal when set.
+type ResponseCacheConfig struct {
+	Enabled      bool
+	MaxEntries   int
+	MaxBodySize  int64
+	Routes       []CacheRoute
+	KeyQuery     bool
+	KeyHeaders   []string
+	KeyPrincipal bool
+}
+
+// CORSConfig is the cross-origin policy of a route group. Origins may be exact
+// (https://app.example.com), a wildcard subdomain (https://*.example.com),
+// a regular expression prefixed with "regex:" or "*" for any origin.
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           int
+}
+
+// RateLimitRoute overrides the group limit for one route, e.g. "GET /v1/ping".
+type RateLimitRoute struct {
+	Method string
+	Path   string
+	Limit  int
+	Window time.Duration
+}
+
+// RateLimitConfig is the rate limit policy of a route group.
+type RateLimitConfig struct {
+	Enabled bool
+	// Store is "memory" (per instance) or "postgres" (shared through the repository).
+	Store string
+	// Algorithm is "token_bucket" or "sliding_window".
+	Algorithm string
+	Limit     int
+	Window    time.Duration
+	// KeyBy selects the client key: "ip", "api_key" or "principal".
+	KeyBy        string
+	APIKeyHeader string
+	Routes       []RateLimitRoute
+}
+
+// Validate rejects limits and windows that allow nothing or divide by zero.
+func (c RateLimitConfig) Validate() error {
+	if c.Limit <= 0 || c.Window <= 0 {
+		return fmt.Errorf("limit and window must be positive, got %d per %s", c.Limit, c.Window)
+	}
+	return nil
+}
+
+// SecurityHeadersConfig holds the security response headers. An empty value omits the header.
+type SecurityHeadersConfig struct {
+	Enabled               bool
+	HSTSMaxAge            int
+	HSTSIncludeSubdomains bool
+	HSTSPreload           bool
+	ContentSecurityPolicy string
+	// CSPOverrides replaces the Content-Security-Policy for paths under a prefix.
+	CSPOverrides      map[string]string
+	FrameOptions      string
+	ReferrerPolicy    string
+	PermissionsPolicy string
+}
+
+// CompressionConfig holds response compression and request decompression settings.
+type CompressionConfig struct {
+	Enabled bool
+	// Encodings are the supported response encodings in server preference order.
+	Encodings    []string
+	MinSize      int
+	ContentTypes []string
+	// MaxDecompressedSize limits gzip request bodies after decompression (0 leaves them untouched).
+	MaxDecompressedSize int64
+}
+
+// TimeoutRoute overrides the request deadline of one route (0 disables it).
+type TimeoutRoute struct {
+	Method  string
+	Path    string
+	Timeout time.Duration
+}
+
+// TimeoutConfig holds the request deadlines. StatusCode (504 or 503) is sent on expiry.
+type TimeoutConfig struct {
+	Default    time.Duration
+	Routes     []TimeoutRoute
+	StatusCode int
+}
+
+// APIDeprecation marks an API version ("v1") or a versioned route ("GET /v1/ping") as
+// deprecated since Since. A zero Sunset means no removal date is announced.
+type APIDeprecation struct {
+	Target string
+	Since  time.Time
+	Sunset time.Time
+}
+
+// VersioningConfig holds the API versioning settings. Unversioned paths are served by
+// DefaultVersion unless the Accept header selects another one.
+type VersioningConfig struct {
+	DefaultVersion string
+	Deprecations   []APIDeprecation
+}
+
+// ConcurrencyConfig holds the in-flight request caps used for load shedding.
+// In adaptive modes (aimd, gradient) the caps are upper bounds the limits move under.
+type ConcurrencyConfig struct {
+	Enabled bool
+	// Limit caps in-flight requests across the server, GroupLimits per route group (0 = no cap).
+	Limit         int
+	GroupLimits   map[string]int
+	Mode          string
+	MinLimit      int
+	LatencyTarget time.Duration
+	RetryAfter    time.Duration
+	// Exempt path prefixes are never shed (health and metrics).
+	Exempt []string
+}
+
+// SSEConfig holds the Server-Sent Events broker settings.
+type SSEConfig struct {
+	// Heartbeat is the interval of the comments keeping idle streams open through proxies.
+	Heartbeat time.Duration
+	// Retry is the reconnection delay sent to clients.
+	Retry time.Duration
+	// ReplaySize is how many events per topic are kept for Last-Event-ID resumes.
+	ReplaySize int
+	// ReplayTTL is how long events stay in the replay buffer; topics without subscribers
+	// are dropped once their events expire. With 0 they are dropped with their last subscriber.
+	ReplayTTL time.Duration
+	// BufferSize is how many events a client may fall behind before it is disconnected.
+	BufferSize int
+}
+
+// GRPCConfig holds the settings of the gRPC listener, which runs next to the HTTP server.
+type GRPCConfig struct {
+	// Listen is the listener spec of the gRPC server; it is disabled when empty.
+	Listen string
+	// Reflection exposes the server reflection service, to authenticated clients.
+	Reflection bool
+	// HealthInterval is how often health Watch streams check the dependencies.
+	HealthInterval time.Duration
+}
+
+// Enabled reports whether the gRPC server runs.
+func (c GRPCConfig) Enabled() bool {
+	return c.Listen != ""
+}
+
+// GraphQLConfig holds the settings of the GraphQL endpoint, served on the protected group.
+type GraphQLConfig struct {
+	Enabled bool
+	Path    string
+	// MaxDepth and MaxComplexity (fields selected, fragments expanded) reject larger
+	// queries before they run; 0 disables the limit.
+	MaxDepth      int
+	MaxComplexity int
+	// Playground serves GraphiQL on Path/playground, on the public group.
+	Playground bool
+}
+
+// WebSocketConfig holds the WebSocket connection settings.
+type WebSocketConfig struct {
+	// PingInterval is how often pings are sent; connections without a pong (or any
+	// message) for PongTimeout are closed.
+	PingInterval time.Duration
+	PongTimeout  time.Duration
+	WriteTimeout time.Duration
+	// SendBuffer is how many messages may wait for a client before it is disconnected.
+	SendBuffer     int
+	MaxMessageSize int64
+	// AllowedOrigins may open connections from browsers; same-origin only when empty.
+	AllowedOrigins []string
+}
+
+// MaintenanceConfig holds the maintenance mode settings. Enabled, Message and RetryAfter
+// are applied again on configuration reloads; the allow lists and Exempt at startup.
+type MaintenanceConfig struct {
+	Enabled    bool
+	Message    string
+	RetryAfter time.Duration
+	// AllowIPs (IPs or CIDRs) and AllowAPIKeys, sent in APIKeyHeader, still reach the API.
+	AllowIPs     []string
+	AllowAPIKeys []string
+	APIKeyHeader string
+	// Exempt path prefixes are always served (ping, health and metrics).
+	Exempt []string
+}
+
+// Enabled reports whether any origin is allowed.
+func (c CORSConfig) Enabled() bool {
+	return len(c.AllowedOrigins) > 0
+}
+
 func LoadConfiguration() {
 	if err := godotenv.Load(); err != nil {
 		log.Info("No .env file found, proceeding with default values")
@@ -82,6 +339,19 @@ func LoadConfiguration() {
 	pflag.String("server.port", "9000", "Server port")
 	pflag.String("server.scheme", "http", "Server scheme")
 	pflag.String("server.mode", "debug", "Server mode")
+	pflag.String("server.listen", "", "Listener spec: tcp://host:port, unix:///path.sock or systemd:[name]")
+	pflag.String("server.socket_mode", "0660", "File mode of the unix socket")
+	pflag.String("server.admin.listen", "", "Admin listener spec for metrics, health and debug endpoints (disabled when empty)")
+	pflag.String("server.admin.secret", "", "Basic auth secret for the admin listener (required unless it is on loopback or a unix socket)")
+	pflag.Bool("server.debug_endpoints", false, "Expose pprof and runtime stats (enabled by default outside production)")
+	pflag.Duration("server.upgrade_timeout", 30*time.Second, "Time the new process of a SIGUSR2 upgrade has to become ready")
+	pflag.String("server.trusted_proxies", "", "Comma-separated proxy IPs/CIDRs allowed to set the client IP headers (none when empty)")
+	pflag.String("server.client_ip_headers", "X-Forwarded-For,X-Real-IP", "Comma-separated headers carrying the client IP, e.g. CF-Connecting-IP")
+	pflag.String("server.static", "", "Directory with static files to serve")
+	pflag.String("server.static.mount", "/", "Mount path for static files")
+	pflag.Int("server.static.max_age", 3600, "Cache max-age in seconds for static files")
+	pflag.Bool("server.static.spa", false, "Fall back to index.html for unknown non-API paths")
+	pflag.String("server.static.api_prefixes", "/api,/metrics", "Comma-separated path prefixes excluded from the SPA fallback")
 	pflag.String("auth.secret", "", "Authentication secret")
 	pflag.String("db.database", "default_db", "Database name")
 	pflag.Int("db.max_connections", 10, "Database max open connections")
@@ -91,6 +361,88 @@ func LoadConfiguration() {
 	pflag.String("log.level", "info", "Log level")
 	pflag.String("log.errorLogFile", "", "Error log file path")
 	pflag.String("environment", "development", "Environment name")
+	pflag.String("cors.allowed_origins", "", "Comma-separated allowed origins (exact, https://*.domain, regex:<expr> or *)")
+	pflag.String("cors.allowed_methods", "GET,POST,PUT,PATCH,DELETE,HEAD,OPTIONS", "Comma-separated allowed methods")
+	pflag.String("cors.allowed_headers", "Authorization,Content-Type,X-Request-ID", "Comma-separated allowed request headers")
+	pflag.String("cors.exposed_headers", "X-Request-ID", "Comma-separated headers exposed to the browser")
+	pflag.Bool("cors.allow_credentials", false, "Allow credentials on cross-origin requests")
+	pflag.Int("cors.max_age", 600, "Preflight cache duration in seconds")
+
+	pflag.Bool("ratelimit.enabled", false, "Enable rate limiting")
+	pflag.String("ratelimit.store", "memory", "Rate limit store: memory or postgres")
+	pflag.String("ratelimit.algorithm", "token_bucket", "Rate limit algorithm: token_bucket or sliding_window")
+	pflag.Int("ratelimit.limit", 100, "Requests allowed per window")
+	pflag.String("ratelimit.window", "1m", "Rate limit window")
+	pflag.String("ratelimit.key", "ip", "Rate limit key: ip, api_key or principal")
+	pflag.String("ratelimit.api_key_header", "X-API-Key", "Header holding the API key")
+	pflag.String("ratelimit.routes", "", "Per route limits, e.g. GET /v1/ping=10/1s,POST /v1/orders=5/1m")
+	pflag.Bool("security.enabled", true, "Set security response headers")
+	pflag.Int("security.hsts_max_age", 0, "Strict-Transport-Security max-age in seconds (HTTPS only)")
+	pflag.Bool("security.hsts_include_subdomains", false, "Add includeSubDomains to HSTS")
+	pflag.Bool("security.hsts_preload", false, "Add preload to HSTS")
+	pflag.String("security.csp", "", "Content-Security-Policy")
+	pflag.String("security.csp_overrides", "", "Per path prefix CSP, e.g. /metrics=default-src 'none'|/app=default-src 'self'")
+	pflag.String("security.frame_options", "", "X-Frame-Options")
+	pflag.String("security.referrer_policy", "", "Referrer-Policy")
+	pflag.String("security.permissions_policy", "", "Permissions-Policy")
+	pflag.Bool("compression.enabled", true, "Compress responses negotiated via Accept-Encoding")
+	pflag.String("compression.encodings", "br,zstd,gzip", "Comma-separated response encodings in preference order")
+	pflag.Int("compression.min_size", 1024, "Minimum response size in bytes to compress")
+	pflag.String("compression.content_types", "", "Comma-separated compressible content types (type/* allowed)")
+	pflag.Int("compression.max_decompressed_size", 10<<20, "Maximum decompressed size of gzip request bodies in bytes (0 disables)")
+	pflag.String("timeout.default", "30s", "Request deadline of routes without an override (0 disables)")
+	pflag.String("timeout.routes", "", "Per route deadlines, e.g. GET /v1/ping=1s,POST /v1/reports=2m")
+	pflag.Int("timeout.status", 504, "Status sent when the deadline expires: 504 or 503")
+	pflag.Bool("concurrency.enabled", false, "Shed requests above the in-flight limits")
+	pflag.Int("concurrency.limit", 1000, "Maximum in-flight requests across the server (0 = no cap)")
+	pflag.Int("concurrency.public.limit", 0, "Maximum in-flight requests on the public group (0 = no cap)")
+	pflag.Int("concurrency.protected.limit", 0, "Maximum in-flight requests on the protected group (0 = no cap)")
+	pflag.String("concurrency.mode", "static", "Limit mode: static, aimd or gradient")
+	pflag.Int("concurrency.min_limit", 10, "Lowest limit adaptive modes may set")
+	pflag.String("concurrency.latency_target", "500ms", "Mean latency above which aimd decreases the limit")
+	pflag.String("concurrency.retry_after", "1s", "Retry-After sent with shed requests")
+	pflag.String("concurrency.exempt", "/ping,/metrics,/health", "Comma-separated path prefixes never shed")
+	pflag.String("ipfilter.allow", "", "Comma-separated IPs/CIDRs allowed (all when empty)")
+	pflag.String("ipfilter.deny", "", "Comma-separated IPs/CIDRs denied")
+	pflag.String("api.default_version", "v1", "API version serving unversioned paths")
+	pflag.String("api.deprecations", "", "Deprecated versions and routes, e.g. v1=2026-01-01/2026-12-31,GET /v2/ping=2026-06-01")
+	pflag.Bool("cache.enabled", false, "Serve the configured GET routes from an in-process LRU cache")
+	pflag.Int("cache.max_entries", 10000, "Maximum cached responses")
+	pflag.Int("cache.max_body_size", 1<<20, "Largest response body in bytes to cache")
+	pflag.String("cache.routes", "", "Cacheable GET routes with their default TTL, e.g. GET /v1/ping=30s")
+	pflag.Bool("cache.key_query", true, "Include the query string in cache keys")
+	pflag.String("cache.key_headers", "Accept", "Request headers included in cache keys")
+	pflag.Bool("cache.key_principal", true, "Include the authenticated principal in cache keys")
+	pflag.Bool("idempotency.enabled", true, "Honor the Idempotency-Key header on POST and PATCH")
+	pflag.String("idempotency.store", "memory", "Idempotency store: memory or postgres")
+	pflag.String("idempotency.ttl", "24h", "How long responses are kept for replay")
+	pflag.Int("idempotency.max_body_size", 1<<20, "Maximum request body size in bytes for idempotent requests")
+	pflag.String("sse.heartbeat", "15s", "Interval of the heartbeat comments on event streams")
+	pflag.String("sse.retry", "3s", "Reconnection delay sent to event stream clients")
+	pflag.Int("sse.replay_size", 100, "Events kept per topic for Last-Event-ID resumes")
+	pflag.String("sse.replay_ttl", "5m", "How long events are kept for Last-Event-ID resumes")
+	pflag.Int("sse.buffer_size", 64, "Events a client may fall behind before it is disconnected")
+	pflag.String("grpc.listen", "", "gRPC listener spec: tcp://host:port, unix:///path.sock or systemd:[name] (disabled when empty)")
+	pflag.Bool("grpc.reflection", false, "Expose gRPC server reflection (enabled by default outside production)")
+	pflag.String("grpc.health_interval", "5s", "Interval of the dependency checks of gRPC health Watch streams")
+	pflag.Bool("graphql.enabled", true, "Serve the GraphQL endpoint on the protected group")
+	pflag.String("graphql.path", "/graphql", "Path of the GraphQL endpoint")
+	pflag.Int("graphql.max_depth", 10, "Deepest GraphQL query accepted (0 disables the limit)")
+	pflag.Int("graphql.max_complexity", 200, "Most fields a GraphQL query may select, fragments expanded (0 disables the limit)")
+	pflag.Bool("graphql.playground", false, "Serve GraphiQL on the GraphQL path + /playground (enabled by default in debug mode)")
+	pflag.String("websocket.ping_interval", "30s", "Interval of the WebSocket pings")
+	pflag.String("websocket.pong_timeout", "60s", "Time without a pong after which a WebSocket connection is closed")
+	pflag.String("websocket.write_timeout", "10s", "Deadline of each WebSocket write")
+	pflag.Int("websocket.send_buffer", 32, "Messages queued per WebSocket connection before a slow client is disconnected")
+	pflag.Int("websocket.max_message_size", 64<<10, "Largest WebSocket message in bytes accepted from clients")
+	pflag.String("websocket.allowed_origins", "", "Comma-separated origins allowed to connect (same origin when empty, * for any)")
+	pflag.Bool("maintenance.enabled", false, "Answer 503 on every route but ping, health and metrics")
+	pflag.String("maintenance.message", "The API is under maintenance, please retry later", "Message of the maintenance responses")
+	pflag.String("maintenance.retry_after", "5m", "Retry-After sent with maintenance responses (0 omits it)")
+	pflag.String("maintenance.allow_ips", "", "Comma-separated IPs/CIDRs served during maintenance")
+	pflag.String("maintenance.allow_api_keys", "", "Comma-separated API keys served during maintenance")
+	pflag.String("maintenance.api_key_header", "X-API-Key", "Header holding the maintenance API key")
+	pflag.String("maintenance.exempt", "/ping,/health,/metrics", "Comma-separated path prefixes served during maintenance")
 
 	pflag.Parse()
 
@@ -99,11 +451,26 @@ func LoadConfiguration() {
 	log.SetLogLevel(GetLogConfig().Level)
 }
 
+// ReloadConfiguration reads the .env file again, overriding the environment, so the
+// settings read per use (maintenance mode, log level) change without a restart.
+// Variables removed from the file keep their previous value.
+func ReloadConfiguration() error {
+	if err := godotenv.Overload(); err != nil {
+		return fmt.Errorf("failed to reload .env file: %w", err)
+	}
+	loadEnvVariables()
+	log.SetLogLevel(GetLogConfig().Level)
+	return nil
+}
+
+// envMapping maps an environment variable to its configuration key.
+type envMapping struct {
+	key   string
+	value string
+}
+
 func loadEnvVariables() {
-	envVars := []struct {
-		key   string
-		value string
-	}{
+	envVars := []envMapping{
 		{"DB_USER", "db.user"},
 		{"DB_PASSWORD", "db.password"},
 		{"DB_HOST", "db.host"},
@@ -112,6 +479,19 @@ func loadEnvVariables() {
 		{"SERVER_PORT", "server.port"},
 		{"SERVER_SCHEME", "server.scheme"},
 		{"SERVER_MODE", "server.mode"},
+		{"SERVER_LISTEN", "server.listen"},
+		{"SERVER_SOCKET_MODE", "server.socket_mode"},
+		{"SERVER_ADMIN_LISTEN", "server.admin.listen"},
+		{"SERVER_ADMIN_SECRET", "server.admin.secret"},
+		{"SERVER_DEBUG_ENDPOINTS", "server.debug_endpoints"},
+		{"SERVER_UPGRADE_TIMEOUT", "server.upgrade_timeout"},
+		{"SERVER_STATIC", "server.static"},
+		{"SERVER_STATIC_MOUNT", "server.static.mount"},
+		{"SERVER_STATIC_MAX_AGE", "server.static.max_age"},
+		{"SERVER_STATIC_SPA", "server.static.spa"},
+		{"SERVER_STATIC_API_PREFIXES", "server.static.api_prefixes"},
+		{"SERVER_TRUSTED_PROXIES", "server.trusted_proxies"},
+		{"SERVER_CLIENT_IP_HEADERS", "server.client_ip_headers"},
 		{"AUTH_SECRET", "auth.secret"},
 		{"DB_DATABASE", "db.database"},
 		{"DB_MAX_CONNECTIONS", "db.max_connections"},
@@ -121,6 +501,103 @@ func loadEnvVariables() {
 		{"LOG_LEVEL", "log.level"},
 		{"LOG_ERROR_LOG_FILE", "log.errorLogFile"},
 		{"ENVIRONMENT", "environment"},
+		{"CORS_ALLOWED_ORIGINS", "cors.allowed_origins"},
+		{"CORS_ALLOWED_METHODS", "cors.allowed_methods"},
+		{"CORS_ALLOWED_HEADERS", "cors.allowed_headers"},
+		{"CORS_EXPOSED_HEADERS", "cors.exposed_headers"},
+		{"CORS_ALLOW_CREDENTIALS", "cors.allow_credentials"},
+		{"CORS_MAX_AGE", "cors.max_age"},
+		{"RATELIMIT_ENABLED", "ratelimit.enabled"},
+		{"RATELIMIT_STORE", "ratelimit.store"},
+		{"RATELIMIT_ALGORITHM", "ratelimit.algorithm"},
+		{"RATELIMIT_LIMIT", "ratelimit.limit"},
+		{"RATELIMIT_WINDOW", "ratelimit.window"},
+		{"RATELIMIT_KEY", "ratelimit.key"},
+		{"RATELIMIT_API_KEY_HEADER", "ratelimit.api_key_header"},
+		{"RATELIMIT_ROUTES", "ratelimit.routes"},
+		{"SECURITY_ENABLED", "security.enabled"},
+		{"SECURITY_HSTS_MAX_AGE", "security.hsts_max_age"},
+		{"SECURITY_HSTS_INCLUDE_SUBDOMAINS", "security.hsts_include_subdomains"},
+		{"SECURITY_HSTS_PRELOAD", "security.hsts_preload"},
+		{"SECURITY_CSP", "security.csp"},
+		{"SECURITY_CSP_OVERRIDES", "security.csp_overrides"},
+		{"SECURITY_FRAME_OPTIONS", "security.frame_options"},
+		{"SECURITY_REFERRER_POLICY", "security.referrer_policy"},
+		{"SECURITY_PERMISSIONS_POLICY", "security.permissions_policy"},
+		{"COMPRESSION_ENABLED", "compression.enabled"},
+		{"COMPRESSION_ENCODINGS", "compression.encodings"},
+		{"COMPRESSION_MIN_SIZE", "compression.min_size"},
+		{"COMPRESSION_CONTENT_TYPES", "compression.content_types"},
+		{"COMPRESSION_MAX_DECOMPRESSED_SIZE", "compression.max_decompressed_size"},
+		{"TIMEOUT_DEFAULT", "timeout.default"},
+		{"TIMEOUT_ROUTES", "timeout.routes"},
+		{"TIMEOUT_STATUS", "timeout.status"},
+		{"IPFILTER_ALLOW", "ipfilter.allow"},
+		{"IPFILTER_DENY", "ipfilter.deny"},
+		{"API_DEFAULT_VERSION", "api.default_version"},
+		{"API_DEPRECATIONS", "api.deprecations"},
+		{"CACHE_ENABLED", "cache.enabled"},
+		{"CACHE_MAX_ENTRIES", "cache.max_entries"},
+		{"CACHE_MAX_BODY_SIZE", "cache.max_body_size"},
+		{"CACHE_ROUTES", "cache.routes"},
+		{"CACHE_KEY_QUERY", "cache.key_query"},
+		{"CACHE_KEY_HEADERS", "cache.key_headers"},
+		{"CACHE_KEY_PRINCIPAL", "cache.key_principal"},
+		{"IDEMPOTENCY_ENABLED", "idempotency.enabled"},
+		{"IDEMPOTENCY_STORE", "idempotency.store"},
+		{"IDEMPOTENCY_TTL", "idempotency.ttl"},
+		{"IDEMPOTENCY_MAX_BODY_SIZE", "idempotency.max_body_size"},
+		{"SSE_HEARTBEAT", "sse.heartbeat"},
+		{"SSE_RETRY", "sse.retry"},
+		{"SSE_REPLAY_SIZE", "sse.replay_size"},
+		{"SSE_REPLAY_TTL", "sse.replay_ttl"},
+		{"SSE_BUFFER_SIZE", "sse.buffer_size"},
+		{"GRPC_LISTEN", "grpc.listen"},
+		{"GRPC_REFLECTION", "grpc.reflection"},
+		{"GRPC_HEALTH_INTERVAL", "grpc.health_interval"},
+		{"GRAPHQL_ENABLED", "graphql.enabled"},
+		{"GRAPHQL_PATH", "graphql.path"},
+		{"GRAPHQL_MAX_DEPTH", "graphql.max_depth"},
+		{"GRAPHQL_MAX_COMPLEXITY", "graphql.max_complexity"},
+		{"GRAPHQL_PLAYGROUND", "graphql.playground"},
+		{"WEBSOCKET_PING_INTERVAL", "websocket.ping_interval"},
+		{"WEBSOCKET_PONG_TIMEOUT", "websocket.pong_timeout"},
+		{"WEBSOCKET_WRITE_TIMEOUT", "websocket.write_timeout"},
+		{"WEBSOCKET_SEND_BUFFER", "websocket.send_buffer"},
+		{"WEBSOCKET_MAX_MESSAGE_SIZE", "websocket.max_message_size"},
+		{"WEBSOCKET_ALLOWED_ORIGINS", "websocket.allowed_origins"},
+		{"MAINTENANCE_ENABLED", "maintenance.enabled"},
+		{"MAINTENANCE_MESSAGE", "maintenance.message"},
+		{"MAINTENANCE_RETRY_AFTER", "maintenance.retry_after"},
+		{"MAINTENANCE_ALLOW_IPS", "maintenance.allow_ips"},
+		{"MAINTENANCE_ALLOW_API_KEYS", "maintenance.allow_api_keys"},
+		{"MAINTENANCE_API_KEY_HEADER", "maintenance.api_key_header"},
+		{"MAINTENANCE_EXEMPT", "maintenance.exempt"},
+		{"CONCURRENCY_ENABLED", "concurrency.enabled"},
+		{"CONCURRENCY_LIMIT", "concurrency.limit"},
+		{"CONCURRENCY_MODE", "concurrency.mode"},
+		{"CONCURRENCY_MIN_LIMIT", "concurrency.min_limit"},
+		{"CONCURRENCY_LATENCY_TARGET", "concurrency.latency_target"},
+		{"CONCURRENCY_RETRY_AFTER", "concurrency.retry_after"},
+		{"CONCURRENCY_EXEMPT", "concurrency.exempt"},
+	}
+	// route group overrides, e.g. CORS_PROTECTED_ALLOWED_ORIGINS -> cors.protected.allowed_origins
+	groupSettings := map[string][]string{
+		"cors": {"allowed_origins", "allowed_methods", "allowed_headers",
+			"exposed_headers", "allow_credentials", "max_age"},
+		"ratelimit":   {"enabled", "algorithm", "limit", "window", "key", "routes"},
+		"concurrency": {"limit"},
+		"ipfilter":    {"allow", "deny"},
+	}
+	for prefix, keys := range groupSettings {
+		for _, group := range []string{"public", "protected"} {
+			for _, key := range keys {
+				envVars = append(envVars, envMapping{
+					strings.ToUpper(prefix + "_" + group + "_" + key),
+					prefix + "." + group + "." + key,
+				})
+			}
+		}
 	}
 
 	for _, envVar := range envVars {
@@ -158,6 +635,18 @@ func GetServerConfig() ServerConfig {
 		PathToSSLKeyFile:  os.Getenv("server.ssl.key"),        // Podría ser opcional
 		PathToSSLCertFile: os.Getenv("server.ssl.cert"),       // Podría ser opcional
 		Static:            os.Getenv("server.static"),         // Podría ser opcional
+		StaticMountPath:   getEnv("server.static.mount", "/"),
+		StaticMaxAge:      getIntEnv("server.static.max_age", 3600),
+		StaticSPA:         getBoolEnv("server.static.spa", false),
+		StaticAPIPrefixes: getListEnv("server.static.api_prefixes", []string{"/api", "/metrics"}),
+		Listen:            os.Getenv("server.listen"),
+		SocketMode:        getEnv("server.socket_mode", "0660"),
+		AdminListen:       os.Getenv("server.admin.listen"),
+		AdminSecret:       os.Getenv("server.admin.secret"),
+		DebugEndpoints:    getBoolEnv("server.debug_endpoints", !IsProduction()),
+		TrustedProxies:    getListEnv("server.trusted_proxies", []string{}),
+		ClientIPHeaders:   getListEnv("server.client_ip_headers", []string{"X-Forwarded-For", "X-Real-IP"}),
+		UpgradeTimeout:    getDurationEnv("server.upgrade_timeout", 30*time.Second),
 	}
 	log.Debug("ServerConfig", log.Fields{"config": config})
 	return config
@@ -176,12 +665,364 @@ func GetEnvironmentConfig() EnvironmentConfig {
 	}
 }
 
+// IsProduction reports whether the configured environment is production.
+func IsProduction() bool {
+	return GetEnvironmentConfig().Environment == "production"
+}
+
 func GetAuthenticationKey() AuthenticateKeyConfig {
 	return AuthenticateKeyConfig{
 		Secret: getEnv("auth.secret", "default_secret"),
 	}
 }
 
+// groupKey returns prefix.group.setting when that override is set, prefix.setting otherwise.
+func groupKey(prefix, group, setting string) string {
+	if _, exists := os.LookupEnv(prefix + "." + group + "." + setting); exists {
+		return prefix + "." + group + "." + setting
+	}
+	return prefix + "." + setting
+}
+
+// GetIPFilterConfig returns the IP filter of a route group ("public" or "protected").
+// Both lists can be overridden per group with ipfilter.<group>.allow and ipfilter.<group>.deny.
+func GetIPFilterConfig(group string) IPFilterConfig {
+	return IPFilterConfig{
+		Allow: getListEnv(groupKey("ipfilter", group, "allow"), []string{}),
+		Deny:  getListEnv(groupKey("ipfilter", group, "deny"), []string{}),
+	}
+}
+
+// GetIdempotencyConfig returns the Idempotency-Key settings.
+func GetIdempotencyConfig() IdempotencyConfig {
+	return IdempotencyConfig{
+		Enabled:     getBoolEnv("idempotency.enabled", true),
+		Store:       getEnv("idempotency.store", "memory"),
+		TTL:         getDurationEnv("idempotency.ttl", 24*time.Hour),
+		MaxBodySize: int64(getIntEnv("idempotency.max_body_size", 1<<20)),
+	}
+}
+
+// GetSSEConfig returns the Server-Sent Events broker settings.
+func GetSSEConfig() SSEConfig {
+	return SSEConfig{
+		Heartbeat:  getDurationEnv("sse.heartbeat", 15*time.Second),
+		Retry:      getDurationEnv("sse.retry", 3*time.Second),
+		ReplaySize: getIntEnv("sse.replay_size", 100),
+		ReplayTTL:  getDurationEnv("sse.replay_ttl", 5*time.Minute),
+		BufferSize: getIntEnv("sse.buffer_size", 64),
+	}
+}
+
+// GetGRPCConfig returns the gRPC listener settings.
+func GetGRPCConfig() GRPCConfig {
+	return GRPCConfig{
+		Listen:         os.Getenv("grpc.listen"),
+		Reflection:     getBoolEnv("grpc.reflection", !IsProduction()),
+		HealthInterval: getDurationEnv("grpc.health_interval", 5*time.Second),
+	}
+}
+
+// GetGraphQLConfig returns the GraphQL endpoint settings.
+func GetGraphQLConfig() GraphQLConfig {
+	return GraphQLConfig{
+		Enabled:       getBoolEnv("graphql.enabled", true),
+		Path:          getEnv("graphql.path", "/graphql"),
+		MaxDepth:      getIntEnv("graphql.max_depth", 10),
+		MaxComplexity: getIntEnv("graphql.max_complexity", 200),
+		Playground:    getBoolEnv("graphql.playground", GetServerConfig().Mode == "debug"),
+	}
+}
+
+// GetWebSocketConfig returns the WebSocket connection settings.
+func GetWebSocketConfig() WebSocketConfig {
+	return WebSocketConfig{
+		PingInterval:   getDurationEnv("websocket.ping_interval", 30*time.Second),
+		PongTimeout:    getDurationEnv("websocket.pong_timeout", 60*time.Second),
+		WriteTimeout:   getDurationEnv("websocket.write_timeout", 10*time.Second),
+		SendBuffer:     getIntEnv("websocket.send_buffer", 32),
+		MaxMessageSize: int64(getIntEnv("websocket.max_message_size", 64<<10)),
+		AllowedOrigins: getListEnv("websocket.allowed_origins", []string{}),
+	}
+}
+
+// GetMaintenanceConfig returns the maintenance mode settings.
+func GetMaintenanceConfig() MaintenanceConfig {
+	return MaintenanceConfig{
+		Enabled:      getBoolEnv("maintenance.enabled", false),
+		Message:      getEnv("maintenance.message", "The API is under maintenance, please retry later"),
+		RetryAfter:   getDurationEnv("maintenance.retry_after", 5*time.Minute),
+		AllowIPs:     getListEnv("maintenance.allow_ips", []string{}),
+		AllowAPIKeys: getListEnv("maintenance.allow_api_keys", []string{}),
+		APIKeyHeader: getEnv("maintenance.api_key_header", "X-API-Key"),
+		Exempt:       getListEnv("maintenance.exempt", []string{"/ping", "/health", "/metrics"}),
+	}
+}
+
+// GetResponseCacheConfig returns the HTTP response cache settings.
+func GetResponseCacheConfig() ResponseCacheConfig {
+	return ResponseCacheConfig{
+		Enabled:      getBoolEnv("cache.enabled", false),
+		MaxEntries:   getIntEnv("cache.max_entries", 10000),
+		MaxBodySize:  int64(getIntEnv("cache.max_body_size", 1<<20)),
+		Routes:       parseCacheRoutes(getListEnv("cache.routes", []string{})),
+		KeyQuery:     getBoolEnv("cache.key_query", true),
+		KeyHeaders:   getListEnv("cache.key_headers", []string{"Accept"}),
+		KeyPrincipal: getBoolEnv("cache.key_principal", true),
+	}
+}
+
+// GetCORSConfig returns the CORS policy of a route group ("public" or "protected").
+// Every setting can be overridden per group with cors.<group>.<setting>.
+func GetCORSConfig(group string) CORSConfig {
+	key := func(setting string) string {
+		return groupKey("cors", group, setting)
+	}
+	return CORSConfig{
+		AllowedOrigins:   getListEnv(key("allowed_origins"), nil),
+		AllowedMethods:   getListEnv(key("allowed_methods"), []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}),
+		AllowedHeaders:   getListEnv(key("allowed_headers"), []string{"Authorization", "Content-Type", "X-Request-ID"}),
+		ExposedHeaders:   getListEnv(key("exposed_headers"), []string{"X-Request-ID"}),
+		AllowCredentials: getBoolEnv(key("allow_credentials"), false),
+		MaxAge:           getIntEnv(key("max_age"), 600),
+	}
+}
+
+// GetRateLimitConfig returns the rate limit policy of a route group ("public" or "protected").
+// Every setting but the store can be overridden per group with ratelimit.<group>.<setting>.
+func GetRateLimitConfig(group string) RateLimitConfig {
+	key := func(setting string) string {
+		return groupKey("ratelimit", group, setting)
+	}
+	return RateLimitConfig{
+		Enabled:      getBoolEnv(key("enabled"), false),
+		Store:        getEnv("ratelimit.store", "memory"),
+		Algorithm:    getEnv(key("algorithm"), "token_bucket"),
+		Limit:        getIntEnv(key("limit"), 100),
+		Window:       getDurationEnv(key("window"), time.Minute),
+		KeyBy:        getEnv(key("key"), "ip"),
+		APIKeyHeader: getEnv("ratelimit.api_key_header", "X-API-Key"),
+		Routes:       parseRateLimitRoutes(getListEnv(key("routes"), nil)),
+	}
+}
+
+// GetSecurityHeadersConfig returns the security headers policy. Defaults are
+// stricter when the environment is production.
+func GetSecurityHeadersConfig() SecurityHeadersConfig {
+	cfg := SecurityHeadersConfig{
+		Enabled:               getBoolEnv("security.enabled", true),
+		HSTSMaxAge:            getIntEnv("security.hsts_max_age", 0),
+		HSTSIncludeSubdomains: getBoolEnv("security.hsts_include_subdomains", false),
+		HSTSPreload:           getBoolEnv("security.hsts_preload", false),
+		ContentSecurityPolicy: getEnv("security.csp", "default-src 'self'"),
+		FrameOptions:          getEnv("security.frame_options", "SAMEORIGIN"),
+		ReferrerPolicy:        getEnv("security.referrer_policy", "strict-origin-when-cross-origin"),
+		PermissionsPolicy:     getEnv("security.permissions_policy", ""),
+	}
+	if IsProduction() {
+		cfg.HSTSMaxAge = getIntEnv("security.hsts_max_age", 63072000)
+		cfg.HSTSIncludeSubdomains = getBoolEnv("security.hsts_include_subdomains", true)
+		cfg.ContentSecurityPolicy = getEnv("security.csp", "default-src 'none'; frame-ancestors 'none'")
+		cfg.FrameOptions = getEnv("security.frame_options", "DENY")
+		cfg.ReferrerPolicy = getEnv("security.referrer_policy", "no-referrer")
+		cfg.PermissionsPolicy = getEnv("security.permissions_policy", "camera=(), microphone=(), geolocation=()")
+	}
+
+	cfg.CSPOverrides = map[string]string{}
+	if overrides := os.Getenv("security.csp_overrides"); overrides != "" {
+		// policies contain ';' and ',' so entries are separated by '|'
+		for _, item := range strings.Split(overrides, "|") {
+			prefix, policy, found := strings.Cut(item, "=")
+			if !found || strings.TrimSpace(prefix) == "" {
+				log.Warn("Ignoring invalid CSP override", log.Fields{"override": item})
+				continue
+			}
+			cfg.CSPOverrides[strings.TrimSpace(prefix)] = strings.TrimSpace(policy)
+		}
+	}
+	return cfg
+}
+
+// GetCompressionConfig returns the compression settings.
+func GetCompressionConfig() CompressionConfig {
+	return CompressionConfig{
+		Enabled:   getBoolEnv("compression.enabled", true),
+		Encodings: getListEnv("compression.encodings", []string{"br", "zstd", "gzip"}),
+		MinSize:   getIntEnv("compression.min_size", 1024),
+		ContentTypes: getListEnv("compression.content_types", []string{
+			"text/*",
+			"application/json",
+			"application/problem+json",
+			"application/javascript",
+			"application/xml",
+			"application/yaml",
+			"image/svg+xml",
+		}),
+		MaxDecompressedSize: int64(getIntEnv("compression.max_decompressed_size", 10<<20)),
+	}
+}
+
+// GetTimeoutConfig returns the request deadlines. Profiling endpoints run longer
+// than any sensible default so they have no deadline unless configured.
+func GetTimeoutConfig() TimeoutConfig {
+	cfg := TimeoutConfig{
+		Default: getDurationEnv("timeout.default", 30*time.Second),
+		Routes: parseTimeoutRoutes(getListEnv("timeout.routes", []string{
+			"GET /debug/pprof/profile=0",
+			"GET /debug/pprof/trace=0",
+		})),
+		StatusCode: getIntEnv("timeout.status", http.StatusGatewayTimeout),
+	}
+	if cfg.StatusCode != http.StatusGatewayTimeout && cfg.StatusCode != http.StatusServiceUnavailable {
+		log.Warn("Ignoring invalid timeout status", log.Fields{"status": cfg.StatusCode})
+		cfg.StatusCode = http.StatusGatewayTimeout
+	}
+	return cfg
+}
+
+// GetVersioningConfig returns the API versioning settings.
+func GetVersioningConfig() VersioningConfig {
+	return VersioningConfig{
+		DefaultVersion: getEnv("api.default_version", "v1"),
+		Deprecations:   parseAPIDeprecations(getListEnv("api.deprecations", []string{})),
+	}
+}
+
+// GetConcurrencyConfig returns the load shedding settings. Group limits do not fall
+// back to the global limit, which already applies to every request.
+func GetConcurrencyConfig() ConcurrencyConfig {
+	cfg := ConcurrencyConfig{
+		Enabled:       getBoolEnv("concurrency.enabled", false),
+		Limit:         getIntEnv("concurrency.limit", 1000),
+		GroupLimits:   map[string]int{},
+		Mode:          getEnv("concurrency.mode", "static"),
+		MinLimit:      getIntEnv("concurrency.min_limit", 10),
+		LatencyTarget: getDurationEnv("concurrency.latency_target", 500*time.Millisecond),
+		RetryAfter:    getDurationEnv("concurrency.retry_after", time.Second),
+		Exempt:        getListEnv("concurrency.exempt", []string{"/ping", "/metrics", "/health"}),
+	}
+	for _, group := range []string{"public", "protected"} {
+		cfg.GroupLimits[group] = getIntEnv("concurrency."+group+".limit", 0)
+	}
+	switch cfg.Mode {
+	case "static", "aimd", "gradient":
+	default:
+		log.Warn("Ignoring invalid concurrency mode", log.Fields{"mode": cfg.Mode})
+		cfg.Mode = "static"
+	}
+	return cfg
+}
+
+// parseTimeoutRoutes parses "METHOD /path=DURATION" items, skipping invalid ones.
+func parseTimeoutRoutes(items []string) []TimeoutRoute {
+	routes := []TimeoutRoute{}
+	for _, item := range items {
+		route, value, found := strings.Cut(item, "=")
+		method, path, hasPath := strings.Cut(strings.TrimSpace(route), " ")
+		if !found || !hasPath {
+			log.Warn("Ignoring invalid timeout route", log.Fields{"route": item})
+			continue
+		}
+		timeout, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || timeout < 0 {
+			log.Warn("Ignoring invalid timeout route", log.Fields{"route": item})
+			continue
+		}
+		routes = append(routes, TimeoutRoute{
+			Method:  strings.ToUpper(method),
+			Path:    strings.TrimSpace(path),
+			Timeout: timeout,
+		})
+	}
+	return routes
+}
+
+// parseCacheRoutes parses "GET /path=TTL" items, skipping invalid and non-GET ones.
+func parseCacheRoutes(items []string) []CacheRoute {
+	routes := []CacheRoute{}
+	for _, item := range items {
+		route, value, found := strings.Cut(item, "=")
+		method, path, hasPath := strings.Cut(strings.TrimSpace(route), " ")
+		if !found || !hasPath || !strings.EqualFold(method, http.MethodGet) {
+			log.Warn("Ignoring invalid cache route", log.Fields{"route": item})
+			continue
+		}
+		ttl, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || ttl < 0 {
+			log.Warn("Ignoring invalid cache route", log.Fields{"route": item})
+			continue
+		}
+		routes = append(routes, CacheRoute{Path: strings.TrimSpace(path), TTL: ttl})
+	}
+	return routes
+}
+
+// parseAPIDeprecations parses "TARGET=SINCE[/SUNSET]" items, where TARGET is a version or
+// "METHOD /path" and the dates are YYYY-MM-DD or RFC 3339, skipping invalid ones.
+func parseAPIDeprecations(items []string) []APIDeprecation {
+	deprecations := []APIDeprecation{}
+	for _, item := range items {
+		target, dates, found := strings.Cut(item, "=")
+		since, sunset, hasSunset := strings.Cut(dates, "/")
+		deprecation := APIDeprecation{Target: strings.TrimSpace(target)}
+		var err error
+		if deprecation.Since, err = parseDate(since); !found || deprecation.Target == "" || err != nil {
+			log.Warn("Ignoring invalid API deprecation", log.Fields{"deprecation": item})
+			continue
+		}
+		if hasSunset {
+			if deprecation.Sunset, err = parseDate(sunset); err != nil {
+				log.Warn("Ignoring invalid API deprecation", log.Fields{"deprecation": item})
+				continue
+			}
+		}
+		if method, path, isRoute := strings.Cut(deprecation.Target, " "); isRoute {
+			deprecation.Target = strings.ToUpper(method) + " " + strings.TrimSpace(path)
+		}
+		deprecations = append(deprecations, deprecation)
+	}
+	return deprecations
+}
+
+func parseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if date, err := time.Parse(time.DateOnly, value); err == nil {
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// parseRateLimitRoutes parses "METHOD /path=LIMIT/WINDOW" items, skipping invalid ones.
+func parseRateLimitRoutes(items []string) []RateLimitRoute {
+	routes := []RateLimitRoute{}
+	for _, item := range items {
+		route, limit, found := strings.Cut(item, "=")
+		method, path, hasPath := strings.Cut(strings.TrimSpace(route), " ")
+		count, window, hasWindow := strings.Cut(limit, "/")
+		if !found || !hasPath || !hasWindow {
+			log.Warn("Ignoring invalid rate limit route", log.Fields{"route": item})
+			continue
+		}
+		countValue, err := strconv.Atoi(strings.TrimSpace(count))
+		if err != nil || countValue < 1 {
+			log.Warn("Ignoring invalid rate limit route", log.Fields{"route": item})
+			continue
+		}
+		windowValue, err := time.ParseDuration(strings.TrimSpace(window))
+		if err != nil || windowValue <= 0 {
+			log.Warn("Ignoring invalid rate limit route", log.Fields{"route": item})
+			continue
+		}
+		routes = append(routes, RateLimitRoute{
+			Method: strings.ToUpper(method),
+			Path:   strings.TrimSpace(path),
+			Limit:  countValue,
+			Window: windowValue,
+		})
+	}
+	return routes
+}
+
 func getEnv(key, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
@@ -189,6 +1030,41 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getBoolEnv(key string, defaultValue bool) bool {
+	value, exists := os.LookupEnv(key)
+	if exists {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getListEnv reads a comma-separated list, dropping empty items.
+func getListEnv(key string, defaultValue []string) []string {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if exists {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
 func getIntEnv(key string, defaultValue int) int {
 	value, exists := os.LookupEnv(key)
 	if exists {
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -67,6 +68,15 @@ func TestGetServerConfig_Defaults(t *testing.T) {
 	assert.Equal(t, "debug", cfg.Mode)
 }
 
+func TestGetServerConfig_UpgradeTimeout(t *testing.T) {
+	os.Unsetenv("server.upgrade_timeout")
+	assert.Equal(t, 30*time.Second, GetServerConfig().UpgradeTimeout)
+
+	os.Setenv("server.upgrade_timeout", "5s")
+	defer os.Unsetenv("server.upgrade_timeout")
+	assert.Equal(t, 5*time.Second, GetServerConfig().UpgradeTimeout)
+}
+
 func TestServerConfig_Validate_Valid(t *testing.T) {
 	cfg := ServerConfig{
 		Host:   "localhost",
@@ -140,3 +150,322 @@ func TestGetAuthenticationKey_Default(t *testing.T) {
 	cfg := GetAuthenticationKey()
 	assert.Equal(t, "default_secret", cfg.Secret)
 }
+
+func TestGetServerConfig_StaticDefaults(t *testing.T) {
+	envKeys := []string{"server.static", "server.static.mount", "server.static.max_age",
+		"server.static.spa", "server.static.api_prefixes"}
+	for _, key := range envKeys {
+		os.Unsetenv(key)
+	}
+
+	cfg := GetServerConfig()
+
+	assert.Equal(t, "", cfg.Static)
+	assert.Equal(t, "/", cfg.StaticMountPath)
+	assert.Equal(t, 3600, cfg.StaticMaxAge)
+	assert.False(t, cfg.StaticSPA)
+	assert.Equal(t, []string{"/api", "/metrics"}, cfg.StaticAPIPrefixes)
+}
+
+func TestGetServerConfig_StaticFromEnv(t *testing.T) {
+	os.Setenv("server.static", "./public")
+	os.Setenv("server.static.spa", "true")
+	os.Setenv("server.static.api_prefixes", "/v1, ,/internal")
+	defer func() {
+		os.Unsetenv("server.static")
+		os.Unsetenv("server.static.spa")
+		os.Unsetenv("server.static.api_prefixes")
+	}()
+
+	cfg := GetServerConfig()
+
+	assert.Equal(t, "./public", cfg.Static)
+	assert.True(t, cfg.StaticSPA)
+	assert.Equal(t, []string{"/v1", "/internal"}, cfg.StaticAPIPrefixes)
+}
+
+func TestServerConfig_ListenSpec(t *testing.T) {
+	cfg := ServerConfig{Host: "localhost", Port: "9000"}
+	assert.Equal(t, "tcp://localhost:9000", cfg.ListenSpec())
+
+	cfg.Listen = "unix:///run/api.sock"
+	assert.Equal(t, "unix:///run/api.sock", cfg.ListenSpec())
+}
+
+func TestGetCORSConfig_GroupOverrides(t *testing.T) {
+	os.Setenv("cors.allowed_origins", "https://app.example.com")
+	os.Setenv("cors.protected.allowed_origins", "https://admin.example.com")
+	os.Setenv("cors.protected.allow_credentials", "true")
+	defer func() {
+		os.Unsetenv("cors.allowed_origins")
+		os.Unsetenv("cors.protected.allowed_origins")
+		os.Unsetenv("cors.protected.allow_credentials")
+	}()
+
+	public := GetCORSConfig("public")
+	assert.Equal(t, []string{"https://app.example.com"}, public.AllowedOrigins)
+	assert.False(t, public.AllowCredentials)
+	assert.Equal(t, 600, public.MaxAge)
+
+	protected := GetCORSConfig("protected")
+	assert.Equal(t, []string{"https://admin.example.com"}, protected.AllowedOrigins)
+	assert.True(t, protected.AllowCredentials)
+}
+
+func TestGetCORSConfig_DisabledByDefault(t *testing.T) {
+	os.Unsetenv("cors.allowed_origins")
+	assert.False(t, GetCORSConfig("public").Enabled())
+}
+
+func TestGetRateLimitConfig_RoutesAndOverrides(t *testing.T) {
+	os.Setenv("ratelimit.enabled", "true")
+	os.Setenv("ratelimit.limit", "50")
+	os.Setenv("ratelimit.window", "30s")
+	os.Setenv("ratelimit.protected.key", "principal")
+	os.Setenv("ratelimit.routes", "GET /ping=10/1s, invalid, POST /orders=x/1m")
+	defer func() {
+		os.Unsetenv("ratelimit.enabled")
+		os.Unsetenv("ratelimit.limit")
+		os.Unsetenv("ratelimit.window")
+		os.Unsetenv("ratelimit.protected.key")
+		os.Unsetenv("ratelimit.routes")
+	}()
+
+	public := GetRateLimitConfig("public")
+	assert.True(t, public.Enabled)
+	assert.Equal(t, "memory", public.Store)
+	assert.Equal(t, "token_bucket", public.Algorithm)
+	assert.Equal(t, 50, public.Limit)
+	assert.Equal(t, 30*time.Second, public.Window)
+	assert.Equal(t, "ip", public.KeyBy)
+	assert.Equal(t, []RateLimitRoute{{Method: "GET", Path: "/ping", Limit: 10, Window: time.Second}}, public.Routes)
+
+	assert.Equal(t, "principal", GetRateLimitConfig("protected").KeyBy)
+}
+
+func TestRateLimitConfig_Validate(t *testing.T) {
+	assert.NoError(t, RateLimitConfig{Limit: 100, Window: time.Minute}.Validate())
+	assert.Error(t, RateLimitConfig{Limit: 0, Window: time.Minute}.Validate())
+	assert.Error(t, RateLimitConfig{Limit: -1, Window: time.Minute}.Validate())
+	assert.Error(t, RateLimitConfig{Limit: 100, Window: 0}.Validate())
+}
+
+func TestGetSecurityHeadersConfig(t *testing.T) {
+	t.Setenv("environment", "development")
+	cfg := GetSecurityHeadersConfig()
+	assert.True(t, cfg.Enabled)
+	assert.Zero(t, cfg.HSTSMaxAge)
+	assert.Equal(t, "SAMEORIGIN", cfg.FrameOptions)
+
+	t.Setenv("environment", "production")
+	t.Setenv("security.frame_options", "SAMEORIGIN")
+	t.Setenv("security.csp_overrides", "/metrics=default-src 'none'|/app/=default-src 'self'; img-src *|invalid")
+	cfg = GetSecurityHeadersConfig()
+	assert.Equal(t, 63072000, cfg.HSTSMaxAge)
+	assert.True(t, cfg.HSTSIncludeSubdomains)
+	assert.Equal(t, "default-src 'none'; frame-ancestors 'none'", cfg.ContentSecurityPolicy)
+	assert.Equal(t, "SAMEORIGIN", cfg.FrameOptions, "explicit settings win over production defaults")
+	assert.Equal(t, map[string]string{
+		"/metrics": "default-src 'none'",
+		"/app/":    "default-src 'self'; img-src *",
+	}, cfg.CSPOverrides)
+}
+
+func TestGetCompressionConfig(t *testing.T) {
+	cfg := GetCompressionConfig()
+	assert.True(t, cfg.Enabled)
+	assert.Equal(t, []string{"br", "zstd", "gzip"}, cfg.Encodings)
+	assert.Equal(t, 1024, cfg.MinSize)
+	assert.Contains(t, cfg.ContentTypes, "application/json")
+	assert.Equal(t, int64(10<<20), cfg.MaxDecompressedSize)
+
+	t.Setenv("compression.encodings", "gzip")
+	t.Setenv("compression.max_decompressed_size", "0")
+	cfg = GetCompressionConfig()
+	assert.Equal(t, []string{"gzip"}, cfg.Encodings)
+	assert.Zero(t, cfg.MaxDecompressedSize)
+}
+
+func TestGetTimeoutConfig(t *testing.T) {
+	cfg := GetTimeoutConfig()
+	assert.Equal(t, 30*time.Second, cfg.Default)
+	assert.Equal(t, 504, cfg.StatusCode)
+	assert.Contains(t, cfg.Routes, TimeoutRoute{Method: "GET", Path: "/debug/pprof/profile", Timeout: 0})
+
+	t.Setenv("timeout.routes", "get /ping=1s,POST /reports=2m,invalid,GET /x=-1s")
+	t.Setenv("timeout.status", "418")
+	cfg = GetTimeoutConfig()
+	assert.Equal(t, []TimeoutRoute{
+		{Method: "GET", Path: "/ping", Timeout: time.Second},
+		{Method: "POST", Path: "/reports", Timeout: 2 * time.Minute},
+	}, cfg.Routes)
+	assert.Equal(t, 504, cfg.StatusCode)
+}
+
+func TestGetConcurrencyConfig(t *testing.T) {
+	cfg := GetConcurrencyConfig()
+	assert.False(t, cfg.Enabled)
+	assert.Equal(t, 1000, cfg.Limit)
+	assert.Equal(t, map[string]int{"public": 0, "protected": 0}, cfg.GroupLimits)
+	assert.Equal(t, "static", cfg.Mode)
+	assert.Equal(t, []string{"/ping", "/metrics", "/health"}, cfg.Exempt)
+
+	t.Setenv("concurrency.protected.limit", "50")
+	t.Setenv("concurrency.mode", "fifo")
+	cfg = GetConcurrencyConfig()
+	assert.Equal(t, 50, cfg.GroupLimits["protected"])
+	assert.Equal(t, "static", cfg.Mode, "invalid modes fall back to static")
+}
+
+func TestGetServerConfig_ClientIP(t *testing.T) {
+	cfg := GetServerConfig()
+	assert.Empty(t, cfg.TrustedProxies)
+	assert.Equal(t, []string{"X-Forwarded-For", "X-Real-IP"}, cfg.ClientIPHeaders)
+
+	t.Setenv("server.trusted_proxies", "10.0.0.0/8, 192.0.2.1")
+	t.Setenv("server.client_ip_headers", "CF-Connecting-IP")
+	cfg = GetServerConfig()
+	assert.Equal(t, []string{"10.0.0.0/8", "192.0.2.1"}, cfg.TrustedProxies)
+	assert.Equal(t, []string{"CF-Connecting-IP"}, cfg.ClientIPHeaders)
+}
+
+func TestGetIPFilterConfig(t *testing.T) {
+	assert.False(t, GetIPFilterConfig("protected").Enabled())
+
+	t.Setenv("ipfilter.deny", "203.0.113.0/24")
+	t.Setenv("ipfilter.protected.allow", "10.0.0.0/8")
+	protected := GetIPFilterConfig("protected")
+	assert.Equal(t, []string{"10.0.0.0/8"}, protected.Allow)
+	assert.Equal(t, []string{"203.0.113.0/24"}, protected.Deny)
+
+	public := GetIPFilterConfig("public")
+	assert.Empty(t, public.Allow)
+	assert.True(t, public.Enabled())
+}
+
+func TestGetIdempotencyConfig(t *testing.T) {
+	cfg := GetIdempotencyConfig()
+	assert.True(t, cfg.Enabled)
+	assert.Equal(t, "memory", cfg.Store)
+	assert.Equal(t, 24*time.Hour, cfg.TTL)
+	assert.Equal(t, int64(1<<20), cfg.MaxBodySize)
+
+	t.Setenv("idempotency.store", "postgres")
+	t.Setenv("idempotency.ttl", "1h")
+	cfg = GetIdempotencyConfig()
+	assert.Equal(t, "postgres", cfg.Store)
+	assert.Equal(t, time.Hour, cfg.TTL)
+}
+
+func TestGetVersioningConfig(t *testing.T) {
+	cfg := GetVersioningConfig()
+	assert.Equal(t, "v1", cfg.DefaultVersion)
+	assert.Empty(t, cfg.Deprecations)
+
+	t.Setenv("api.deprecations", "v1=2026-01-01/2026-12-31T12:00:00Z,get /v2/ping=2026-06-01,v3=soon,v4")
+	cfg = GetVersioningConfig()
+	if !assert.Len(t, cfg.Deprecations, 2) {
+		return
+	}
+	assert.Equal(t, APIDeprecation{
+		Target: "v1",
+		Since:  time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Sunset: time.Date(2026, 12, 31, 12, 0, 0, 0, time.UTC),
+	}, cfg.Deprecations[0])
+	assert.Equal(t, "GET /v2/ping", cfg.Deprecations[1].Target)
+	assert.True(t, cfg.Deprecations[1].Sunset.IsZero())
+}
+
+func TestGetResponseCacheConfig(t *testing.T) {
+	cfg := GetResponseCacheConfig()
+	assert.False(t, cfg.Enabled)
+	assert.Equal(t, 10000, cfg.MaxEntries)
+	assert.Empty(t, cfg.Routes)
+	assert.True(t, cfg.KeyQuery)
+	assert.Equal(t, []string{"Accept"}, cfg.KeyHeaders)
+	assert.True(t, cfg.KeyPrincipal)
+
+	t.Setenv("cache.routes", "GET /ping=30s,get /orders=0,POST /orders=1m,GET /bad=soon")
+	cfg = GetResponseCacheConfig()
+	assert.Equal(t, []CacheRoute{{Path: "/ping", TTL: 30 * time.Second}, {Path: "/orders"}}, cfg.Routes)
+}
+
+func TestGetMaintenanceConfig(t *testing.T) {
+	cfg := GetMaintenanceConfig()
+	assert.False(t, cfg.Enabled)
+	assert.Equal(t, 5*time.Minute, cfg.RetryAfter)
+	assert.Equal(t, "X-API-Key", cfg.APIKeyHeader)
+	assert.Equal(t, []string{"/ping", "/health", "/metrics"}, cfg.Exempt)
+
+	t.Setenv("maintenance.allow_ips", "10.0.0.0/8, 192.168.1.10")
+	cfg = GetMaintenanceConfig()
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.10"}, cfg.AllowIPs)
+}
+
+func TestReloadConfiguration(t *testing.T) {
+	t.Setenv("MAINTENANCE_ENABLED", "false")
+	t.Setenv("MAINTENANCE_MESSAGE", "")
+	t.Setenv("maintenance.enabled", "false")
+	t.Setenv("maintenance.message", "")
+	t.Chdir(t.TempDir())
+	assert.Error(t, ReloadConfiguration())
+
+	err := os.WriteFile(".env", []byte("MAINTENANCE_ENABLED=true\nMAINTENANCE_MESSAGE=Migrating the database\n"), 0o600)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, ReloadConfiguration())
+	cfg := GetMaintenanceConfig()
+	assert.True(t, cfg.Enabled)
+	assert.Equal(t, "Migrating the database", cfg.Message)
+}
+
+func TestGetSSEConfig(t *testing.T) {
+	cfg := GetSSEConfig()
+	assert.Equal(t, 15*time.Second, cfg.Heartbeat)
+	assert.Equal(t, 3*time.Second, cfg.Retry)
+	assert.Equal(t, 100, cfg.ReplaySize)
+	assert.Equal(t, 64, cfg.BufferSize)
+
+	t.Setenv("sse.replay_size", "10")
+	assert.Equal(t, 10, GetSSEConfig().ReplaySize)
+}
+
+func TestGetGRPCConfig(t *testing.T) {
+	cfg := GetGRPCConfig()
+	assert.False(t, cfg.Enabled())
+	assert.True(t, cfg.Reflection)
+	assert.Equal(t, 5*time.Second, cfg.HealthInterval)
+
+	t.Setenv("grpc.listen", "tcp://:9090")
+	t.Setenv("environment", "production")
+	cfg = GetGRPCConfig()
+	assert.True(t, cfg.Enabled())
+	assert.False(t, cfg.Reflection)
+}
+
+func TestGetGraphQLConfig(t *testing.T) {
+	cfg := GetGraphQLConfig()
+	assert.True(t, cfg.Enabled)
+	assert.Equal(t, "/graphql", cfg.Path)
+	assert.Equal(t, 10, cfg.MaxDepth)
+	assert.Equal(t, 200, cfg.MaxComplexity)
+	assert.True(t, cfg.Playground)
+
+	t.Setenv("server.mode", "release")
+	assert.False(t, GetGraphQLConfig().Playground)
+	t.Setenv("graphql.playground", "true")
+	assert.True(t, GetGraphQLConfig().Playground)
+}
+
+func TestGetWebSocketConfig(t *testing.T) {
+	cfg := GetWebSocketConfig()
+	assert.Equal(t, 30*time.Second, cfg.PingInterval)
+	assert.Equal(t, 60*time.Second, cfg.PongTimeout)
+	assert.Equal(t, 32, cfg.SendBuffer)
+	assert.Equal(t, int64(64<<10), cfg.MaxMessageSize)
+	assert.Empty(t, cfg.AllowedOrigins)
+
+	t.Setenv("websocket.allowed_origins", "https://app.example.com")
+	assert.Equal(t, []string{"https://app.example.com"}, GetWebSocketConfig().AllowedOrigins)
+}
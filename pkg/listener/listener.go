@@ -0,0 +1,176 @@
+package listener
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SchemeTCP     = "tcp"
+	SchemeUnix    = "unix"
+	SchemeSystemd = "systemd"
+
+	// first file descriptor passed by systemd (SD_LISTEN_FDS_START)
+	systemdFdStart = 3
+)
+
+// Spec is a parsed listener address such as tcp://0.0.0.0:9000, unix:///run/api.sock or systemd:.
+type Spec struct {
+	Scheme string
+	// Address is host:port for tcp, a file path for unix and an optional
+	// socket name (FileDescriptorName=) for systemd.
+	Address string
+}
+
+// Options tunes how listeners are created.
+type Options struct {
+	// SocketMode is applied to unix socket files after they are created. Sockets are
+	// owner-only until then, and when it is 0.
+	SocketMode os.FileMode
+}
+
+func (s Spec) String() string {
+	if s.Scheme == SchemeSystemd {
+		return s.Scheme + ":" + s.Address
+	}
+	return s.Scheme + "://" + s.Address
+}
+
+// Local reports whether only this host can connect: unix sockets and tcp on a
+// loopback address. Sockets passed by systemd are not known to be local.
+func (s Spec) Local() bool {
+	switch s.Scheme {
+	case SchemeUnix:
+		return true
+	case SchemeTCP:
+		host, _, err := net.SplitHostPort(s.Address)
+		if err != nil {
+			return false
+		}
+		if host == "localhost" {
+			return true
+		}
+		ip := net.ParseIP(host)
+		return ip != nil && ip.IsLoopback()
+	}
+	return false
+}
+
+// ParseSpec parses a listener spec. A bare host:port is treated as tcp.
+func ParseSpec(spec string) (Spec, error) {
+	switch {
+	case strings.HasPrefix(spec, SchemeTCP+"://"):
+		address := strings.TrimPrefix(spec, SchemeTCP+"://")
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			return Spec{}, fmt.Errorf("invalid tcp listener %q: %w", spec, err)
+		}
+		return Spec{Scheme: SchemeTCP, Address: address}, nil
+	case strings.HasPrefix(spec, SchemeUnix+"://"):
+		address := strings.TrimPrefix(spec, SchemeUnix+"://")
+		if address == "" {
+			return Spec{}, fmt.Errorf("invalid unix listener %q: missing socket path", spec)
+		}
+		return Spec{Scheme: SchemeUnix, Address: address}, nil
+	case strings.HasPrefix(spec, SchemeSystemd+":"):
+		return Spec{Scheme: SchemeSystemd, Address: strings.TrimPrefix(spec, SchemeSystemd+":")}, nil
+	case !strings.Contains(spec, "://"):
+		if _, _, err := net.SplitHostPort(spec); err != nil {
+			return Spec{}, fmt.Errorf("invalid listener %q: %w", spec, err)
+		}
+		return Spec{Scheme: SchemeTCP, Address: spec}, nil
+	}
+	return Spec{}, fmt.Errorf("unsupported listener scheme in %q", spec)
+}
+
+// Listen parses spec and opens the matching listener.
+func Listen(spec string, options Options) (net.Listener, error) {
+	parsed, err := ParseSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	switch parsed.Scheme {
+	case SchemeUnix:
+		return listenUnix(parsed.Address, options.SocketMode)
+	case SchemeSystemd:
+		return listenSystemd(parsed.Address)
+	}
+	return net.Listen("tcp", parsed.Address)
+}
+
+// listenUnix creates a unix socket, replacing a stale socket file left by a previous run.
+// The socket file is created owner-only, so no other user can connect before mode is
+// applied, and it is removed when the listener is closed.
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+	var ln net.Listener
+	err := withUmask(0o177, func() (err error) {
+		ln, err = net.Listen("unix", path)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	ln.(*net.UnixListener).SetUnlinkOnClose(true)
+	if mode != 0 {
+		if err := os.Chmod(path, mode); err != nil {
+			ln.Close()
+			return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+		}
+	}
+	return ln, nil
+}
+
+func removeStaleSocket(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("refusing to replace %s: not a socket", path)
+	}
+	// a socket that still accepts connections belongs to a running process
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return fmt.Errorf("socket %s is already in use", path)
+	}
+	return os.Remove(path)
+}
+
+// listenSystemd takes over a socket passed by systemd socket activation (sd_listen_fds).
+// When name is empty the first passed socket is used, otherwise the one whose
+// FileDescriptorName matches.
+func listenSystemd(name string) (net.Listener, error) {
+	if pid, err := strconv.Atoi(os.Getenv("LISTEN_PID")); err != nil || pid != os.Getpid() {
+		return nil, errors.New("no sockets passed by systemd (LISTEN_PID does not match)")
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count < 1 {
+		return nil, errors.New("no sockets passed by systemd (LISTEN_FDS is empty)")
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	for i := 0; i < count; i++ {
+		if name != "" && (i >= len(names) || names[i] != name) {
+			continue
+		}
+		file := os.NewFile(uintptr(systemdFdStart+i), fmt.Sprintf("systemd-socket-%d", i))
+		ln, err := net.FileListener(file)
+		// FileListener dups the descriptor, the original is no longer needed
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to use systemd socket %d: %w", i, err)
+		}
+		return ln, nil
+	}
+	return nil, fmt.Errorf("systemd socket %q not found in LISTEN_FDNAMES", name)
+}
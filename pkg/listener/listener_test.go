@@ -0,0 +1,127 @@
+package listener
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSpec(t *testing.T) {
+	cases := []struct {
+		spec     string
+		expected Spec
+	}{
+		{"tcp://0.0.0.0:9000", Spec{Scheme: SchemeTCP, Address: "0.0.0.0:9000"}},
+		{"localhost:9000", Spec{Scheme: SchemeTCP, Address: "localhost:9000"}},
+		{"unix:///run/api.sock", Spec{Scheme: SchemeUnix, Address: "/run/api.sock"}},
+		{"systemd:", Spec{Scheme: SchemeSystemd, Address: ""}},
+		{"systemd:http", Spec{Scheme: SchemeSystemd, Address: "http"}},
+	}
+	for _, tc := range cases {
+		parsed, err := ParseSpec(tc.spec)
+		require.NoError(t, err, tc.spec)
+		assert.Equal(t, tc.expected, parsed, tc.spec)
+	}
+}
+
+func TestParseSpec_Invalid(t *testing.T) {
+	for _, spec := range []string{"udp://0.0.0.0:9000", "tcp://no-port", "unix://", "localhost"} {
+		_, err := ParseSpec(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestSpec_Local(t *testing.T) {
+	cases := map[string]bool{
+		"tcp://127.0.0.1:9001":   true,
+		"tcp://[::1]:9001":       true,
+		"localhost:9001":         true,
+		"unix:///run/admin.sock": true,
+		"tcp://0.0.0.0:9001":     false,
+		"tcp://:9001":            false,
+		"tcp://10.0.0.5:9001":    false,
+		"systemd:admin":          false,
+	}
+	for spec, local := range cases {
+		parsed, err := ParseSpec(spec)
+		require.NoError(t, err, spec)
+		assert.Equal(t, local, parsed.Local(), spec)
+	}
+}
+
+func TestListen_TCP(t *testing.T) {
+	ln, err := Listen("tcp://127.0.0.1:0", Options{})
+	require.NoError(t, err)
+	defer ln.Close()
+
+	assert.Equal(t, "tcp", ln.Addr().Network())
+}
+
+func TestListen_UnixSetsModeAndCleansUp(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.sock")
+
+	ln, err := Listen("unix://"+path, Options{SocketMode: 0600})
+	require.NoError(t, err)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	require.NoError(t, ln.Close())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "socket file should be removed on close")
+}
+
+func TestListen_UnixReplacesStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.sock")
+
+	stale, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	ln, err := Listen("unix://"+path, Options{})
+	require.NoError(t, err)
+	ln.Close()
+}
+
+func TestListen_UnixRefusesSocketInUse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.sock")
+
+	active, err := Listen("unix://"+path, Options{})
+	require.NoError(t, err)
+	defer active.Close()
+
+	_, err = Listen("unix://"+path, Options{})
+	assert.Error(t, err)
+}
+
+func TestListen_UnixRefusesRegularFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.sock")
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0600))
+
+	_, err := Listen("unix://"+path, Options{})
+	assert.Error(t, err)
+}
+
+func TestListen_SystemdWithoutActivation(t *testing.T) {
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+
+	_, err := Listen("systemd:", Options{})
+	assert.Error(t, err)
+}
+
+func TestListen_SystemdUnknownName(t *testing.T) {
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	t.Setenv("LISTEN_FDS", "1")
+	t.Setenv("LISTEN_FDNAMES", "http")
+
+	_, err := Listen("systemd:admin", Options{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package listener
+
+// withUmask runs fn; there is no umask on this platform.
+func withUmask(mask int, fn func() error) error {
+	return fn()
+}
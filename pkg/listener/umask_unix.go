@@ -0,0 +1,20 @@
+//go:build unix
+
+package listener
+
+import (
+	"sync"
+	"syscall"
+)
+
+var umaskMu sync.Mutex
+
+// withUmask runs fn with the process umask set to mask. The umask is process-wide:
+// files other goroutines create meanwhile get it too.
+func withUmask(mask int, fn func() error) error {
+	umaskMu.Lock()
+	defer umaskMu.Unlock()
+	previous := syscall.Umask(mask)
+	defer syscall.Umask(previous)
+	return fn()
+}
@@ -0,0 +1,28 @@
+//go:build unix
+
+package listener
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListen_UnixSocketIsOwnerOnlyUntilModeIsSet(t *testing.T) {
+	previous := syscall.Umask(0o022)
+	defer syscall.Umask(previous)
+	path := filepath.Join(t.TempDir(), "api.sock")
+
+	ln, err := Listen("unix://"+path, Options{})
+	require.NoError(t, err)
+	defer ln.Close()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	assert.Equal(t, 0o022, syscall.Umask(0o022), "the umask is restored")
+}
@@ -1,12 +1,15 @@
 package log
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"runtime"
 	"strings"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/requestid"
 )
 
 var logger = logrus.New()
@@ -48,6 +51,22 @@ func SetLogLevel(level string) error {
 	logger.Level = lvl
 	return nil
 }
+
+// ContextFields returns fields (merged with the optional extra fields) that
+// correlate a log entry with the request carried by ctx.
+func ContextFields(ctx context.Context, fields ...Fields) Fields {
+	merged := Fields{}
+	if len(fields) > 0 {
+		for key, value := range fields[0] {
+			merged[key] = value
+		}
+	}
+	if id := requestid.FromContext(ctx); id != "" {
+		merged[requestid.Key] = id
+	}
+	return merged
+}
+
 func Debug(message interface{}, fields ...Fields) {
 	logWithFields(logrus.DebugLevel, message, fields...)
 }
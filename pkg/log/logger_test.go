@@ -2,8 +2,10 @@ package log
 
 import (
 	"bytes"
+	"context"
 	"testing"
 
+	"github.com/oswaldom-code/api-template-gin/pkg/requestid"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 )
@@ -71,3 +73,12 @@ func TestLogLevelFiltering(t *testing.T) {
 	assert.NotContains(t, buf.String(), "This info message should not appear")
 	assert.Contains(t, buf.String(), "This warning message should appear")
 }
+
+func TestContextFields(t *testing.T) {
+	ctx := requestid.NewContext(context.Background(), "req-1")
+
+	fields := ContextFields(ctx, Fields{"myKey": "myValue"})
+	assert.Equal(t, Fields{"myKey": "myValue", "request_id": "req-1"}, fields)
+
+	assert.Equal(t, Fields{}, ContextFields(context.Background()))
+}
@@ -0,0 +1,34 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_GeneratesUUIDv7(t *testing.T) {
+	id, err := uuid.Parse(New())
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Version(7), id.Version())
+	assert.NotEqual(t, New(), New())
+}
+
+func TestIsValid(t *testing.T) {
+	assert.True(t, IsValid("0190b6b8-7d4e-7c3a-9f1e-2a6b9c0d1e2f"))
+	assert.True(t, IsValid("req_123.abc:1"))
+	assert.False(t, IsValid(""))
+	assert.False(t, IsValid("bad id"))
+	assert.False(t, IsValid("line\nbreak"))
+	assert.False(t, IsValid(strings.Repeat("a", 129)))
+}
+
+func TestContext(t *testing.T) {
+	assert.Empty(t, FromContext(context.Background()))
+
+	ctx := NewContext(context.Background(), "abc")
+	assert.Equal(t, "abc", FromContext(ctx))
+}
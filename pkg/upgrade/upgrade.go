@@ -0,0 +1,133 @@
+// Package upgrade replaces the running process with a new process of the binary
+// without refusing connections: the listening sockets are passed to the new
+// process, which reports when it is ready, and the old one then drains and exits.
+package upgrade
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/listener"
+)
+
+const (
+	// envListeners lists the specs of the passed sockets, in descriptor order.
+	envListeners = "UPGRADE_LISTENERS"
+	// envReadyFd is the descriptor the new process reports readiness on.
+	envReadyFd = "UPGRADE_READY_FD"
+
+	// first descriptor of the passed sockets, after stdin, stdout and stderr
+	fdStart = 3
+)
+
+// ErrNotSupported is returned by Upgrade on platforms without descriptor passing.
+var ErrNotSupported = errors.New("upgrades are not supported on this platform")
+
+type namedListener struct {
+	spec string
+	ln   net.Listener
+}
+
+// Upgrader opens the listeners of the process, taking over the ones passed by the
+// process it replaces, and hands them over to the next one on Upgrade.
+type Upgrader struct {
+	mu        sync.Mutex
+	timeout   time.Duration
+	inherited map[string]*os.File
+	listeners []namedListener
+	upgraded  bool
+	// ready is the pipe to the previous process until Ready is called
+	ready *os.File
+	// command line of the new process, os.Executable and os.Args by default
+	executable string
+	args       []string
+}
+
+// New returns an Upgrader giving the new process of an upgrade timeout to be ready.
+// In a process started by an upgrade it takes over the passed sockets.
+func New(timeout time.Duration) (*Upgrader, error) {
+	u := &Upgrader{timeout: timeout, inherited: map[string]*os.File{}, args: os.Args[1:]}
+	specs := os.Getenv(envListeners)
+	readyFd := os.Getenv(envReadyFd)
+	// the variables are meant for this process only
+	os.Unsetenv(envListeners)
+	os.Unsetenv(envReadyFd)
+	if readyFd == "" {
+		return u, nil
+	}
+
+	fd, err := strconv.Atoi(readyFd)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", envReadyFd, readyFd, err)
+	}
+	u.upgraded = true
+	u.ready = os.NewFile(uintptr(fd), "upgrade-ready")
+	var names []string
+	if err := json.Unmarshal([]byte(specs), &names); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", envListeners, err)
+	}
+	for i, spec := range names {
+		u.inherited[spec] = os.NewFile(uintptr(fdStart+i), "upgrade-"+spec)
+	}
+	return u, nil
+}
+
+// Upgraded reports whether the process was started by an upgrade.
+func (u *Upgrader) Upgraded() bool {
+	return u.upgraded
+}
+
+// Listen returns the socket of spec passed by the previous process, or opens it.
+func (u *Upgrader) Listen(spec string, options listener.Options) (net.Listener, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	var ln net.Listener
+	if file, ok := u.inherited[spec]; ok {
+		delete(u.inherited, spec)
+		var err error
+		ln, err = net.FileListener(file)
+		// FileListener dups the descriptor, the original is no longer needed
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to take over %s: %w", spec, err)
+		}
+		if unixLn, ok := ln.(*net.UnixListener); ok {
+			// the socket file is this process's to remove now
+			unixLn.SetUnlinkOnClose(true)
+		}
+	} else {
+		var err error
+		if ln, err = listener.Listen(spec, options); err != nil {
+			return nil, err
+		}
+	}
+	u.listeners = append(u.listeners, namedListener{spec: spec, ln: ln})
+	return ln, nil
+}
+
+// Ready tells the previous process that this one serves, so it can drain and exit.
+// Passed sockets no longer configured are closed. It does nothing in a process not
+// started by an upgrade.
+func (u *Upgrader) Ready() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for spec, file := range u.inherited {
+		file.Close()
+		delete(u.inherited, spec)
+	}
+	if u.ready == nil {
+		return nil
+	}
+	_, err := u.ready.Write([]byte{1})
+	u.ready.Close()
+	u.ready = nil
+	return err
+}
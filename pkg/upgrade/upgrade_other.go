@@ -0,0 +1,13 @@
+//go:build !unix
+
+package upgrade
+
+import "os"
+
+// Signal is nil: no signal asks for an upgrade on this platform.
+var Signal os.Signal
+
+// Upgrade returns ErrNotSupported.
+func (u *Upgrader) Upgrade() error {
+	return ErrNotSupported
+}
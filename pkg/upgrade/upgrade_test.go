@@ -0,0 +1,173 @@
+//go:build unix
+
+package upgrade
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/listener"
+)
+
+// TestMain runs the new process of the upgrade tests when UPGRADE_TEST_CHILD is set.
+func TestMain(m *testing.M) {
+	switch os.Getenv("UPGRADE_TEST_CHILD") {
+	case "":
+		os.Exit(m.Run())
+	case "fail":
+		os.Exit(1)
+	case "hang":
+		time.Sleep(time.Minute)
+		os.Exit(1)
+	}
+	runChild()
+}
+
+// runChild serves "child" on the socket passed by the test until /exit is requested.
+func runChild() {
+	u, err := New(time.Second)
+	if err != nil || !u.Upgraded() {
+		os.Exit(2)
+	}
+	ln, err := u.Listen(os.Getenv("UPGRADE_TEST_SPEC"), listener.Options{})
+	if err != nil {
+		os.Exit(3)
+	}
+	done := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, "child") })
+	mux.HandleFunc("/pid", func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, strconv.Itoa(os.Getpid())) })
+	mux.HandleFunc("/exit", func(w http.ResponseWriter, r *http.Request) { close(done) })
+	go http.Serve(ln, mux)
+	if err := u.Ready(); err != nil {
+		os.Exit(4)
+	}
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+	}
+	ln.Close()
+	os.Exit(0)
+}
+
+func newTestUpgrader(t *testing.T, child string) *Upgrader {
+	t.Setenv("UPGRADE_TEST_CHILD", child)
+	u, err := New(2 * time.Second)
+	require.NoError(t, err)
+	u.args = []string{"-test.run=^$"}
+	return u
+}
+
+func serveParent(t *testing.T, u *Upgrader, spec string) (net.Listener, *http.Client) {
+	t.Setenv("UPGRADE_TEST_SPEC", spec)
+	ln, err := u.Listen(spec, listener.Options{})
+	require.NoError(t, err)
+	go http.Serve(ln, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, "parent") }))
+
+	addr := ln.Addr()
+	return ln, &http.Client{Transport: &http.Transport{
+		DisableKeepAlives: true,
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, addr.Network(), addr.String())
+		},
+	}}
+}
+
+func get(t *testing.T, client *http.Client, path string) string {
+	resp, err := client.Get("http://api" + path)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestUpgrade_HandsOverListeners(t *testing.T) {
+	for name, spec := range map[string]string{
+		"tcp":  "tcp://127.0.0.1:0",
+		"unix": "unix://" + filepath.Join(t.TempDir(), "api.sock"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			u := newTestUpgrader(t, "serve")
+			ln, client := serveParent(t, u, spec)
+			assert.Equal(t, "parent", get(t, client, "/"))
+
+			require.NoError(t, u.Upgrade())
+			require.NoError(t, ln.Close())
+			if name == "unix" {
+				_, err := os.Stat(ln.Addr().String())
+				assert.NoError(t, err, "the socket file stays for the new process")
+			}
+
+			for i := 0; i < 5; i++ {
+				assert.Equal(t, "child", get(t, client, "/"))
+			}
+			get(t, client, "/exit")
+		})
+	}
+}
+
+func TestUpgrade_RollsBackWhenTheNewProcessFails(t *testing.T) {
+	for _, child := range []string{"fail", "hang"} {
+		t.Run(child, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "api.sock")
+			u := newTestUpgrader(t, child)
+			u.timeout = 500 * time.Millisecond
+			ln, client := serveParent(t, u, "unix://"+path)
+
+			assert.Error(t, u.Upgrade())
+			assert.Equal(t, "parent", get(t, client, "/"))
+
+			require.NoError(t, ln.Close())
+			_, err := os.Stat(path)
+			assert.True(t, os.IsNotExist(err), "the socket file is still removed on close")
+		})
+	}
+}
+
+func TestUpgrade_AnnouncesTheNewMainProcess(t *testing.T) {
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "notify.sock")
+	manager, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+	defer manager.Close()
+	u := newTestUpgrader(t, "serve")
+	ln, client := serveParent(t, u, "unix://"+filepath.Join(dir, "api.sock"))
+
+	t.Setenv("NOTIFY_SOCKET", filepath.Join(dir, "missing.sock"))
+	assert.Error(t, u.Upgrade(), "the new process could not be announced")
+	assert.Equal(t, "parent", get(t, client, "/"))
+
+	t.Setenv("NOTIFY_SOCKET", socket)
+	require.NoError(t, u.Upgrade())
+	require.NoError(t, ln.Close())
+	state := make([]byte, 64)
+	require.NoError(t, manager.SetReadDeadline(time.Now().Add(time.Second)))
+	n, err := manager.Read(state)
+	require.NoError(t, err)
+	assert.Equal(t, "MAINPID="+get(t, client, "/pid"), string(state[:n]))
+	get(t, client, "/exit")
+}
+
+func TestNew_WithoutUpgrade(t *testing.T) {
+	u, err := New(time.Second)
+	require.NoError(t, err)
+	assert.False(t, u.Upgraded())
+	assert.NoError(t, u.Ready())
+}
+
+func TestNew_InvalidEnvironment(t *testing.T) {
+	t.Setenv(envReadyFd, "not-a-number")
+	_, err := New(time.Second)
+	assert.Error(t, err)
+}
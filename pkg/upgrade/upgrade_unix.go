@@ -0,0 +1,157 @@
+//go:build unix
+
+package upgrade
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Signal asks the process for an upgrade.
+var Signal os.Signal = syscall.SIGUSR2
+
+// Upgrade starts a new process of the binary, which may have been replaced on disk,
+// with the listening sockets and waits for it to be ready. Both processes accept
+// connections meanwhile. Under a service manager listening on NOTIFY_SOCKET, such
+// as systemd, the new process is then announced as the main one. On success the
+// caller drains its servers and exits; on failure the new process is killed and
+// this one keeps serving.
+func (u *Upgrader) Upgrade() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	executable := u.executable
+	if executable == "" {
+		var err error
+		if executable, err = os.Executable(); err != nil {
+			return fmt.Errorf("failed to find the executable: %w", err)
+		}
+	}
+
+	// the descriptors are passed as they are: os/exec would put the sockets, shared
+	// with the listeners of this process, in blocking mode
+	fds := []uintptr{0, 1, 2}
+	defer func() {
+		for _, fd := range fds[fdStart:] {
+			syscall.Close(int(fd))
+		}
+	}()
+	specs := make([]string, 0, len(u.listeners))
+	for _, l := range u.listeners {
+		fd, err := dup(l.ln)
+		if err != nil {
+			return fmt.Errorf("failed to pass listener %s: %w", l.spec, err)
+		}
+		fds = append(fds, fd)
+		specs = append(specs, l.spec)
+	}
+	encodedSpecs, err := json.Marshal(specs)
+	if err != nil {
+		return err
+	}
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer readyR.Close()
+
+	env := append(os.Environ(),
+		envListeners+"="+string(encodedSpecs),
+		envReadyFd+"="+strconv.Itoa(len(fds)))
+	pid, err := syscall.ForkExec(executable, append([]string{executable}, u.args...), &syscall.ProcAttr{
+		Env:   env,
+		Files: append(fds, readyW.Fd()),
+	})
+	// only the new process holds the write end now, its exit ends the read
+	readyW.Close()
+	if err != nil {
+		return fmt.Errorf("failed to start %s: %w", executable, err)
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+
+	ready := make(chan error, 1)
+	go func() {
+		_, err := readyR.Read(make([]byte, 1))
+		if errors.Is(err, io.EOF) {
+			err = errors.New("exited before it was ready")
+		}
+		ready <- err
+	}()
+	// reap the new process when it exits, now or after this one
+	go process.Wait()
+
+	timer := time.NewTimer(u.timeout)
+	defer timer.Stop()
+	select {
+	case err = <-ready:
+	case <-timer.C:
+		err = fmt.Errorf("not ready after %s", u.timeout)
+	}
+	if err != nil {
+		process.Kill()
+		return fmt.Errorf("new process %d failed: %w", pid, err)
+	}
+	// the service manager would stop the service when this process exits
+	if err := notify("MAINPID=" + strconv.Itoa(pid)); err != nil {
+		process.Kill()
+		return fmt.Errorf("failed to announce new process %d to the service manager: %w", pid, err)
+	}
+
+	for _, l := range u.listeners {
+		if unixLn, ok := l.ln.(*net.UnixListener); ok {
+			// closing this process's listener must not remove the socket file in use
+			unixLn.SetUnlinkOnClose(false)
+		}
+	}
+	return nil
+}
+
+// notify sends state to the service manager listening on NOTIFY_SOCKET, if any.
+func notify(state string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte(state))
+	return err
+}
+
+// dup duplicates the socket of ln, close-on-exec like the descriptors of the runtime.
+func dup(ln net.Listener) (uintptr, error) {
+	conn, ok := ln.(syscall.Conn)
+	if !ok {
+		return 0, fmt.Errorf("%T has no descriptor", ln)
+	}
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+	var fd int
+	var dupErr error
+	err = raw.Control(func(sysfd uintptr) {
+		syscall.ForkLock.RLock()
+		defer syscall.ForkLock.RUnlock()
+		if fd, dupErr = syscall.Dup(int(sysfd)); dupErr == nil {
+			syscall.CloseOnExec(fd)
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	return uintptr(fd), dupErr
+}
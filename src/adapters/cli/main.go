@@ -2,7 +2,10 @@ package cli
 
 import (
 	"fmt"
+	"net/http"
 
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/handlers"
 	Service "github.com/oswaldom-code/api-template-gin/src/application/system_services"
 	"github.com/spf13/cobra"
 )
@@ -23,10 +26,6 @@ func askConfirmation() bool {
 }
 
 func RunCliCmd(cmd *cobra.Command, args []string) error {
-	healthService, err := Service.HealthService()
-	if err != nil {
-		return fmt.Errorf("failed to initialize health service: %w", err)
-	}
 	function, err := cmd.Flags().GetString("function")
 	if err != nil {
 		fmt.Println("Error:", err)
@@ -34,13 +33,48 @@ func RunCliCmd(cmd *cobra.Command, args []string) error {
 	}
 	switch function {
 	case "test":
-		err = healthService.TestDb()
+		healthService, err := Service.HealthService()
+		if err != nil {
+			return fmt.Errorf("failed to initialize health service: %w", err)
+		}
+		err = healthService.TestDb(cmd.Context())
 		if err != nil {
 			fmt.Printf("> ❌Test error: %s\n", err)
 			return err
 		}
 		fmt.Println("> ✅ Test connection to database success ")
 		return nil
+	case "maintenance-on", "maintenance-off", "maintenance-status":
+		return runMaintenance(cmd, function)
+	}
+	return nil
+}
+
+// runMaintenance reads or toggles maintenance mode on the running server.
+func runMaintenance(cmd *cobra.Command, function string) error {
+	client, err := newMaintenanceClient(config.GetServerConfig())
+	if err != nil {
+		fmt.Printf("> ❌Maintenance error: %s\n", err)
+		return err
+	}
+	method, request := http.MethodPut, &handlers.MaintenanceRequest{}
+	switch function {
+	case "maintenance-on":
+		message, _ := cmd.Flags().GetString("message")
+		retryAfter, _ := cmd.Flags().GetDuration("retry-after")
+		enabled := true
+		request.Enabled, request.Message, request.RetryAfter = &enabled, message, int(retryAfter.Seconds())
+	case "maintenance-off":
+		enabled := false
+		request.Enabled = &enabled
+	default:
+		method, request = http.MethodGet, nil
+	}
+	status, err := client.do(cmd.Context(), method, request)
+	if err != nil {
+		fmt.Printf("> ❌Maintenance error: %s\n", err)
+		return err
 	}
+	printMaintenance(status)
 	return nil
 }
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/pkg/listener"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/handlers"
+)
+
+// maintenanceClient calls the maintenance endpoint of the running server: on the admin
+// listener when there is one, otherwise on the API listener with the auth secret.
+type maintenanceClient struct {
+	client *http.Client
+	url    string
+	secret string
+}
+
+func newMaintenanceClient(serverConfig config.ServerConfig) (*maintenanceClient, error) {
+	spec, secret := serverConfig.ListenSpec(), config.GetAuthenticationKey().Secret
+	if serverConfig.AdminEnabled() {
+		spec, secret = serverConfig.AdminListen, serverConfig.AdminSecret
+	}
+	parsed, err := listener.ParseSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	m := &maintenanceClient{client: &http.Client{Timeout: 10 * time.Second}, secret: secret}
+	switch parsed.Scheme {
+	case listener.SchemeTCP:
+		host, port, _ := net.SplitHostPort(parsed.Address)
+		if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+			host = "localhost"
+		}
+		m.url = "http://" + net.JoinHostPort(host, port) + "/maintenance"
+	case listener.SchemeUnix:
+		var dialer net.Dialer
+		m.client.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "unix", parsed.Address)
+			},
+		}
+		m.url = "http://unix/maintenance"
+	default:
+		return nil, fmt.Errorf("cannot reach the %s listener, configure a tcp or unix admin listener", parsed)
+	}
+	return m, nil
+}
+
+// do sends the request and returns the maintenance status from the response.
+func (m *maintenanceClient) do(ctx context.Context, method string, request *handlers.MaintenanceRequest) (map[string]any, error) {
+	var body bytes.Buffer
+	if request != nil {
+		if err := json.NewEncoder(&body).Encode(request); err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, m.url, &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", dto.MIMEJSON)
+	req.Header.Set("Accept", dto.MIMEJSON)
+	if m.secret != "" {
+		req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(m.secret)))
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach the server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errorResponse dto.ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&errorResponse); err != nil || errorResponse.Error.Message == "" {
+			return nil, fmt.Errorf("server answered %s", resp.Status)
+		}
+		return nil, fmt.Errorf("server answered %s: %s", resp.Status, errorResponse.Error.Message)
+	}
+	var response struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("invalid server response: %w", err)
+	}
+	return response.Data, nil
+}
+
+// printMaintenance prints the maintenance status returned by the server.
+func printMaintenance(status map[string]any) {
+	if enabled, _ := status["enabled"].(bool); !enabled {
+		fmt.Println("> ✅ Maintenance mode is off")
+		return
+	}
+	fmt.Printf("> 🚧 Maintenance mode is on since %v\n", status["since"])
+	if message, ok := status["message"]; ok {
+		fmt.Printf("  message: %v\n", message)
+	}
+	if retryAfter, ok := status["retry_after"]; ok {
+		fmt.Printf("  retry after: %vs\n", retryAfter)
+	}
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMaintenanceClient_Targets(t *testing.T) {
+	t.Setenv("auth.secret", "api_secret")
+
+	client, err := newMaintenanceClient(config.ServerConfig{Host: "0.0.0.0", Port: "9000"})
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:9000/maintenance", client.url)
+	assert.Equal(t, "api_secret", client.secret)
+
+	client, err = newMaintenanceClient(config.ServerConfig{AdminListen: "unix:///run/api-admin.sock", AdminSecret: "admin_secret"})
+	require.NoError(t, err)
+	assert.Equal(t, "http://unix/maintenance", client.url)
+	assert.Equal(t, "admin_secret", client.secret)
+
+	_, err = newMaintenanceClient(config.ServerConfig{AdminListen: "systemd:admin"})
+	assert.Error(t, err)
+}
+
+func TestMaintenanceClient_Do(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Basic "+base64.StdEncoding.EncodeToString([]byte("admin_secret")) {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"code":"UNAUTHORIZED","message":"Invalid or missing auth token"}}`))
+			return
+		}
+		var request handlers.MaintenanceRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/maintenance", r.URL.Path)
+		_, _ = w.Write([]byte(`{"data":{"enabled":true,"message":"` + request.Message + `"}}`))
+	}))
+	defer server.Close()
+	adminListen := "tcp://" + strings.TrimPrefix(server.URL, "http://")
+	enabled := true
+	request := &handlers.MaintenanceRequest{Enabled: &enabled, Message: "Migrating"}
+
+	client, err := newMaintenanceClient(config.ServerConfig{AdminListen: adminListen, AdminSecret: "admin_secret"})
+	require.NoError(t, err)
+	status, err := client.do(t.Context(), http.MethodPut, request)
+	require.NoError(t, err)
+	assert.Equal(t, true, status["enabled"])
+	assert.Equal(t, "Migrating", status["message"])
+
+	client, err = newMaintenanceClient(config.ServerConfig{AdminListen: adminListen, AdminSecret: "wrong"})
+	require.NoError(t, err)
+	_, err = client.do(t.Context(), http.MethodPut, request)
+	assert.ErrorContains(t, err, "Invalid or missing auth token")
+}
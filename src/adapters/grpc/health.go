@@ -0,0 +1,111 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/log"
+	Service "github.com/oswaldom-code/api-template-gin/src/application/system_services"
+)
+
+// healthServer implements the gRPC health checking protocol for the whole server,
+// the "" service, which serves while the database is reachable.
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+
+	healthService func() (Service.Health, error)
+	interval      time.Duration
+
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+const defaultHealthInterval = 5 * time.Second
+
+func newHealthServer(healthService func() (Service.Health, error), interval time.Duration) *healthServer {
+	if interval <= 0 {
+		interval = defaultHealthInterval
+	}
+	return &healthServer{healthService: healthService, interval: interval, done: make(chan struct{})}
+}
+
+// Check reports the current status; unknown services are NOT_FOUND.
+func (h *healthServer) Check(ctx context.Context, request *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	if request.Service != "" {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", request.Service)
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: h.status(ctx)}, nil
+}
+
+// Watch sends the current status, then every change, checking every interval. An
+// unknown service is reported SERVICE_UNKNOWN and the stream kept open, as the
+// protocol requires. The stream ends with NOT_SERVING when the server shuts down.
+func (h *healthServer) Watch(request *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	ctx := stream.Context()
+	if request.Service != "" {
+		if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN}); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-h.done:
+			return nil
+		}
+	}
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	last := grpc_health_v1.HealthCheckResponse_UNKNOWN
+	for {
+		current := h.status(ctx)
+		if current != last {
+			if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: current}); err != nil {
+				return err
+			}
+			last = current
+		}
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-h.done:
+			if last != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+				return stream.Send(&grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_NOT_SERVING})
+			}
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// status checks the database, within an interval so a Watch keeps its pace.
+func (h *healthServer) status(ctx context.Context) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	select {
+	case <-h.done:
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	default:
+	}
+	health, err := h.healthService()
+	if err != nil {
+		log.Warn("Health service unavailable", log.ContextFields(ctx, log.Fields{"error": err.Error()}))
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}
+	ctx, cancel := context.WithTimeout(ctx, h.interval)
+	defer cancel()
+	if err := health.TestDb(ctx); err != nil {
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}
+	return grpc_health_v1.HealthCheckResponse_SERVING
+}
+
+// shutdown reports NOT_SERVING from now on and ends the Watch streams.
+func (h *healthServer) shutdown() {
+	h.doneOnce.Do(func() {
+		close(h.done)
+	})
+}
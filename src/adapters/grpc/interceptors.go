@@ -0,0 +1,190 @@
+package grpc
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	metrics "github.com/penglongli/gin-metrics/ginmetrics"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/log"
+	"github.com/oswaldom-code/api-template-gin/pkg/requestid"
+)
+
+const (
+	// requestIDMetadata carries the request ID on calls and responses, as X-Request-ID does over HTTP.
+	requestIDMetadata = "x-request-id"
+
+	metricHandledTotal    = "grpc_server_handled_total"
+	metricHandlingSeconds = "grpc_server_handling_seconds"
+	metricPanicTotal      = "grpc_panic_total"
+)
+
+// RegisterMetrics adds the gRPC call metrics to the gin-metrics monitor, so they are
+// served on /metrics with the HTTP ones.
+func RegisterMetrics() {
+	monitor := metrics.GetMonitor()
+	// AddMetric fails when the metric already exists, which is fine on repeated setup
+	_ = monitor.AddMetric(&metrics.Metric{
+		Type:        metrics.Counter,
+		Name:        metricHandledTotal,
+		Description: "the completed gRPC calls counter.",
+		Labels:      []string{"method", "code"},
+	})
+	_ = monitor.AddMetric(&metrics.Metric{
+		Type:        metrics.Histogram,
+		Name:        metricHandlingSeconds,
+		Description: "the gRPC call durations in seconds.",
+		Labels:      []string{"method"},
+		Buckets:     []float64{0.1, 0.3, 1.2, 5, 10},
+	})
+	_ = monitor.AddMetric(&metrics.Metric{
+		Type:        metrics.Counter,
+		Name:        metricPanicTotal,
+		Description: "the gRPC recovered panics counter.",
+		Labels:      []string{"method"},
+	})
+}
+
+// contextStream is a server stream with the context the interceptors extended.
+type contextStream struct {
+	grpclib.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// withRequestID reuses a valid inbound x-request-id or generates a new one, stores it
+// in the context and sends it back in the response header.
+func withRequestID(ctx context.Context) context.Context {
+	var id string
+	if values := metadata.ValueFromIncomingContext(ctx, requestIDMetadata); len(values) > 0 {
+		id = values[0]
+	}
+	if !requestid.IsValid(id) {
+		id = requestid.New()
+	}
+	_ = grpclib.SetHeader(ctx, metadata.Pairs(requestIDMetadata, id))
+	return requestid.NewContext(ctx, id)
+}
+
+func requestIDUnaryInterceptor(ctx context.Context, request any, _ *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (any, error) {
+	return handler(withRequestID(ctx), request)
+}
+
+func requestIDStreamInterceptor(server any, stream grpclib.ServerStream, _ *grpclib.StreamServerInfo, handler grpclib.StreamHandler) error {
+	return handler(server, &contextStream{ServerStream: stream, ctx: withRequestID(stream.Context())})
+}
+
+// observe logs a completed call, like the access log, and records it in the metrics.
+func observe(ctx context.Context, method string, start time.Time, err error) {
+	code := status.Code(err).String()
+	latency := time.Since(start)
+	monitor := metrics.GetMonitor()
+	_ = monitor.GetMetric(metricHandledTotal).Inc([]string{method, code})
+	_ = monitor.GetMetric(metricHandlingSeconds).Observe([]string{method}, latency.Seconds())
+
+	fields := log.Fields{"method": method, "code": code, "latency": latency.String()}
+	if p, ok := peer.FromContext(ctx); ok {
+		fields["peer"] = p.Addr.String()
+	}
+	if err != nil {
+		fields["error"] = status.Convert(err).Message()
+	}
+	log.Info("gRPC call", log.ContextFields(ctx, fields))
+}
+
+func observeUnaryInterceptor(ctx context.Context, request any, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (any, error) {
+	start := time.Now()
+	response, err := handler(ctx, request)
+	observe(ctx, info.FullMethod, start, err)
+	return response, err
+}
+
+func observeStreamInterceptor(server any, stream grpclib.ServerStream, info *grpclib.StreamServerInfo, handler grpclib.StreamHandler) error {
+	start := time.Now()
+	err := handler(server, stream)
+	observe(stream.Context(), info.FullMethod, start, err)
+	return err
+}
+
+// recoverPanic turns a panic of the call into an Internal error carrying an incident
+// ID, logged with the stack, like the REST recovery middleware. It must be deferred.
+func recoverPanic(ctx context.Context, method string, err *error) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	incidentID := requestid.New()
+	_ = metrics.GetMonitor().GetMetric(metricPanicTotal).Inc([]string{method})
+	log.Error("Panic recovered", log.ContextFields(ctx, log.Fields{
+		"incident_id": incidentID,
+		"panic":       fmt.Sprint(recovered),
+		"method":      method,
+		"stack":       string(debug.Stack()),
+	}))
+	*err = status.Errorf(codes.Internal, "An unexpected error occurred (incident %s)", incidentID)
+}
+
+func recoveryUnaryInterceptor(ctx context.Context, request any, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (response any, err error) {
+	defer recoverPanic(ctx, info.FullMethod, &err)
+	return handler(ctx, request)
+}
+
+func recoveryStreamInterceptor(server any, stream grpclib.ServerStream, info *grpclib.StreamServerInfo, handler grpclib.StreamHandler) (err error) {
+	defer recoverPanic(stream.Context(), info.FullMethod, &err)
+	return handler(server, stream)
+}
+
+// authorization validates the authorization metadata against the Basic credentials
+// of the secret returned by secret, as the REST protected routes do. Methods of
+// publicServices skip it.
+type authorization struct {
+	secret func() string
+}
+
+func newAuthorization(secret func() string) *authorization {
+	return &authorization{secret: secret}
+}
+
+func (a *authorization) authorize(ctx context.Context, method string) error {
+	if publicServices[serviceName(method)] {
+		return nil
+	}
+	expected := "Basic " + base64.StdEncoding.EncodeToString([]byte(a.secret()))
+	values := metadata.ValueFromIncomingContext(ctx, "authorization")
+	if len(values) == 0 || values[0] != expected {
+		return status.Error(codes.Unauthenticated, "Invalid or missing auth token")
+	}
+	return nil
+}
+
+func (a *authorization) unary(ctx context.Context, request any, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (any, error) {
+	if err := a.authorize(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, request)
+}
+
+func (a *authorization) stream(server any, stream grpclib.ServerStream, info *grpclib.StreamServerInfo, handler grpclib.StreamHandler) error {
+	if err := a.authorize(stream.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(server, stream)
+}
+
+// serviceName returns the service of a full method name, /package.Service/Method.
+func serviceName(fullMethod string) string {
+	service, _, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	return service
+}
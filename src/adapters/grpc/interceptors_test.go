@@ -0,0 +1,31 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRecoveryInterceptor_PanicBecomesInternal(t *testing.T) {
+	RegisterMetrics()
+	info := &grpclib.UnaryServerInfo{FullMethod: "/orders.Orders/Get"}
+
+	response, err := recoveryUnaryInterceptor(context.Background(), nil, info, func(ctx context.Context, request any) (any, error) {
+		panic("boom")
+	})
+	assert.Nil(t, response)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "incident")
+	assert.NotContains(t, status.Convert(err).Message(), "boom")
+}
+
+func TestAuthorization_PublicServices(t *testing.T) {
+	auth := newAuthorization(func() string { return "s3cret" })
+
+	assert.NoError(t, auth.authorize(context.Background(), "/grpc.health.v1.Health/Check"))
+	assert.Equal(t, codes.Unauthenticated, status.Code(auth.authorize(context.Background(), "/orders.Orders/Get")))
+}
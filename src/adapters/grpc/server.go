@@ -0,0 +1,79 @@
+package grpc
+
+import (
+	"context"
+	"net"
+
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	Service "github.com/oswaldom-code/api-template-gin/src/application/system_services"
+)
+
+// publicServices are served without credentials, like the public REST routes, so
+// load balancers and orchestrators can probe the server.
+var publicServices = map[string]bool{
+	grpc_health_v1.Health_ServiceDesc.ServiceName: true,
+}
+
+// Server serves the application services over gRPC, next to the HTTP server.
+type Server struct {
+	server *grpclib.Server
+	health *healthServer
+}
+
+// NewServer returns a gRPC server with the health checking protocol, backed by the
+// health service, and the reflection service when enabled. Calls go through the
+// request ID, logging, metrics, recovery and authorization interceptors; further
+// services registered with Register require the auth.secret Basic credentials.
+// healthService is resolved on every check so the process can start before the
+// database is reachable.
+func NewServer(cfg config.GRPCConfig, healthService func() (Service.Health, error)) *Server {
+	RegisterMetrics()
+	auth := newAuthorization(func() string {
+		return config.GetAuthenticationKey().Secret
+	})
+	s := &Server{
+		server: grpclib.NewServer(
+			grpclib.ChainUnaryInterceptor(requestIDUnaryInterceptor, observeUnaryInterceptor, recoveryUnaryInterceptor, auth.unary),
+			grpclib.ChainStreamInterceptor(requestIDStreamInterceptor, observeStreamInterceptor, recoveryStreamInterceptor, auth.stream),
+		),
+		health: newHealthServer(healthService, cfg.HealthInterval),
+	}
+	grpc_health_v1.RegisterHealthServer(s.server, s.health)
+	if cfg.Reflection {
+		reflection.Register(s.server)
+	}
+	return s
+}
+
+// Register adds a service implementation, as generated RegisterXServer functions do.
+func (s *Server) Register(desc *grpclib.ServiceDesc, implementation any) {
+	s.server.RegisterService(desc, implementation)
+}
+
+// Serve accepts connections on ln until the server is shut down.
+func (s *Server) Serve(ln net.Listener) error {
+	return s.server.Serve(ln)
+}
+
+// Shutdown reports the server as not serving, ending the health Watch streams, then
+// stops accepting connections and waits for the running calls. They are cancelled
+// when ctx expires.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.health.shutdown()
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return ctx.Err()
+	}
+}
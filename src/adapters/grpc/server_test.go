@@ -0,0 +1,192 @@
+package grpc
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	Service "github.com/oswaldom-code/api-template-gin/src/application/system_services"
+)
+
+type fakeHealth struct {
+	err error
+}
+
+func (f fakeHealth) TestDb(ctx context.Context) error {
+	return f.err
+}
+
+func healthy() (Service.Health, error) {
+	return fakeHealth{}, nil
+}
+
+// startServer serves server on an in-memory listener and returns a client connection.
+func startServer(t *testing.T, server *Server) *grpclib.ClientConn {
+	t.Setenv("auth.secret", "s3cret")
+	ln := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(ln) }()
+	t.Cleanup(func() { _ = server.Shutdown(context.Background()) })
+
+	conn, err := grpclib.Dial("bufnet",
+		grpclib.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpclib.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func withCredentials(ctx context.Context, secret string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(secret)))
+}
+
+func TestHealth_Check(t *testing.T) {
+	cases := []struct {
+		name   string
+		health func() (Service.Health, error)
+		want   grpc_health_v1.HealthCheckResponse_ServingStatus
+	}{
+		{"database reachable", healthy, grpc_health_v1.HealthCheckResponse_SERVING},
+		{"database down", func() (Service.Health, error) {
+			return fakeHealth{err: errors.New("connection refused")}, nil
+		}, grpc_health_v1.HealthCheckResponse_NOT_SERVING},
+		{"health service unavailable", func() (Service.Health, error) {
+			return nil, errors.New("no database configuration")
+		}, grpc_health_v1.HealthCheckResponse_NOT_SERVING},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			conn := startServer(t, NewServer(config.GRPCConfig{}, tc.health))
+			// the health service is public, no credentials needed
+			response, err := grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, response.Status)
+		})
+	}
+}
+
+func TestHealth_CheckUnknownService(t *testing.T) {
+	conn := startServer(t, NewServer(config.GRPCConfig{}, healthy))
+
+	_, err := grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "orders"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestHealth_WatchReportsChangesAndShutdown(t *testing.T) {
+	var down bool
+	results := make(chan bool, 1)
+	results <- false
+	health := func() (Service.Health, error) {
+		select {
+		case down = <-results:
+		default:
+		}
+		if down {
+			return fakeHealth{err: errors.New("connection refused")}, nil
+		}
+		return fakeHealth{}, nil
+	}
+	server := NewServer(config.GRPCConfig{HealthInterval: 10 * time.Millisecond}, health)
+	conn := startServer(t, server)
+
+	stream, err := grpc_health_v1.NewHealthClient(conn).Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	require.NoError(t, err)
+	response, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, response.Status)
+
+	results <- true
+	response, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, response.Status)
+
+	results <- false
+	response, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, response.Status)
+
+	go func() { _ = server.Shutdown(context.Background()) }()
+	response, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, response.Status)
+}
+
+func TestHealth_WatchUnknownService(t *testing.T) {
+	conn := startServer(t, NewServer(config.GRPCConfig{}, healthy))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := grpc_health_v1.NewHealthClient(conn).Watch(ctx, &grpc_health_v1.HealthCheckRequest{Service: "orders"})
+	require.NoError(t, err)
+	response, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN, response.Status)
+}
+
+func TestReflection_RequiresCredentials(t *testing.T) {
+	conn := startServer(t, NewServer(config.GRPCConfig{Reflection: true}, healthy))
+	listServices := func(ctx context.Context) (*reflectionpb.ServerReflectionResponse, error) {
+		stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+		require.NoError(t, err)
+		err = stream.Send(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return stream.Recv()
+	}
+
+	_, err := listServices(context.Background())
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = listServices(withCredentials(context.Background(), "wrong"))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	response, err := listServices(withCredentials(context.Background(), "s3cret"))
+	require.NoError(t, err)
+	var services []string
+	for _, service := range response.GetListServicesResponse().Service {
+		services = append(services, service.Name)
+	}
+	assert.Contains(t, services, "grpc.health.v1.Health")
+}
+
+func TestReflection_Disabled(t *testing.T) {
+	conn := startServer(t, NewServer(config.GRPCConfig{}, healthy))
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(withCredentials(context.Background(), "s3cret"))
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestRequestID_EchoedInHeader(t *testing.T) {
+	conn := startServer(t, NewServer(config.GRPCConfig{}, healthy))
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(context.Background(), requestIDMetadata, "trace-123")
+	_, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{}, grpclib.Header(&header))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"trace-123"}, header.Get(requestIDMetadata))
+
+	_, err = client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}, grpclib.Header(&header))
+	require.NoError(t, err)
+	require.Len(t, header.Get(requestIDMetadata), 1)
+	assert.NotEmpty(t, header.Get(requestIDMetadata)[0])
+}
@@ -0,0 +1,65 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/graphql-go/graphql/gqlerrors"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/log"
+	"github.com/oswaldom-code/api-template-gin/pkg/requestid"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+)
+
+// requestErrors are errors preventing the execution of the whole request.
+func requestErrors(code dto.ErrorCode, errs ...gqlerrors.FormattedError) []gqlerrors.FormattedError {
+	for i := range errs {
+		errs[i].Extensions = map[string]any{"code": code}
+	}
+	return errs
+}
+
+func requestError(code dto.ErrorCode, message string) []gqlerrors.FormattedError {
+	return requestErrors(code, gqlerrors.NewFormattedError(message))
+}
+
+// executionErrors gives every error a code. Errors without a path come from the
+// request, its variables for instance. Field errors are reported like in the REST
+// handlers: a *dto.APIError with its code and message, other errors logged with an
+// incident ID and masked.
+func executionErrors(ctx context.Context, errs []gqlerrors.FormattedError) []gqlerrors.FormattedError {
+	for i, err := range errs {
+		if _, ok := err.Extensions["code"]; ok {
+			continue
+		}
+		if len(err.Path) == 0 {
+			errs[i].Extensions = map[string]any{"code": dto.ErrBadRequest}
+			continue
+		}
+		if apiErr := apiError(err); apiErr != nil {
+			errs[i].Message = apiErr.Message
+			errs[i].Extensions = map[string]any{"code": apiErr.Code}
+			continue
+		}
+		incidentID := requestid.New()
+		log.Error("GraphQL resolver failed", log.ContextFields(ctx, log.Fields{
+			"incident_id": incidentID,
+			"error":       err.Message,
+			"path":        fmt.Sprint(err.Path),
+		}))
+		errs[i].Message = "An unexpected error occurred"
+		errs[i].Extensions = map[string]any{"code": dto.ErrInternalServer, "incident_id": incidentID}
+	}
+	return errs
+}
+
+// apiError returns the *dto.APIError a resolver failed with, wrapped or not.
+func apiError(err gqlerrors.FormattedError) *dto.APIError {
+	var located *gqlerrors.Error
+	var apiErr *dto.APIError
+	if errors.As(err.OriginalError(), &located) && errors.As(located.OriginalError, &apiErr) {
+		return apiErr
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+	Service "github.com/oswaldom-code/api-template-gin/src/application/system_services"
+)
+
+// playgroundCSP lets the GraphiQL page load its scripts and styles from unpkg.
+const playgroundCSP = "default-src 'self'; script-src 'self' 'unsafe-inline' https://unpkg.com; " +
+	"style-src 'self' 'unsafe-inline' https://unpkg.com; img-src 'self' data:; connect-src 'self'"
+
+var playgroundPage = template.Must(template.New("playground").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>GraphQL Playground</title>
+  <link rel="stylesheet" href="https://unpkg.com/graphiql@3/graphiql.min.css">
+</head>
+<body style="margin: 0">
+  <div id="graphiql" style="height: 100vh"></div>
+  <script crossorigin src="https://unpkg.com/react@18/umd/react.production.min.js"></script>
+  <script crossorigin src="https://unpkg.com/react-dom@18/umd/react-dom.production.min.js"></script>
+  <script crossorigin src="https://unpkg.com/graphiql@3/graphiql.min.js"></script>
+  <script>
+    ReactDOM.createRoot(document.getElementById("graphiql")).render(React.createElement(GraphiQL, {
+      fetcher: GraphiQL.createFetcher({ url: {{.Endpoint}} }),
+      defaultHeaders: '{"Authorization": "Basic "}',
+      defaultEditorToolsVisibility: "headers",
+    }));
+  </script>
+</body>
+</html>
+`))
+
+// Request is a GraphQL request, sent as the body of a POST (JSON, YAML, MessagePack or
+// CBOR) or the query string of a GET.
+type Request struct {
+	Query         string         `json:"query" yaml:"query"`
+	OperationName string         `json:"operationName" yaml:"operationName"`
+	Variables     map[string]any `json:"variables" yaml:"variables"`
+}
+
+// Response is a GraphQL response. Data is absent when the request could not run.
+type Response struct {
+	Data   any                        `json:"data,omitempty"`
+	Errors []gqlerrors.FormattedError `json:"errors,omitempty"`
+}
+
+// Handler serves GraphQL requests resolved against the application services.
+type Handler struct {
+	schema   gql.Schema
+	resolver *resolver
+	limits   queryLimits
+	endpoint string
+}
+
+// NewHandler returns a Handler; healthService is resolved on every health query
+// so the process can start before the database is reachable.
+func NewHandler(cfg config.GraphQLConfig, healthService func() (Service.Health, error), maintenance Service.Maintenance) (*Handler, error) {
+	r := newResolver(healthService, maintenance)
+	schema, err := newSchema(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{
+		schema:   schema,
+		resolver: r,
+		limits:   queryLimits{maxDepth: cfg.MaxDepth, maxComplexity: cfg.MaxComplexity},
+		endpoint: cfg.Path,
+	}, nil
+}
+
+// Serve runs a query from a POST body or a GET query string; mutations are only run
+// on POST. Requests that cannot run (syntax, validation, limits) are a 400 with errors
+// carrying their dto.ErrorCode in extensions.code; executed ones are a 200 with the
+// data and the field errors.
+func (h *Handler) Serve(c *gin.Context) {
+	var request Request
+	if c.Request.Method == http.MethodGet {
+		request.Query = c.Query("query")
+		request.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				h.reject(c, http.StatusBadRequest, requestError(dto.ErrBadRequest, "variables must be a JSON object"))
+				return
+			}
+		}
+	} else if err := dto.Bind(c, &request); err != nil {
+		if errors.Is(err, dto.ErrUnsupportedContentType) {
+			h.reject(c, http.StatusUnsupportedMediaType, requestError(dto.ErrUnsupportedMediaType, err.Error()))
+			return
+		}
+		h.reject(c, http.StatusBadRequest, requestError(dto.ErrBadRequest, "Invalid GraphQL request: "+err.Error()))
+		return
+	}
+	if request.Query == "" {
+		h.reject(c, http.StatusBadRequest, requestError(dto.ErrBadRequest, "query is required"))
+		return
+	}
+
+	document, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{
+		Body: []byte(request.Query),
+		Name: "GraphQL request",
+	})})
+	if err != nil {
+		h.reject(c, http.StatusBadRequest, requestErrors(dto.ErrBadRequest, gqlerrors.FormatError(err)))
+		return
+	}
+	if validation := gql.ValidateDocument(&h.schema, document, nil); !validation.IsValid {
+		h.reject(c, http.StatusBadRequest, requestErrors(dto.ErrValidation, validation.Errors...))
+		return
+	}
+	operation := findOperation(document, request.OperationName)
+	if operation == nil {
+		h.reject(c, http.StatusBadRequest, requestError(dto.ErrBadRequest, "operationName does not name an operation of the query"))
+		return
+	}
+	if operation.Operation != ast.OperationTypeQuery && c.Request.Method == http.MethodGet {
+		c.Header("Allow", http.MethodPost)
+		h.reject(c, http.StatusMethodNotAllowed, requestError(dto.ErrBadRequest, "only queries can be sent with GET"))
+		return
+	}
+	if err := h.limits.check(document, operation); err != nil {
+		h.reject(c, http.StatusBadRequest, requestError(dto.ErrValidation, err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+	result := gql.Execute(gql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           document,
+		OperationName: request.OperationName,
+		Args:          request.Variables,
+		Context:       h.resolver.withLoaders(ctx),
+	})
+	response := Response{Data: result.Data, Errors: executionErrors(ctx, result.Errors)}
+	if result.Data == nil && !hasFieldErrors(response.Errors) {
+		// the variables were rejected, nothing ran
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Playground serves GraphiQL, querying the endpoint; credentials go in its headers editor.
+func (h *Handler) Playground(c *gin.Context) {
+	c.Header("Content-Security-Policy", playgroundCSP)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	_ = playgroundPage.Execute(c.Writer, struct{ Endpoint string }{h.endpoint})
+}
+
+func (h *Handler) reject(c *gin.Context, statusCode int, errs []gqlerrors.FormattedError) {
+	c.JSON(statusCode, Response{Errors: errs})
+}
+
+func hasFieldErrors(errs []gqlerrors.FormattedError) bool {
+	for _, err := range errs {
+		if len(err.Path) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// findOperation returns the operation named name, or the only one without a name.
+func findOperation(document *ast.Document, name string) *ast.OperationDefinition {
+	var found *ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if name == "" {
+			if found != nil {
+				return nil
+			}
+			found = operation
+			continue
+		}
+		if operation.Name != nil && operation.Name.Value == name {
+			return operation
+		}
+	}
+	return found
+}
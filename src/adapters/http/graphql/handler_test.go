@@ -0,0 +1,261 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	gql "github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+	Service "github.com/oswaldom-code/api-template-gin/src/application/system_services"
+)
+
+type fakeHealth struct {
+	calls *atomic.Int32
+	err   error
+}
+
+func (f fakeHealth) TestDb(ctx context.Context) error {
+	f.calls.Add(1)
+	return f.err
+}
+
+func testConfig() config.GraphQLConfig {
+	return config.GraphQLConfig{Enabled: true, Path: "/graphql", MaxDepth: 4, MaxComplexity: 10, Playground: true}
+}
+
+func setupRouter(t *testing.T, h *Handler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/graphql", h.Serve)
+	router.POST("/graphql", h.Serve)
+	router.GET("/graphql/playground", h.Playground)
+	return router
+}
+
+func newTestHandler(t *testing.T, health fakeHealth, maintenance Service.Maintenance) *Handler {
+	h, err := NewHandler(testConfig(), func() (Service.Health, error) { return health, nil }, maintenance)
+	require.NoError(t, err)
+	return h
+}
+
+// response is Response with the errors decoded as sent.
+type response struct {
+	Data   map[string]any `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Path       []any          `json:"path"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+func post(t *testing.T, router *gin.Engine, query string, variables map[string]any) (*httptest.ResponseRecorder, response) {
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var decoded response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &decoded))
+	return w, decoded
+}
+
+func TestServe_Query(t *testing.T) {
+	maintenance := Service.NewMaintenance()
+	maintenance.Enable("Upgrading", 10*time.Minute)
+	router := setupRouter(t, newTestHandler(t, fakeHealth{calls: &atomic.Int32{}}, maintenance))
+
+	w, resp := post(t, router, `{ ping maintenance { enabled message retryAfter since } }`, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, resp.Errors)
+	assert.Equal(t, "pong", resp.Data["ping"])
+	mode := resp.Data["maintenance"].(map[string]any)
+	assert.Equal(t, true, mode["enabled"])
+	assert.Equal(t, "Upgrading", mode["message"])
+	assert.Equal(t, float64(600), mode["retryAfter"])
+	assert.NotNil(t, mode["since"])
+}
+
+func TestServe_HealthChecksDependenciesOnce(t *testing.T) {
+	calls := &atomic.Int32{}
+	router := setupRouter(t, newTestHandler(t, fakeHealth{calls: calls}, Service.NewMaintenance()))
+
+	w, resp := post(t, router, `{
+		health { status dependencies { name ready } db: dependency(name: "database") { ready } }
+		again: health { status }
+	}`, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, resp.Errors)
+	health := resp.Data["health"].(map[string]any)
+	assert.Equal(t, "ready", health["status"])
+	assert.Equal(t, []any{map[string]any{"name": "database", "ready": true}}, health["dependencies"])
+	assert.Equal(t, map[string]any{"ready": true}, health["db"])
+	assert.Equal(t, int32(1), calls.Load(), "the loads are batched and cached for the request")
+
+	post(t, router, `{ health { status } }`, nil)
+	assert.Equal(t, int32(2), calls.Load(), "each request checks again")
+}
+
+func TestServe_HealthDatabaseDown(t *testing.T) {
+	health := fakeHealth{calls: &atomic.Int32{}, err: errors.New("connection refused")}
+	router := setupRouter(t, newTestHandler(t, health, Service.NewMaintenance()))
+
+	_, resp := post(t, router, `{ health { status dependencies { name ready message } unknown: dependency(name: "cache") { ready } } }`, nil)
+	assert.Empty(t, resp.Errors)
+	result := resp.Data["health"].(map[string]any)
+	assert.Equal(t, "unavailable", result["status"])
+	assert.Equal(t, []any{map[string]any{"name": "database", "ready": false, "message": "Database unavailable"}}, result["dependencies"])
+	assert.Nil(t, result["unknown"])
+}
+
+func TestServe_RequestErrors(t *testing.T) {
+	router := setupRouter(t, newTestHandler(t, fakeHealth{calls: &atomic.Int32{}}, Service.NewMaintenance()))
+
+	cases := []struct {
+		name      string
+		query     string
+		variables map[string]any
+		code      string
+		message   string
+	}{
+		{"syntax", `{ ping`, nil, "BAD_REQUEST", "Syntax Error"},
+		{"unknown field", `{ pong }`, nil, "VALIDATION_ERROR", `Cannot query field "pong"`},
+		{"complexity", `{ a: ping b: ping c: ping d: ping e: ping f: ping g: ping h: ping i: ping j: ping k: ping }`, nil,
+			"VALIDATION_ERROR", "query complexity 11 exceeds the maximum of 10"},
+		{"missing variable", `query($name: String!) { health { dependency(name: $name) { ready } } }`, nil,
+			"BAD_REQUEST", `Variable "$name"`},
+		{"missing query", ``, nil, "BAD_REQUEST", "query is required"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, resp := post(t, router, tc.query, tc.variables)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Nil(t, resp.Data)
+			require.NotEmpty(t, resp.Errors)
+			assert.Equal(t, tc.code, resp.Errors[0].Extensions["code"])
+			assert.Contains(t, resp.Errors[0].Message, tc.message)
+		})
+	}
+}
+
+func TestServe_DepthLimit(t *testing.T) {
+	h := newTestHandler(t, fakeHealth{calls: &atomic.Int32{}}, Service.NewMaintenance())
+	h.limits.maxDepth = 2
+	router := setupRouter(t, h)
+
+	w, _ := post(t, router, `{ health { status } }`, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w, resp := post(t, router, `{ health { ...h } } fragment h on Health { dependencies { name } }`, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "VALIDATION_ERROR", resp.Errors[0].Extensions["code"])
+	assert.Equal(t, "query depth 3 exceeds the maximum of 2", resp.Errors[0].Message)
+}
+
+func TestServe_Get(t *testing.T) {
+	router := setupRouter(t, newTestHandler(t, fakeHealth{calls: &atomic.Int32{}}, Service.NewMaintenance()))
+
+	query := url.Values{"query": {`query Ping { ping }`}, "operationName": {"Ping"}}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/graphql?"+query.Encode(), nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data":{"ping":"pong"}}`, w.Body.String())
+}
+
+func TestServe_MutationOverGetNotAllowed(t *testing.T) {
+	h := newTestHandler(t, fakeHealth{calls: &atomic.Int32{}}, Service.NewMaintenance())
+	schema, err := gql.NewSchema(gql.SchemaConfig{
+		Query: h.schema.QueryType(),
+		Mutation: gql.NewObject(gql.ObjectConfig{Name: "Mutation", Fields: gql.Fields{
+			"touch": &gql.Field{Type: gql.Boolean, Resolve: func(p gql.ResolveParams) (any, error) { return true, nil }},
+		}}),
+	})
+	require.NoError(t, err)
+	h.schema = schema
+	router := setupRouter(t, h)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(`mutation { touch }`), nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, http.MethodPost, w.Header().Get("Allow"))
+
+	w, resp := post(t, router, `mutation { touch }`, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, true, resp.Data["touch"])
+}
+
+func TestServe_ResolverErrors(t *testing.T) {
+	h := newTestHandler(t, fakeHealth{calls: &atomic.Int32{}}, Service.NewMaintenance())
+	schema, err := gql.NewSchema(gql.SchemaConfig{
+		Query: gql.NewObject(gql.ObjectConfig{Name: "Query", Fields: gql.Fields{
+			"order": &gql.Field{Type: gql.String, Resolve: func(p gql.ResolveParams) (any, error) {
+				return nil, dto.NewAPIError(dto.ErrNotFound, "order 42 not found")
+			}},
+			"invoice": &gql.Field{Type: gql.String, Resolve: func(p gql.ResolveParams) (any, error) {
+				return nil, fmt.Errorf("failed to load invoice 7: %w", &dto.APIError{
+					Code: dto.ErrConflict, Message: "invoice 7 changed", Err: errors.New("pq: could not serialize access"),
+				})
+			}},
+			"broken": &gql.Field{Type: gql.String, Resolve: func(p gql.ResolveParams) (any, error) {
+				return nil, errors.New("pq: password authentication failed")
+			}},
+		}}),
+	})
+	require.NoError(t, err)
+	h.schema = schema
+	router := setupRouter(t, h)
+
+	w, resp := post(t, router, `{ order invoice broken }`, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, resp.Errors, 3)
+	byPath := map[any]map[string]any{}
+	messages := map[any]string{}
+	for _, e := range resp.Errors {
+		byPath[e.Path[0]] = e.Extensions
+		messages[e.Path[0]] = e.Message
+	}
+	assert.Equal(t, "NOT_FOUND", byPath["order"]["code"])
+	assert.Equal(t, "order 42 not found", messages["order"])
+	assert.Equal(t, "CONFLICT", byPath["invoice"]["code"])
+	assert.Equal(t, "invoice 7 changed", messages["invoice"], "the cause is not reported")
+	assert.Equal(t, "INTERNAL_ERROR", byPath["broken"]["code"])
+	assert.NotEmpty(t, byPath["broken"]["incident_id"])
+	assert.Equal(t, "An unexpected error occurred", messages["broken"])
+}
+
+func TestServe_UnsupportedContentType(t *testing.T) {
+	router := setupRouter(t, newTestHandler(t, fakeHealth{calls: &atomic.Int32{}}, Service.NewMaintenance()))
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{ ping }`))
+	req.Header.Set("Content-Type", "text/plain")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"UNSUPPORTED_MEDIA_TYPE"`)
+}
+
+func TestPlayground(t *testing.T) {
+	router := setupRouter(t, newTestHandler(t, fakeHealth{calls: &atomic.Int32{}}, Service.NewMaintenance()))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/graphql/playground", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Header().Get("Content-Security-Policy"), "https://unpkg.com")
+	assert.Contains(t, w.Body.String(), `url: "/graphql"`)
+}
@@ -0,0 +1,103 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// maxIntrospectionDepth bounds the paths through introspection fields, which the
+// introspection query of GraphiQL and other clients nests 13 levels deep.
+const maxIntrospectionDepth = 15
+
+// cost is the depth and complexity of a selection set. Paths through introspection
+// fields (__schema, __type, ...) are measured apart, in introspectionDepth.
+type cost struct {
+	depth              int
+	introspectionDepth int
+	complexity         int
+}
+
+// queryLimits rejects queries nesting fields deeper than maxDepth or selecting more
+// than maxComplexity fields, fragments expanded. Introspection fields count toward the
+// complexity; their paths may nest up to maxIntrospectionDepth, or maxDepth when it is
+// higher. A 0 limit is disabled.
+type queryLimits struct {
+	maxDepth      int
+	maxComplexity int
+}
+
+// check measures operation; the document must be valid, without fragment cycles.
+func (l queryLimits) check(document *ast.Document, operation *ast.OperationDefinition) error {
+	if l.maxDepth <= 0 && l.maxComplexity <= 0 {
+		return nil
+	}
+	m := &measure{fragments: map[string]*ast.FragmentDefinition{}, costs: map[string]cost{}}
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			m.fragments[fragment.Name.Value] = fragment
+		}
+	}
+	c := m.selectionSet(operation.SelectionSet)
+	if l.maxDepth > 0 && c.depth > l.maxDepth {
+		return fmt.Errorf("query depth %d exceeds the maximum of %d", c.depth, l.maxDepth)
+	}
+	if limit := max(l.maxDepth, maxIntrospectionDepth); l.maxDepth > 0 && c.introspectionDepth > limit {
+		return fmt.Errorf("introspection depth %d exceeds the maximum of %d", c.introspectionDepth, limit)
+	}
+	if l.maxComplexity > 0 && c.complexity > l.maxComplexity {
+		return fmt.Errorf("query complexity %d exceeds the maximum of %d", c.complexity, l.maxComplexity)
+	}
+	return nil
+}
+
+type measure struct {
+	fragments map[string]*ast.FragmentDefinition
+	// costs memoizes fragments, so spreading one many times is measured once
+	costs map[string]cost
+}
+
+func (m *measure) selectionSet(set *ast.SelectionSet) cost {
+	var total cost
+	if set == nil {
+		return total
+	}
+	for _, selection := range set.Selections {
+		var c cost
+		switch selection := selection.(type) {
+		case *ast.Field:
+			c = m.selectionSet(selection.SelectionSet)
+			if strings.HasPrefix(selection.Name.Value, "__") {
+				c.introspectionDepth = max(c.depth, c.introspectionDepth) + 1
+				c.depth = 0
+			} else {
+				c.depth++
+				if c.introspectionDepth > 0 {
+					c.introspectionDepth++
+				}
+			}
+			c.complexity++
+		case *ast.InlineFragment:
+			c = m.selectionSet(selection.SelectionSet)
+		case *ast.FragmentSpread:
+			c = m.fragment(selection.Name.Value)
+		}
+		total.depth = max(total.depth, c.depth)
+		total.introspectionDepth = max(total.introspectionDepth, c.introspectionDepth)
+		total.complexity += c.complexity
+	}
+	return total
+}
+
+func (m *measure) fragment(name string) cost {
+	if c, ok := m.costs[name]; ok {
+		return c
+	}
+	var c cost
+	if fragment, ok := m.fragments[name]; ok {
+		c = m.selectionSet(fragment.SelectionSet)
+	}
+	m.costs[name] = c
+	return c
+}
@@ -0,0 +1,95 @@
+package graphql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func measureQuery(t *testing.T, query string) cost {
+	document, err := parser.Parse(parser.ParseParams{Source: query})
+	require.NoError(t, err)
+	m := &measure{fragments: map[string]*ast.FragmentDefinition{}, costs: map[string]cost{}}
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			m.fragments[fragment.Name.Value] = fragment
+		}
+	}
+	return m.selectionSet(findOperation(document, "").SelectionSet)
+}
+
+func TestMeasure(t *testing.T) {
+	cases := []struct {
+		name  string
+		query string
+		want  cost
+	}{
+		{"flat", `{ ping maintenance { enabled message } }`, cost{depth: 2, complexity: 4}},
+		{"nested", `{ health { dependencies { name ready } } }`, cost{depth: 3, complexity: 4}},
+		{"inline fragment", `{ health { ... on Health { status } } }`, cost{depth: 2, complexity: 2}},
+		{"fragments expanded", `{ a: health { ...h } b: health { ...h } } fragment h on Health { dependencies { ...d } } fragment d on Dependency { name ready }`,
+			cost{depth: 3, complexity: 8}},
+		{"introspection measured apart", `{ __schema { types { name fields { name type { name ofType { name } } } } } ping }`,
+			cost{depth: 1, introspectionDepth: 6, complexity: 10}},
+		{"typename under fields", `{ health { __typename status } }`, cost{depth: 2, introspectionDepth: 2, complexity: 3}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, measureQuery(t, tc.query))
+		})
+	}
+}
+
+func TestQueryLimits_Disabled(t *testing.T) {
+	document, err := parser.Parse(parser.ParseParams{Source: `{ health { dependencies { name } } }`})
+	require.NoError(t, err)
+	operation := findOperation(document, "")
+
+	assert.NoError(t, queryLimits{}.check(document, operation))
+	assert.Error(t, queryLimits{maxDepth: 2}.check(document, operation))
+	assert.Error(t, queryLimits{maxComplexity: 2}.check(document, operation))
+}
+
+func TestQueryLimits_Introspection(t *testing.T) {
+	limits := queryLimits{maxDepth: 10, maxComplexity: 200}
+	check := func(query string) error {
+		document, err := parser.Parse(parser.ParseParams{Source: query})
+		require.NoError(t, err)
+		return limits.check(document, findOperation(document, ""))
+	}
+
+	assert.NoError(t, check(introspectionQuery), "the introspection query of GraphiQL")
+
+	nested := "{ __schema { types { " + strings.Repeat("fields { type { ", 6) + "fields { name }" + strings.Repeat(" }", 15)
+	assert.ErrorContains(t, check(nested), "introspection depth 16 exceeds the maximum of 15")
+
+	wide := "{ __schema { types { " + strings.Repeat("name ", 200) + "} } }"
+	assert.ErrorContains(t, check(wide), "query complexity")
+}
+
+const introspectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+    directives { name description locations args { ...InputValue } }
+  }
+}
+fragment FullType on __Type {
+  kind name description
+  fields(includeDeprecated: true) { name description args { ...InputValue } type { ...TypeRef } isDeprecated deprecationReason }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) { name description isDeprecated deprecationReason }
+  possibleTypes { ...TypeRef }
+}
+fragment InputValue on __InputValue { name description type { ...TypeRef } defaultValue }
+fragment TypeRef on __Type {
+  kind name
+  ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } } } } }
+}`
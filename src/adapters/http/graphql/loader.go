@@ -0,0 +1,66 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+)
+
+type loaderResult[V any] struct {
+	value V
+	err   error
+}
+
+// loader batches the loads of the resolvers of one request. Resolvers return the
+// thunk of load; the executor resolves the fields of a level before running their
+// thunks, so the keys they asked for are fetched in a single call. Results are kept
+// for the request, each key is fetched once.
+type loader[K comparable, V any] struct {
+	ctx   context.Context
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending []K
+	results map[K]*loaderResult[V]
+}
+
+func newLoader[K comparable, V any](ctx context.Context, fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{ctx: ctx, fetch: fetch, results: map[K]*loaderResult[V]{}}
+}
+
+// load queues key and returns a thunk waiting for its value. Keys missing from the
+// fetch result have the zero value.
+func (l *loader[K, V]) load(key K) func() (V, error) {
+	l.mu.Lock()
+	result, ok := l.results[key]
+	if !ok {
+		result = &loaderResult[V]{}
+		l.results[key] = result
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.dispatch()
+		return result.value, result.err
+	}
+}
+
+// dispatch fetches the pending keys.
+func (l *loader[K, V]) dispatch() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.pending) == 0 {
+		return
+	}
+	keys := l.pending
+	l.pending = nil
+	values, err := l.fetch(l.ctx, keys)
+	for _, key := range keys {
+		result := l.results[key]
+		if err != nil {
+			result.err = err
+			continue
+		}
+		result.value = values[key]
+	}
+}
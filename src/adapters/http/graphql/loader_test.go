@@ -0,0 +1,55 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoader_BatchesPendingKeys(t *testing.T) {
+	var batches [][]string
+	l := newLoader(context.Background(), func(ctx context.Context, keys []string) (map[string]int, error) {
+		batches = append(batches, keys)
+		values := map[string]int{}
+		for _, key := range keys {
+			if key != "missing" {
+				values[key] = len(key)
+			}
+		}
+		return values, nil
+	})
+
+	a, b, again, missing := l.load("a"), l.load("bb"), l.load("a"), l.load("missing")
+	value, err := b()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, value)
+	value, _ = a()
+	assert.Equal(t, 1, value)
+	value, _ = again()
+	assert.Equal(t, 1, value)
+	value, err = missing()
+	assert.NoError(t, err)
+	assert.Zero(t, value)
+	assert.Equal(t, [][]string{{"a", "bb", "missing"}}, batches)
+
+	// later loads of known keys are served from the request cache
+	value, _ = l.load("bb")()
+	assert.Equal(t, 2, value)
+	value, _ = l.load("ccc")()
+	assert.Equal(t, 3, value)
+	assert.Equal(t, [][]string{{"a", "bb", "missing"}, {"ccc"}}, batches)
+}
+
+func TestLoader_FetchError(t *testing.T) {
+	l := newLoader(context.Background(), func(ctx context.Context, keys []string) (map[string]int, error) {
+		return nil, errors.New("unavailable")
+	})
+
+	a, b := l.load("a"), l.load("b")
+	_, err := a()
+	assert.EqualError(t, err, "unavailable")
+	_, err = b()
+	assert.EqualError(t, err, "unavailable")
+}
@@ -0,0 +1,244 @@
+package graphql
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	gql "github.com/graphql-go/graphql"
+
+	Service "github.com/oswaldom-code/api-template-gin/src/application/system_services"
+	"github.com/oswaldom-code/api-template-gin/src/domain/entities"
+)
+
+// dependency is the status of a service the API relies on.
+type dependency struct {
+	Name    string
+	Ready   bool
+	Message string
+}
+
+// resolver resolves the schema fields against the application services.
+type resolver struct {
+	healthService func() (Service.Health, error)
+	maintenance   Service.Maintenance
+	// checks are the dependencies reported by the health field, by name
+	checks map[string]func(ctx context.Context) dependency
+}
+
+func newResolver(healthService func() (Service.Health, error), maintenance Service.Maintenance) *resolver {
+	r := &resolver{healthService: healthService, maintenance: maintenance}
+	r.checks = map[string]func(ctx context.Context) dependency{
+		"database": r.checkDatabase,
+	}
+	return r
+}
+
+func (r *resolver) checkDatabase(ctx context.Context) dependency {
+	health, err := r.healthService()
+	if err != nil {
+		return dependency{Name: "database", Message: "Health service unavailable"}
+	}
+	if err := health.TestDb(ctx); err != nil {
+		return dependency{Name: "database", Message: "Database unavailable"}
+	}
+	return dependency{Name: "database", Ready: true}
+}
+
+// fetchDependencies is the batch function of the dependencies loader.
+func (r *resolver) fetchDependencies(ctx context.Context, names []string) (map[string]*dependency, error) {
+	dependencies := make(map[string]*dependency, len(names))
+	for _, name := range names {
+		if check, ok := r.checks[name]; ok {
+			d := check(ctx)
+			dependencies[name] = &d
+		}
+	}
+	return dependencies, nil
+}
+
+func (r *resolver) dependencyNames() []string {
+	names := make([]string, 0, len(r.checks))
+	for name := range r.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// loadDependencies returns a thunk for the named dependencies, skipping unknown ones.
+func (r *resolver) loadDependencies(ctx context.Context, names []string) func() ([]*dependency, error) {
+	loader := loadersFrom(ctx).dependencies
+	thunks := make([]func() (*dependency, error), len(names))
+	for i, name := range names {
+		thunks[i] = loader.load(name)
+	}
+	return func() ([]*dependency, error) {
+		dependencies := make([]*dependency, 0, len(thunks))
+		for _, thunk := range thunks {
+			d, err := thunk()
+			if err != nil {
+				return nil, err
+			}
+			if d != nil {
+				dependencies = append(dependencies, d)
+			}
+		}
+		return dependencies, nil
+	}
+}
+
+// loaders are the batch loaders of a request.
+type loaders struct {
+	dependencies *loader[string, *dependency]
+}
+
+type loadersKey struct{}
+
+func (r *resolver) withLoaders(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loadersKey{}, &loaders{
+		dependencies: newLoader(ctx, r.fetchDependencies),
+	})
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// newSchema builds the GraphQL schema:
+//
+//	type Query {
+//	  ping: String!
+//	  health: Health!
+//	  maintenance: Maintenance!
+//	}
+//	type Health {
+//	  status: String!               # "ready" or "unavailable"
+//	  dependencies: [Dependency!]!
+//	  dependency(name: String!): Dependency
+//	}
+//	type Dependency { name: String!, ready: Boolean!, message: String }
+//	type Maintenance { enabled: Boolean!, message: String!, retryAfter: Int!, since: String }
+func newSchema(r *resolver) (gql.Schema, error) {
+	dependencyType := gql.NewObject(gql.ObjectConfig{
+		Name:        "Dependency",
+		Description: "A service the API relies on.",
+		Fields: gql.Fields{
+			"name":  &gql.Field{Type: gql.NewNonNull(gql.String)},
+			"ready": &gql.Field{Type: gql.NewNonNull(gql.Boolean)},
+			"message": &gql.Field{
+				Type:        gql.String,
+				Description: "Why the dependency is not ready.",
+				Resolve: func(p gql.ResolveParams) (any, error) {
+					if message := p.Source.(*dependency).Message; message != "" {
+						return message, nil
+					}
+					return nil, nil
+				},
+			},
+		},
+	})
+	healthType := gql.NewObject(gql.ObjectConfig{
+		Name:        "Health",
+		Description: "Whether the API can serve traffic; its dependencies are checked once per request.",
+		Fields: gql.Fields{
+			"status": &gql.Field{
+				Type:        gql.NewNonNull(gql.String),
+				Description: "ready when every dependency is, unavailable otherwise.",
+				Resolve: func(p gql.ResolveParams) (any, error) {
+					thunk := r.loadDependencies(p.Context, r.dependencyNames())
+					return func() (any, error) {
+						dependencies, err := thunk()
+						if err != nil {
+							return nil, err
+						}
+						for _, d := range dependencies {
+							if !d.Ready {
+								return "unavailable", nil
+							}
+						}
+						return "ready", nil
+					}, nil
+				},
+			},
+			"dependencies": &gql.Field{
+				Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(dependencyType))),
+				Resolve: func(p gql.ResolveParams) (any, error) {
+					thunk := r.loadDependencies(p.Context, r.dependencyNames())
+					return func() (any, error) {
+						return thunk()
+					}, nil
+				},
+			},
+			"dependency": &gql.Field{
+				Type: dependencyType,
+				Args: gql.FieldConfigArgument{
+					"name": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)},
+				},
+				Resolve: func(p gql.ResolveParams) (any, error) {
+					thunk := loadersFrom(p.Context).dependencies.load(p.Args["name"].(string))
+					return func() (any, error) {
+						d, err := thunk()
+						if err != nil || d == nil {
+							// a nil *dependency would not read as null
+							return nil, err
+						}
+						return d, nil
+					}, nil
+				},
+			},
+		},
+	})
+	maintenanceType := gql.NewObject(gql.ObjectConfig{
+		Name:        "Maintenance",
+		Description: "Whether the API is in maintenance mode.",
+		Fields: gql.Fields{
+			"enabled": &gql.Field{Type: gql.NewNonNull(gql.Boolean)},
+			"message": &gql.Field{Type: gql.NewNonNull(gql.String)},
+			"retryAfter": &gql.Field{
+				Type:        gql.NewNonNull(gql.Int),
+				Description: "Seconds clients should wait before retrying.",
+				Resolve: func(p gql.ResolveParams) (any, error) {
+					return int(p.Source.(entities.MaintenanceMode).RetryAfter.Seconds()), nil
+				},
+			},
+			"since": &gql.Field{
+				Type:        gql.String,
+				Description: "When maintenance started (RFC 3339), null when disabled.",
+				Resolve: func(p gql.ResolveParams) (any, error) {
+					mode := p.Source.(entities.MaintenanceMode)
+					if !mode.Enabled {
+						return nil, nil
+					}
+					return mode.Since.UTC().Format(time.RFC3339), nil
+				},
+			},
+		},
+	})
+
+	return gql.NewSchema(gql.SchemaConfig{
+		Query: gql.NewObject(gql.ObjectConfig{
+			Name: "Query",
+			Fields: gql.Fields{
+				"ping": &gql.Field{
+					Type: gql.NewNonNull(gql.String),
+					Resolve: func(p gql.ResolveParams) (any, error) {
+						return "pong", nil
+					},
+				},
+				"health": &gql.Field{
+					Type: gql.NewNonNull(healthType),
+					Resolve: func(p gql.ResolveParams) (any, error) {
+						return struct{}{}, nil
+					},
+				},
+				"maintenance": &gql.Field{
+					Type: gql.NewNonNull(maintenanceType),
+					Resolve: func(p gql.ResolveParams) (any, error) {
+						return r.maintenance.Status(), nil
+					},
+				},
+			},
+		}),
+	})
+}
@@ -0,0 +1,52 @@
+package dto
+
+import "net/http"
+
+// statusCodes are the HTTP statuses of the error codes.
+var statusCodes = map[ErrorCode]int{
+	ErrUnauthorized:         http.StatusUnauthorized,
+	ErrForbidden:            http.StatusForbidden,
+	ErrNotFound:             http.StatusNotFound,
+	ErrBadRequest:           http.StatusBadRequest,
+	ErrValidation:           http.StatusUnprocessableEntity,
+	ErrConflict:             http.StatusConflict,
+	ErrInternalServer:       http.StatusInternalServerError,
+	ErrServiceUnavail:       http.StatusServiceUnavailable,
+	ErrRateLimited:          http.StatusTooManyRequests,
+	ErrTimeout:              http.StatusGatewayTimeout,
+	ErrNotAcceptable:        http.StatusNotAcceptable,
+	ErrUnsupportedMediaType: http.StatusUnsupportedMediaType,
+}
+
+// StatusCode returns the HTTP status of code, 500 for unknown codes.
+func StatusCode(code ErrorCode) int {
+	if status, ok := statusCodes[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// APIError is an error reported to clients with its code and message, returned by
+// handlers that do not write the response themselves. Err, when set, is the cause
+// kept for logs and errors.Is.
+type APIError struct {
+	Code    ErrorCode
+	Message string
+	Err     error
+}
+
+// NewAPIError returns an APIError with code.
+func NewAPIError(code ErrorCode, message string) *APIError {
+	return &APIError{Code: code, Message: message}
+}
+
+func (e *APIError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
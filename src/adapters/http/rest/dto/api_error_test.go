@@ -0,0 +1,24 @@
+package dto
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusCode(t *testing.T) {
+	assert.Equal(t, http.StatusNotFound, StatusCode(ErrNotFound))
+	assert.Equal(t, http.StatusUnprocessableEntity, StatusCode(ErrValidation))
+	assert.Equal(t, http.StatusInternalServerError, StatusCode("UNKNOWN"))
+}
+
+func TestAPIError_WrapsItsCause(t *testing.T) {
+	cause := errors.New("record not found")
+	err := &APIError{Code: ErrNotFound, Message: "order 42 not found", Err: cause}
+
+	assert.ErrorIs(t, err, cause)
+	assert.EqualError(t, err, "order 42 not found: record not found")
+	assert.EqualError(t, NewAPIError(ErrConflict, "order 42 already exists"), "order 42 already exists")
+}
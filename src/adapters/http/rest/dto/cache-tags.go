@@ -0,0 +1,19 @@
+package dto
+
+import "github.com/gin-gonic/gin"
+
+// CacheTagsKey stores the response cache tags of a request in the gin context.
+const CacheTagsKey = "cache_tags"
+
+// TagResponse tags the response for the response cache, e.g. "orders" or "order:42",
+// so services can invalidate every cached response built from that data.
+func TagResponse(c *gin.Context, tags ...string) {
+	c.Set(CacheTagsKey, append(ResponseTags(c), tags...))
+}
+
+// ResponseTags returns the cache tags set with TagResponse.
+func ResponseTags(c *gin.Context) []string {
+	tags, _ := c.Get(CacheTagsKey)
+	tagList, _ := tags.([]string)
+	return tagList
+}
@@ -0,0 +1,18 @@
+package dto
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagResponse_Accumulates(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	assert.Empty(t, ResponseTags(c))
+
+	TagResponse(c, "orders")
+	TagResponse(c, "order:1", "order:2")
+	assert.Equal(t, []string{"orders", "order:1", "order:2"}, ResponseTags(c))
+}
@@ -0,0 +1,333 @@
+package dto
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/render"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	MIMEJSON    = "application/json"
+	MIMEXML     = "application/xml"
+	MIMEYAML    = "application/yaml"
+	MIMEMsgPack = "application/msgpack"
+	MIMECBOR    = "application/cbor"
+)
+
+// ErrUnsupportedContentType is returned by Bind for request bodies in a format
+// the API does not read. Handlers answer it with 415 ErrUnsupportedMediaType.
+var ErrUnsupportedContentType = errors.New("unsupported content type")
+
+// format is a body encoding the API reads and writes.
+type format struct {
+	mediaType string
+	// aliases are other media types of the format, suffix its structured syntax
+	// suffix (RFC 6839), e.g. application/problem+json.
+	aliases   []string
+	suffix    string
+	newRender func(obj any) render.Render
+	binding   binding.BindingBody
+	// tag names the fields of bound structs in validation errors.
+	tag string
+}
+
+// formats are in order of preference: JSON wins ties and answers "Accept: */*".
+var formats = []*format{
+	{
+		mediaType: MIMEJSON,
+		suffix:    "+json",
+		newRender: func(obj any) render.Render { return render.JSON{Data: obj} },
+		binding:   binding.JSON,
+		tag:       "json",
+	},
+	{
+		mediaType: MIMEXML,
+		aliases:   []string{"text/xml"},
+		suffix:    "+xml",
+		newRender: func(obj any) render.Render { return render.XML{Data: obj} },
+		binding:   binding.XML,
+		tag:       "xml",
+	},
+	{
+		mediaType: MIMEYAML,
+		aliases:   []string{"application/x-yaml", "text/yaml", "text/x-yaml"},
+		suffix:    "+yaml",
+		newRender: func(obj any) render.Render { return yamlRender{Data: obj} },
+		binding:   binding.YAML,
+		tag:       "yaml",
+	},
+	{
+		mediaType: MIMEMsgPack,
+		aliases:   []string{"application/x-msgpack", "application/vnd.msgpack"},
+		newRender: func(obj any) render.Render { return render.MsgPack{Data: obj} },
+		binding:   binding.MsgPack,
+		tag:       "json",
+	},
+	{
+		mediaType: MIMECBOR,
+		suffix:    "+cbor",
+		newRender: func(obj any) render.Render { return cborRender{Data: obj} },
+		binding:   cborBinding{},
+		tag:       "json",
+	},
+}
+
+// MediaTypes returns the media types the API writes, in order of preference.
+func MediaTypes() []string {
+	mediaTypes := make([]string, 0, len(formats))
+	for _, f := range formats {
+		mediaTypes = append(mediaTypes, f.mediaType)
+	}
+	return mediaTypes
+}
+
+// Acceptable reports whether the request accepts one of the API formats.
+func Acceptable(c *gin.Context) bool {
+	_, ok := negotiate(c)
+	return ok
+}
+
+// Bind decodes the request body into obj according to its Content-Type and
+// validates it, returning a *ValidationError when validation fails. Requests
+// without a Content-Type are read as JSON.
+func Bind(c *gin.Context, obj any) error {
+	f := bodyFormat(c)
+	if f == nil {
+		return ErrUnsupportedContentType
+	}
+	return validationError(obj, c.ShouldBindWith(obj, f.binding), f.tag)
+}
+
+// bodyFormat returns the format of the request body, JSON without a Content-Type,
+// or nil when the API does not read it.
+func bodyFormat(c *gin.Context) *format {
+	contentType := c.ContentType()
+	if contentType == "" {
+		return formats[0]
+	}
+	for _, f := range formats {
+		if f.specificity(contentType) == exactMatch {
+			return f
+		}
+	}
+	return nil
+}
+
+func (f *format) write(c *gin.Context, statusCode int, obj any) {
+	addVaryAccept(c.Writer.Header())
+	c.Render(statusCode, f.newRender(obj))
+}
+
+func renderError(c *gin.Context, statusCode int, response ErrorResponse) {
+	f, ok := negotiate(c)
+	if !ok {
+		f = formats[0]
+	}
+	f.write(c, statusCode, response)
+}
+
+func notAcceptable(c *gin.Context) {
+	renderError(c, http.StatusNotAcceptable, NewErrorResponse(c, ErrNotAcceptable,
+		"Supported media types: "+strings.Join(MediaTypes(), ", ")))
+}
+
+const (
+	noMatch = iota
+	anyMatch
+	typeMatch
+	exactMatch
+)
+
+// negotiate picks the format with the highest q-value in Accept, the most specific
+// media range deciding each format's q-value. Without Accept it is JSON.
+func negotiate(c *gin.Context) (*format, bool) {
+	if c.Request == nil {
+		return formats[0], true
+	}
+	accept := c.Request.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return formats[0], true
+	}
+
+	var best *format
+	bestWeight := 0.0
+	for _, f := range formats {
+		weight, specificity := 0.0, noMatch
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err != nil {
+				continue
+			}
+			if s := f.specificity(mediaType); s > specificity {
+				specificity = s
+				weight = 1.0
+				if q, err := strconv.ParseFloat(params["q"], 64); err == nil {
+					weight = q
+				}
+			}
+		}
+		if weight > bestWeight {
+			best, bestWeight = f, weight
+		}
+	}
+	return best, best != nil
+}
+
+// specificity tells how closely mediaType, a media range or Content-Type, matches f.
+func (f *format) specificity(mediaType string) int {
+	mediaType = strings.ToLower(mediaType)
+	switch {
+	case mediaType == "*/*":
+		return anyMatch
+	case mediaType == f.mediaType || (f.suffix != "" && strings.HasSuffix(mediaType, f.suffix)):
+		return exactMatch
+	}
+	for _, alias := range f.aliases {
+		if mediaType == alias {
+			return exactMatch
+		}
+	}
+	if prefix, found := strings.CutSuffix(mediaType, "/*"); found && strings.HasPrefix(f.mediaType, prefix+"/") {
+		return typeMatch
+	}
+	return noMatch
+}
+
+func addVaryAccept(header http.Header) {
+	for _, value := range header.Values("Vary") {
+		for _, field := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), "Accept") {
+				return
+			}
+		}
+	}
+	header.Add("Vary", "Accept")
+}
+
+// MarshalXML names the root element "response", with map data written as elements.
+func (r SuccessResponse) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type envelope SuccessResponse
+	r.Data = toXMLValue(r.Data)
+	start.Name.Local = "response"
+	return e.EncodeElement(envelope(r), start)
+}
+
+// xmlMap writes a map as one child element per key, in key order.
+type xmlMap map[string]any
+
+func (m xmlMap) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := e.EncodeElement(toXMLValue(m[key]), xml.StartElement{Name: xml.Name{Local: key}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// toXMLValue converts maps, which encoding/xml cannot write, to xmlMap.
+func toXMLValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return xmlMap(v)
+	case gin.H:
+		return xmlMap(v)
+	case map[string]string:
+		m := make(xmlMap, len(v))
+		for key, item := range v {
+			m[key] = item
+		}
+		return m
+	}
+	return value
+}
+
+// MarshalXML names the root element "response".
+func (r ErrorResponse) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type envelope ErrorResponse
+	start.Name.Local = "response"
+	return e.EncodeElement(envelope(r), start)
+}
+
+// yamlRender writes application/yaml; gin's YAML render uses the legacy x-yaml type.
+type yamlRender struct {
+	Data any
+}
+
+func (r yamlRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	body, err := yaml.Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(body)
+	return err
+}
+
+func (r yamlRender) WriteContentType(w http.ResponseWriter) {
+	w.Header()["Content-Type"] = []string{MIMEYAML + "; charset=utf-8"}
+}
+
+type cborRender struct {
+	Data any
+}
+
+func (r cborRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	body, err := cbor.Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(body)
+	return err
+}
+
+func (r cborRender) WriteContentType(w http.ResponseWriter) {
+	w.Header()["Content-Type"] = []string{MIMECBOR}
+}
+
+// cborBinding decodes CBOR request bodies, which gin has no binding for.
+type cborBinding struct{}
+
+func (cborBinding) Name() string {
+	return "cbor"
+}
+
+func (b cborBinding) Bind(req *http.Request, obj any) error {
+	if req == nil || req.Body == nil {
+		return errors.New("invalid request")
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	return b.BindBody(body, obj)
+}
+
+func (cborBinding) BindBody(body []byte, obj any) error {
+	if err := cbor.Unmarshal(body, obj); err != nil {
+		return err
+	}
+	if binding.Validator == nil {
+		return nil
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
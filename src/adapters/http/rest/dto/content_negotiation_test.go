@@ -0,0 +1,153 @@
+package dto
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ugorji/go/codec"
+	"gopkg.in/yaml.v3"
+)
+
+func negotiatedContext(accept string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	if accept != "" {
+		c.Request.Header.Set("Accept", accept)
+	}
+	return c, w
+}
+
+type envelope struct {
+	Data struct {
+		Name string `json:"name" xml:"name" yaml:"name"`
+	} `json:"data" xml:"data" yaml:"data"`
+	Meta Meta `json:"meta" xml:"meta" yaml:"meta"`
+}
+
+func TestSuccess_NegotiatesFormat(t *testing.T) {
+	tests := []struct {
+		accept      string
+		contentType string
+		decode      func([]byte, any) error
+	}{
+		{"", MIMEJSON, json.Unmarshal},
+		{"application/json", MIMEJSON, json.Unmarshal},
+		{"application/vnd.api+json", MIMEJSON, json.Unmarshal},
+		{"text/xml", MIMEXML, xml.Unmarshal},
+		{"application/yaml", MIMEYAML, yaml.Unmarshal},
+		{"application/x-msgpack", MIMEMsgPack, func(data []byte, v any) error {
+			return codec.NewDecoderBytes(data, &codec.MsgpackHandle{}).Decode(v)
+		}},
+		{"application/cbor", MIMECBOR, cbor.Unmarshal},
+	}
+	for _, tt := range tests {
+		c, w := negotiatedContext(tt.accept)
+		OK(c, gin.H{"name": "gopher"})
+
+		assert.Equal(t, http.StatusOK, w.Code, tt.accept)
+		assert.Contains(t, w.Header().Get("Content-Type"), tt.contentType, tt.accept)
+		assert.Equal(t, "Accept", w.Header().Get("Vary"))
+		var resp envelope
+		require.NoError(t, tt.decode(w.Body.Bytes(), &resp), tt.accept)
+		assert.Equal(t, "gopher", resp.Data.Name, tt.accept)
+		assert.NotEmpty(t, resp.Meta.Timestamp, tt.accept)
+	}
+}
+
+func TestSuccess_XMLRootElement(t *testing.T) {
+	c, w := negotiatedContext("application/xml")
+	OK(c, map[string]any{"name": "gopher"})
+
+	assert.Contains(t, w.Body.String(), "<response><data><name>gopher</name></data><meta>")
+}
+
+func TestNegotiate_QValues(t *testing.T) {
+	tests := map[string]string{
+		"application/xml;q=0.5, application/cbor":       MIMECBOR,
+		"*/*;q=0.1, application/yaml":                   MIMEYAML,
+		"application/*":                                 MIMEJSON,
+		"application/json;q=0, */*":                     MIMEXML,
+		"application/json; version=2":                   MIMEJSON,
+		"text/html, application/msgpack;q=0.9, */*;q=0": MIMEMsgPack,
+	}
+	for accept, want := range tests {
+		c, _ := negotiatedContext(accept)
+		f, ok := negotiate(c)
+		require.True(t, ok, accept)
+		assert.Equal(t, want, f.mediaType, accept)
+	}
+}
+
+func TestSuccess_NotAcceptable(t *testing.T) {
+	c, w := negotiatedContext("image/png")
+	OK(c, gin.H{"name": "gopher"})
+
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+	var resp ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, ErrNotAcceptable, resp.Error.Code)
+	assert.False(t, Acceptable(c))
+}
+
+func TestError_FallsBackToJSON(t *testing.T) {
+	c, w := negotiatedContext("image/png")
+	NotFound(c, "missing")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), MIMEJSON)
+}
+
+func TestBind_ByContentType(t *testing.T) {
+	type payload struct {
+		Name string `json:"name" xml:"name" yaml:"name" binding:"required"`
+	}
+	var msgpackBody []byte
+	require.NoError(t, codec.NewEncoderBytes(&msgpackBody, &codec.MsgpackHandle{}).Encode(map[string]string{"name": "gopher"}))
+	cborBody, err := cbor.Marshal(map[string]string{"name": "gopher"})
+	require.NoError(t, err)
+
+	bodies := map[string][]byte{
+		"":                             []byte(`{"name":"gopher"}`),
+		"application/json":             []byte(`{"name":"gopher"}`),
+		"application/xml":              []byte(`<payload><name>gopher</name></payload>`),
+		"application/x-yaml":           []byte("name: gopher\n"),
+		"application/msgpack":          msgpackBody,
+		"application/cbor":             cborBody,
+		"application/merge-patch+json": []byte(`{"name":"gopher"}`),
+	}
+	for contentType, body := range bodies {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		if contentType != "" {
+			c.Request.Header.Set("Content-Type", contentType)
+		}
+		var p payload
+		require.NoError(t, Bind(c, &p), contentType)
+		assert.Equal(t, "gopher", p.Name, contentType)
+	}
+}
+
+func TestBind_RejectsUnsupportedAndInvalidBodies(t *testing.T) {
+	type payload struct {
+		Name string `json:"name" binding:"required"`
+	}
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte("name=gopher")))
+	c.Request.Header.Set("Content-Type", "text/plain")
+	assert.ErrorIs(t, Bind(c, &payload{}), ErrUnsupportedContentType)
+
+	cborBody, err := cbor.Marshal(map[string]string{})
+	require.NoError(t, err)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(cborBody))
+	c.Request.Header.Set("Content-Type", MIMECBOR)
+	assert.Error(t, Bind(c, &payload{}), "validation runs for CBOR too")
+}
@@ -5,59 +5,99 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/requestid"
 )
 
 type ErrorCode string
 
 const (
-	ErrUnauthorized   ErrorCode = "UNAUTHORIZED"
-	ErrForbidden      ErrorCode = "FORBIDDEN"
-	ErrNotFound       ErrorCode = "NOT_FOUND"
-	ErrBadRequest     ErrorCode = "BAD_REQUEST"
-	ErrValidation     ErrorCode = "VALIDATION_ERROR"
-	ErrConflict       ErrorCode = "CONFLICT"
-	ErrInternalServer ErrorCode = "INTERNAL_ERROR"
-	ErrServiceUnavail ErrorCode = "SERVICE_UNAVAILABLE"
+	ErrUnauthorized         ErrorCode = "UNAUTHORIZED"
+	ErrForbidden            ErrorCode = "FORBIDDEN"
+	ErrNotFound             ErrorCode = "NOT_FOUND"
+	ErrBadRequest           ErrorCode = "BAD_REQUEST"
+	ErrValidation           ErrorCode = "VALIDATION_ERROR"
+	ErrConflict             ErrorCode = "CONFLICT"
+	ErrInternalServer       ErrorCode = "INTERNAL_ERROR"
+	ErrServiceUnavail       ErrorCode = "SERVICE_UNAVAILABLE"
+	ErrRateLimited          ErrorCode = "RATE_LIMITED"
+	ErrTimeout              ErrorCode = "TIMEOUT"
+	ErrNotAcceptable        ErrorCode = "NOT_ACCEPTABLE"
+	ErrUnsupportedMediaType ErrorCode = "UNSUPPORTED_MEDIA_TYPE"
 )
 
+// The envelopes keep the same field names in every format: MessagePack and CBOR
+// follow the json tags, XML and YAML have their own.
+
 type Meta struct {
-	Timestamp string `json:"timestamp"`
+	Timestamp string `json:"timestamp" xml:"timestamp" yaml:"timestamp"`
+	RequestID string `json:"request_id,omitempty" xml:"request_id,omitempty" yaml:"request_id,omitempty"`
 }
 
 type SuccessResponse struct {
-	Data any  `json:"data"`
-	Meta Meta `json:"meta"`
+	Data any  `json:"data" xml:"data" yaml:"data"`
+	Meta Meta `json:"meta" xml:"meta" yaml:"meta"`
 }
 
 type ErrorDetail struct {
-	Code    ErrorCode `json:"code"`
-	Message string    `json:"message"`
+	Code       ErrorCode `json:"code" xml:"code" yaml:"code"`
+	Message    string    `json:"message" xml:"message" yaml:"message"`
+	IncidentID string    `json:"incident_id,omitempty" xml:"incident_id,omitempty" yaml:"incident_id,omitempty"`
+	Stack      string    `json:"stack,omitempty" xml:"stack,omitempty" yaml:"stack,omitempty"`
+	// Details lists the fields failing validation of a VALIDATION_ERROR.
+	Details []FieldError `json:"details,omitempty" xml:"details>detail,omitempty" yaml:"details,omitempty"`
 }
 
 type ErrorResponse struct {
-	Error ErrorDetail `json:"error"`
+	Error ErrorDetail `json:"error" xml:"error" yaml:"error"`
+	Meta  Meta        `json:"meta" xml:"meta" yaml:"meta"`
+}
+
+func newMeta(c *gin.Context) Meta {
+	return Meta{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		RequestID: c.GetString(requestid.Key),
+	}
 }
 
-func newMeta() Meta {
-	return Meta{Timestamp: time.Now().UTC().Format(time.RFC3339)}
+// NewErrorResponse builds the error envelope for callers that cannot write through c.Writer.
+func NewErrorResponse(c *gin.Context, code ErrorCode, message string) ErrorResponse {
+	return ErrorResponse{
+		Error: ErrorDetail{Code: code, Message: message},
+		Meta:  newMeta(c),
+	}
 }
 
+// Success writes the envelope in the format negotiated from Accept, or 406 when the
+// client accepts none of them.
 func Success(c *gin.Context, statusCode int, data any) {
-	c.JSON(statusCode, SuccessResponse{
+	f, ok := negotiate(c)
+	if !ok {
+		notAcceptable(c)
+		return
+	}
+	f.write(c, statusCode, SuccessResponse{
 		Data: data,
-		Meta: newMeta(),
+		Meta: newMeta(c),
 	})
 }
 
+// Error writes the error envelope in the negotiated format, falling back to JSON
+// so the error is not hidden behind a 406.
 func Error(c *gin.Context, statusCode int, code ErrorCode, message string) {
-	c.JSON(statusCode, ErrorResponse{
-		Error: ErrorDetail{Code: code, Message: message},
-	})
+	renderError(c, statusCode, NewErrorResponse(c, code, message))
 }
 
 func AbortWithError(c *gin.Context, statusCode int, code ErrorCode, message string) {
-	c.AbortWithStatusJSON(statusCode, ErrorResponse{
-		Error: ErrorDetail{Code: code, Message: message},
+	AbortWithErrorDetail(c, statusCode, ErrorDetail{Code: code, Message: message})
+}
+
+// AbortWithErrorDetail aborts with a fully populated ErrorDetail (incident ID, stack, ...).
+func AbortWithErrorDetail(c *gin.Context, statusCode int, detail ErrorDetail) {
+	c.Abort()
+	renderError(c, statusCode, ErrorResponse{
+		Error: detail,
+		Meta:  newMeta(c),
 	})
 }
 
@@ -84,3 +124,7 @@ func NotFound(c *gin.Context, message string) {
 func InternalError(c *gin.Context, message string) {
 	Error(c, http.StatusInternalServerError, ErrInternalServer, message)
 }
+
+func TooManyRequests(c *gin.Context, message string) {
+	AbortWithError(c, http.StatusTooManyRequests, ErrRateLimited, message)
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/oswaldom-code/api-template-gin/pkg/requestid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -188,3 +189,46 @@ func TestAbortWithError_AbortsContext(t *testing.T) {
 	assert.True(t, c.IsAborted())
 	assert.Equal(t, http.StatusForbidden, w.Code)
 }
+
+func TestMeta_IncludesRequestID(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set(requestid.Key, "req-123")
+
+	OK(c, gin.H{"ping": "pong"})
+
+	var resp SuccessResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "req-123", resp.Meta.RequestID)
+}
+
+func TestErrorResponse_IncludesRequestID(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set(requestid.Key, "req-456")
+
+	BadRequest(c, "invalid input")
+
+	var resp ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "req-456", resp.Meta.RequestID)
+	assert.NotEmpty(t, resp.Meta.Timestamp)
+}
+
+func TestAbortWithErrorDetail_KeepsIncidentID(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	AbortWithErrorDetail(c, http.StatusInternalServerError, ErrorDetail{
+		Code:       ErrInternalServer,
+		Message:    "unexpected failure",
+		IncidentID: "incident-1",
+	})
+
+	assert.True(t, c.IsAborted())
+
+	var raw map[string]map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &raw))
+	assert.Equal(t, "incident-1", raw["error"]["incident_id"])
+	assert.NotContains(t, raw["error"], "stack")
+}
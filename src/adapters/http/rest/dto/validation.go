@@ -0,0 +1,357 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError is a field failing validation. Field is a JSON pointer (RFC 6901) to
+// the field, named as in the request: "/items/0/name" for a body, "/page" for a
+// query parameter.
+type FieldError struct {
+	Field   string `json:"field" xml:"field" yaml:"field"`
+	Rule    string `json:"rule" xml:"rule" yaml:"rule"`
+	Message string `json:"message" xml:"message" yaml:"message"`
+}
+
+// ValidationError is returned by the Bind helpers for requests that decoded but
+// break the `binding` rules of the struct they were bound to.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	failures := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		failures[i] = field.Field + " " + field.Message
+	}
+	return "validation failed: " + strings.Join(failures, "; ")
+}
+
+// BindQuery binds the query parameters into obj (`form` tags) and validates it.
+func BindQuery(c *gin.Context, obj any) error {
+	return validationError(obj, c.ShouldBindQuery(obj), "form")
+}
+
+// BindURI binds the path parameters into obj (`uri` tags) and validates it.
+func BindURI(c *gin.Context, obj any) error {
+	return validationError(obj, c.ShouldBindUri(obj), "uri")
+}
+
+// BindHeader binds the request headers into obj (`header` tags) and validates it.
+func BindHeader(c *gin.Context, obj any) error {
+	return validationError(obj, c.ShouldBindHeader(obj), "header")
+}
+
+// BindRequest binds the path parameters (`uri` tags), query parameters (`form` tags),
+// headers (`header` tags) and, when there is one, the body of the request into obj
+// and validates it once all are bound. Each source only sets the fields tagged for it:
+// gin falls back to the field name for untagged fields, which would let a query
+// parameter or a header named after a body field set it.
+func BindRequest(c *gin.Context, obj any) error {
+	sources := []requestSource{
+		{"uri", c.ShouldBindUri},
+		{"form", c.ShouldBindQuery},
+		{"header", c.ShouldBindHeader},
+	}
+	// body fields are named as in JSON when the body is missing
+	bodyTag := formats[0].tag
+	if c.Request.ContentLength != 0 {
+		if format := bodyFormat(c); format != nil {
+			bodyTag = format.tag
+		}
+		sources = append(sources, requestSource{bodyTag, func(obj any) error { return Bind(c, obj) }})
+	}
+	for _, source := range sources {
+		// the bindings validate obj before the other sources are bound, only their
+		// decoding errors count
+		if err := bindTagged(obj, source.tag, source.bind); err != nil && !isValidationError(err) {
+			return err
+		}
+	}
+	return validationError(obj, binding.Validator.ValidateStruct(obj), bodyTag, "uri", "form", "header")
+}
+
+// requestSource binds one part of a request into the fields having tag.
+type requestSource struct {
+	tag  string
+	bind func(any) error
+}
+
+// bindTagged binds a source into a copy of obj holding only the fields tagged for it,
+// then copies those fields back, so the source cannot set any other field.
+func bindTagged(obj any, tag string, bind func(any) error) error {
+	target := reflect.ValueOf(obj)
+	if target.Kind() != reflect.Pointer || target.Elem().Kind() != reflect.Struct {
+		return bind(obj)
+	}
+	target = target.Elem()
+	scratch := reflect.New(target.Type())
+	copyTagged(scratch.Elem(), target, tag)
+	err := bind(scratch.Interface())
+	copyTagged(target, scratch.Elem(), tag)
+	return err
+}
+
+// copyTagged copies the fields of src having tag into dst, walking into untagged
+// structs as gin's mapping does, and reports whether it copied any.
+func copyTagged(dst, src reflect.Value, tag string) bool {
+	copied := false
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Type().Field(i)
+		name, tagged := field.Tag.Lookup(tag)
+		if name == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		switch {
+		case tagged:
+			if field.IsExported() {
+				dst.Field(i).Set(src.Field(i))
+				copied = true
+			}
+		case field.Type.Kind() == reflect.Struct:
+			copied = copyTagged(dst.Field(i), src.Field(i), tag) || copied
+		case field.IsExported() && field.Type.Kind() == reflect.Pointer && field.Type.Elem().Kind() == reflect.Struct && !src.Field(i).IsNil():
+			nested := dst.Field(i)
+			if nested.IsNil() {
+				nested = reflect.New(field.Type.Elem())
+			}
+			if copyTagged(nested.Elem(), src.Field(i).Elem(), tag) {
+				dst.Field(i).Set(nested)
+				copied = true
+			}
+		}
+	}
+	return copied
+}
+
+func isValidationError(err error) bool {
+	var validationErr *ValidationError
+	var fieldErrs validator.ValidationErrors
+	var sliceErr binding.SliceValidationError
+	return errors.As(err, &validationErr) || errors.As(err, &fieldErrs) || errors.As(err, &sliceErr)
+}
+
+// InvalidRequest answers an error of the Bind helpers: 415 for bodies in a format
+// the API does not read, 422 VALIDATION_ERROR with the failing fields and 400 for
+// requests that did not decode.
+func InvalidRequest(c *gin.Context, message string, err error) {
+	var validationErr *ValidationError
+	switch {
+	case errors.Is(err, ErrUnsupportedContentType):
+		Error(c, http.StatusUnsupportedMediaType, ErrUnsupportedMediaType, err.Error())
+	case errors.As(err, &validationErr):
+		ValidationFailed(c, message, validationErr.Fields)
+	default:
+		BadRequest(c, message+": "+err.Error())
+	}
+}
+
+// ValidationFailed writes a 422 VALIDATION_ERROR listing the failing fields, for
+// checks handlers make beyond the binding rules.
+func ValidationFailed(c *gin.Context, message string, fields []FieldError) {
+	renderError(c, http.StatusUnprocessableEntity, ErrorResponse{
+		Error: ErrorDetail{Code: ErrValidation, Message: message, Details: fields},
+		Meta:  newMeta(c),
+	})
+}
+
+// validationError converts the validator errors of a binding into a *ValidationError
+// with the fields named by the first of tags they have; other errors are returned
+// as they are.
+func validationError(obj any, err error, tags ...string) error {
+	var fieldErrs validator.ValidationErrors
+	var sliceErr binding.SliceValidationError
+	switch {
+	case errors.As(err, &fieldErrs):
+		return &ValidationError{Fields: fieldErrors(reflect.TypeOf(obj), tags, "", fieldErrs)}
+	case errors.As(err, &sliceErr):
+		// the slice error does not tell which elements failed, validate them again
+		var fields []FieldError
+		elements := reflect.Indirect(reflect.ValueOf(obj))
+		for i := 0; i < elements.Len(); i++ {
+			element := elements.Index(i)
+			if errors.As(binding.Validator.ValidateStruct(element.Interface()), &fieldErrs) {
+				fields = append(fields, fieldErrors(element.Type(), tags, "/"+strconv.Itoa(i), fieldErrs)...)
+			}
+		}
+		return &ValidationError{Fields: fields}
+	}
+	return err
+}
+
+func fieldErrors(root reflect.Type, tags []string, prefix string, errs validator.ValidationErrors) []FieldError {
+	fields := make([]FieldError, len(errs))
+	for i, fe := range errs {
+		fields[i] = FieldError{
+			Field:   prefix + fieldPointer(root, fe.StructNamespace(), tags),
+			Rule:    fe.Tag(),
+			Message: ruleMessage(fe),
+		}
+	}
+	return fields
+}
+
+// fieldPointer turns a validator namespace, e.g. "Order.Items[0].Name", into a JSON
+// pointer with the names of tags, e.g. "/items/0/name". Embedded structs without a
+// name of their own are flattened, as the decoders do.
+func fieldPointer(root reflect.Type, namespace string, tags []string) string {
+	segments := splitNamespace(namespace)
+	if len(segments) > 0 {
+		// the name of the root struct
+		segments = segments[1:]
+	}
+	var pointer strings.Builder
+	t := root
+	for _, segment := range segments {
+		name, keys, _ := strings.Cut(segment, "[")
+		t = indirectType(t)
+		if t != nil && t.Kind() == reflect.Struct {
+			if field, ok := t.FieldByName(name); ok {
+				t = field.Type
+				name = taggedName(field, tags)
+				if name == "" && field.Anonymous {
+					continue
+				}
+				if name == "" {
+					name = field.Name
+				}
+			} else {
+				t = nil
+			}
+		}
+		pointer.WriteString("/" + escapePointer(name))
+		for keys != "" {
+			var key string
+			key, keys, _ = strings.Cut(keys, "]")
+			keys = strings.TrimPrefix(keys, "[")
+			pointer.WriteString("/" + escapePointer(key))
+			if t = indirectType(t); t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map) {
+				t = t.Elem()
+			} else {
+				t = nil
+			}
+		}
+	}
+	return pointer.String()
+}
+
+// splitNamespace splits a namespace on the dots outside map keys.
+func splitNamespace(namespace string) []string {
+	var segments []string
+	depth, start := 0, 0
+	for i, r := range namespace {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '.':
+			if depth == 0 {
+				segments = append(segments, namespace[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(segments, namespace[start:])
+}
+
+// taggedName returns the name of field in the first of tags naming it.
+func taggedName(field reflect.StructField, tags []string) string {
+	for _, tag := range tags {
+		if name, _, _ := strings.Cut(field.Tag.Get(tag), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return ""
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func escapePointer(token string) string {
+	return pointerEscaper.Replace(token)
+}
+
+// ruleMessage describes the failed rule to clients; the field is reported apart.
+func ruleMessage(fe validator.FieldError) string {
+	param := fe.Param()
+	sized := false
+	switch fe.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		sized = true
+	}
+	unit := "items"
+	if fe.Kind() == reflect.String {
+		unit = "characters"
+	}
+
+	switch fe.Tag() {
+	case "required", "required_if", "required_unless", "required_with", "required_with_all",
+		"required_without", "required_without_all":
+		return "is required"
+	case "min", "gte":
+		if sized {
+			return fmt.Sprintf("must contain at least %s %s", param, unit)
+		}
+		return "must be at least " + param
+	case "max", "lte":
+		if sized {
+			return fmt.Sprintf("must contain at most %s %s", param, unit)
+		}
+		return "must be at most " + param
+	case "gt":
+		if sized {
+			return fmt.Sprintf("must contain more than %s %s", param, unit)
+		}
+		return "must be greater than " + param
+	case "lt":
+		if sized {
+			return fmt.Sprintf("must contain less than %s %s", param, unit)
+		}
+		return "must be less than " + param
+	case "len":
+		if sized {
+			return fmt.Sprintf("must contain exactly %s %s", param, unit)
+		}
+		return "must be " + param
+	case "eq":
+		return "must be " + param
+	case "ne":
+		return "must not be " + param
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(param), ", ")
+	case "email":
+		return "must be a valid email address"
+	case "url", "http_url", "uri":
+		return "must be a valid URL"
+	case "uuid", "uuid4", "uuid7":
+		return "must be a valid UUID"
+	case "datetime":
+		return "must be a date in the " + param + " layout"
+	case "alpha":
+		return "must contain only letters"
+	case "alphanum":
+		return "must contain only letters and digits"
+	case "numeric", "number":
+		return "must be numeric"
+	}
+	if param != "" {
+		return fmt.Sprintf("does not satisfy %s=%s", fe.Tag(), param)
+	}
+	return "does not satisfy " + fe.Tag()
+}
@@ -0,0 +1,193 @@
+package dto
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Audit struct {
+	Reason string `json:"reason" xml:"reason" binding:"required"`
+}
+
+type orderItem struct {
+	SKU      string `json:"sku" xml:"sku" binding:"required"`
+	Quantity int    `json:"quantity" xml:"quantity" binding:"min=1"`
+}
+
+type order struct {
+	Audit
+	Customer string            `json:"customer" xml:"customer" binding:"required,email"`
+	Status   string            `json:"status,omitempty" xml:"status" binding:"omitempty,oneof=draft placed"`
+	Items    []orderItem       `json:"items" xml:"item" binding:"required,min=1,dive"`
+	Labels   map[string]string `json:"labels" xml:"-" binding:"dive,max=3"`
+}
+
+func bindBody(contentType, body string, obj any) error {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", contentType)
+	return Bind(c, obj)
+}
+
+func TestBind_ReportsFieldsAsJSONPointers(t *testing.T) {
+	body := `{"customer": "not-an-email", "status": "shipped",
+		"items": [{"sku": "A1", "quantity": 1}, {"quantity": 0}],
+		"labels": {"a/b": "long"}}`
+
+	err := bindBody(MIMEJSON, body, &order{})
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.ElementsMatch(t, []FieldError{
+		{Field: "/reason", Rule: "required", Message: "is required"},
+		{Field: "/customer", Rule: "email", Message: "must be a valid email address"},
+		{Field: "/status", Rule: "oneof", Message: "must be one of draft, placed"},
+		{Field: "/items/1/sku", Rule: "required", Message: "is required"},
+		{Field: "/items/1/quantity", Rule: "min", Message: "must be at least 1"},
+		{Field: "/labels/a~1b", Rule: "max", Message: "must contain at most 3 characters"},
+	}, validationErr.Fields)
+}
+
+func TestBind_NamesFieldsAfterTheBodyFormat(t *testing.T) {
+	body := `<order><reason>audit</reason><customer>gopher@example.com</customer><item><sku>A1</sku></item></order>`
+
+	var validationErr *ValidationError
+	require.ErrorAs(t, bindBody(MIMEXML, body, &order{}), &validationErr)
+	assert.Equal(t, []FieldError{
+		{Field: "/item/0/quantity", Rule: "min", Message: "must be at least 1"},
+	}, validationErr.Fields)
+}
+
+func TestBind_SliceBodies(t *testing.T) {
+	var validationErr *ValidationError
+	require.ErrorAs(t, bindBody(MIMEJSON, `[{"sku": "A1", "quantity": 1}, {"sku": "B2"}]`, &[]orderItem{}), &validationErr)
+	assert.Equal(t, []FieldError{
+		{Field: "/1/quantity", Rule: "min", Message: "must be at least 1"},
+	}, validationErr.Fields)
+}
+
+func TestBind_DecodeErrorsAreNotValidationErrors(t *testing.T) {
+	err := bindBody(MIMEJSON, `{"customer": 42}`, &order{})
+	require.Error(t, err)
+	var validationErr *ValidationError
+	assert.False(t, errors.As(err, &validationErr))
+}
+
+func TestBindQueryURIAndHeader(t *testing.T) {
+	type listParams struct {
+		Page    int    `form:"page" binding:"gte=1"`
+		Sort    string `form:"sort" binding:"omitempty,oneof=asc desc"`
+		OrderID string `uri:"id" binding:"required,uuid"`
+		Tenant  string `header:"X-Tenant" binding:"required"`
+	}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	var errs []error
+	router.GET("/orders/:id", func(c *gin.Context) {
+		var query, uri, header listParams
+		errs = []error{BindQuery(c, &query), BindURI(c, &uri), BindHeader(c, &header)}
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/42?page=0&sort=up", nil))
+
+	fields := func(err error) []string {
+		var validationErr *ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		names := make([]string, len(validationErr.Fields))
+		for i, field := range validationErr.Fields {
+			names[i] = field.Field + ":" + field.Rule
+		}
+		return names
+	}
+	require.Len(t, errs, 3)
+	assert.Equal(t, []string{"/page:gte", "/sort:oneof", "/OrderID:required", "/Tenant:required"}, fields(errs[0]))
+	assert.Contains(t, fields(errs[1]), "/id:uuid")
+	assert.Contains(t, fields(errs[2]), "/X-Tenant:required")
+}
+
+func TestBindRequest_SourcesOnlySetTheirTaggedFields(t *testing.T) {
+	type pagination struct {
+		Page int `form:"page"`
+	}
+	type Filters struct {
+		Status string `form:"status"`
+	}
+	type request struct {
+		pagination
+		*Filters
+		ID     int    `uri:"id"`
+		Tenant string `header:"X-Tenant"`
+		Name   string `json:"name"`
+		Role   string `json:"role"`
+		Secret string
+	}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	var req request
+	var err error
+	router.PUT("/orders/:id", func(c *gin.Context) {
+		err = BindRequest(c, &req)
+	})
+	httpReq := httptest.NewRequest(http.MethodPut, "/orders/7?page=2&status=placed&Role=admin&Secret=s&ID=9&Tenant=evil",
+		strings.NewReader(`{"name": "gopher", "ID": 9, "Tenant": "evil", "Secret": "s", "Page": 5}`))
+	httpReq.Header.Set("Content-Type", MIMEJSON)
+	httpReq.Header.Set("X-Tenant", "acme")
+	httpReq.Header.Set("Role", "admin")
+	httpReq.Header.Set("Name", "evil")
+	router.ServeHTTP(httptest.NewRecorder(), httpReq)
+
+	require.NoError(t, err)
+	assert.Equal(t, request{
+		pagination: pagination{Page: 2},
+		Filters:    &Filters{Status: "placed"},
+		ID:         7,
+		Tenant:     "acme",
+		Name:       "gopher",
+	}, req)
+}
+
+func TestInvalidRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		code    ErrorCode
+		message string
+		details []FieldError
+	}{
+		{"unsupported content type", ErrUnsupportedContentType, http.StatusUnsupportedMediaType, ErrUnsupportedMediaType, "unsupported content type", nil},
+		{"validation", &ValidationError{Fields: []FieldError{{Field: "/name", Rule: "required", Message: "is required"}}},
+			http.StatusUnprocessableEntity, ErrValidation, "Invalid order", []FieldError{{Field: "/name", Rule: "required", Message: "is required"}}},
+		{"decode", &json.SyntaxError{}, http.StatusBadRequest, ErrBadRequest, "Invalid order: ", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := negotiatedContext("")
+			InvalidRequest(c, "Invalid order", tt.err)
+
+			assert.Equal(t, tt.status, w.Code)
+			var resp ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.code, resp.Error.Code)
+			assert.Equal(t, tt.message, resp.Error.Message)
+			assert.Equal(t, tt.details, resp.Error.Details)
+		})
+	}
+}
+
+func TestValidationFailed_XML(t *testing.T) {
+	c, w := negotiatedContext(MIMEXML)
+	ValidationFailed(c, "Invalid order", []FieldError{{Field: "/name", Rule: "required", Message: "is required"}})
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var resp ErrorResponse
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []FieldError{{Field: "/name", Rule: "required", Message: "is required"}}, resp.Error.Details)
+}
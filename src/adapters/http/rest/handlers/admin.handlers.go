@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oswaldom-code/api-template-gin/pkg/log"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+	Service "github.com/oswaldom-code/api-template-gin/src/application/system_services"
+	"github.com/oswaldom-code/api-template-gin/src/domain/entities"
+)
+
+// AdminHandler serves the operational endpoints of the admin listener.
+type AdminHandler struct {
+	healthService func() (Service.Health, error)
+	maintenance   Service.Maintenance
+}
+
+// NewAdminHandler returns an AdminHandler; healthService is resolved on every
+// readiness check so the process can start before the database is reachable.
+func NewAdminHandler(healthService func() (Service.Health, error), maintenance Service.Maintenance) *AdminHandler {
+	return &AdminHandler{healthService: healthService, maintenance: maintenance}
+}
+
+// MaintenanceRequest turns maintenance mode on or off. An empty Message or RetryAfter
+// (seconds) uses the configured one. MessagePack and CBOR follow the json tags.
+type MaintenanceRequest struct {
+	Enabled    *bool  `json:"enabled" xml:"enabled" yaml:"enabled" binding:"required"`
+	Message    string `json:"message" xml:"message" yaml:"message"`
+	RetryAfter int    `json:"retry_after" xml:"retry_after" yaml:"retry_after" binding:"min=0"`
+}
+
+// Liveness reports that the process is up and serving requests.
+func (h *AdminHandler) Liveness(c *gin.Context) {
+	dto.OK(c, gin.H{"status": "alive"})
+}
+
+// Readiness reports whether the dependencies needed to serve traffic are available.
+func (h *AdminHandler) Readiness(c *gin.Context) {
+	health, err := h.healthService()
+	if err != nil {
+		dto.Error(c, http.StatusServiceUnavailable, dto.ErrServiceUnavail, "Health service unavailable")
+		return
+	}
+	if err := health.TestDb(c.Request.Context()); err != nil {
+		dto.Error(c, http.StatusServiceUnavailable, dto.ErrServiceUnavail, "Database unavailable")
+		return
+	}
+	dto.OK(c, gin.H{"status": "ready"})
+}
+
+// MaintenanceStatus reports whether the API is in maintenance mode.
+func (h *AdminHandler) MaintenanceStatus(c *gin.Context) {
+	dto.OK(c, maintenanceStatus(h.maintenance.Status()))
+}
+
+// SetMaintenance turns maintenance mode on or off.
+func (h *AdminHandler) SetMaintenance(c *gin.Context) {
+	var request MaintenanceRequest
+	if err := dto.Bind(c, &request); err != nil {
+		dto.InvalidRequest(c, "Invalid maintenance request", err)
+		return
+	}
+	var mode entities.MaintenanceMode
+	if *request.Enabled {
+		mode = h.maintenance.Enable(request.Message, time.Duration(request.RetryAfter)*time.Second)
+	} else {
+		mode = h.maintenance.Disable()
+	}
+	log.Info("Maintenance mode changed", log.Fields{"enabled": mode.Enabled, "client_ip": c.ClientIP()})
+	dto.OK(c, maintenanceStatus(mode))
+}
+
+func maintenanceStatus(mode entities.MaintenanceMode) gin.H {
+	status := gin.H{"enabled": mode.Enabled}
+	if !mode.Enabled {
+		return status
+	}
+	status["since"] = mode.Since.UTC().Format(time.RFC3339)
+	if mode.Message != "" {
+		status["message"] = mode.Message
+	}
+	if mode.RetryAfter > 0 {
+		status["retry_after"] = int(mode.RetryAfter.Seconds())
+	}
+	return status
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+	Service "github.com/oswaldom-code/api-template-gin/src/application/system_services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeHealth struct {
+	err error
+}
+
+func (f fakeHealth) TestDb(ctx context.Context) error {
+	return f.err
+}
+
+func serveAdmin(handler *AdminHandler, path string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/health/live", handler.Liveness)
+	router.GET("/health/ready", handler.Readiness)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestLiveness_Returns200(t *testing.T) {
+	handler := NewAdminHandler(func() (Service.Health, error) {
+		return nil, errors.New("not needed")
+	}, Service.NewMaintenance())
+
+	w := serveAdmin(handler, "/health/live")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestReadiness_Ready(t *testing.T) {
+	handler := NewAdminHandler(func() (Service.Health, error) {
+		return fakeHealth{}, nil
+	}, Service.NewMaintenance())
+
+	w := serveAdmin(handler, "/health/ready")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestReadiness_DatabaseDown(t *testing.T) {
+	handler := NewAdminHandler(func() (Service.Health, error) {
+		return fakeHealth{err: errors.New("connection refused")}, nil
+	}, Service.NewMaintenance())
+
+	w := serveAdmin(handler, "/health/ready")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	var resp dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, dto.ErrServiceUnavail, resp.Error.Code)
+}
+
+func TestReadiness_ServiceUnavailable(t *testing.T) {
+	handler := NewAdminHandler(func() (Service.Health, error) {
+		return nil, errors.New("failed to connect to database")
+	}, Service.NewMaintenance())
+
+	w := serveAdmin(handler, "/health/ready")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestSetMaintenance_TogglesMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	maintenance := Service.NewMaintenance()
+	handler := NewAdminHandler(func() (Service.Health, error) {
+		return fakeHealth{}, nil
+	}, maintenance)
+	router := gin.New()
+	router.GET("/maintenance", handler.MaintenanceStatus)
+	router.PUT("/maintenance", handler.SetMaintenance)
+
+	put := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/maintenance", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := put(`{"enabled": true, "message": "Migrating the database", "retry_after": 120}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	mode := maintenance.Status()
+	assert.True(t, mode.Enabled)
+	assert.Equal(t, "Migrating the database", mode.Message)
+	assert.Equal(t, 2*time.Minute, mode.RetryAfter)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/maintenance", nil))
+	var resp struct {
+		Data map[string]any `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, true, resp.Data["enabled"])
+	assert.Equal(t, "Migrating the database", resp.Data["message"])
+	assert.EqualValues(t, 120, resp.Data["retry_after"])
+
+	assert.Equal(t, http.StatusOK, put(`{"enabled": false}`).Code)
+	assert.False(t, maintenance.Status().Enabled)
+}
+
+func TestSetMaintenance_RejectsInvalidRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewAdminHandler(func() (Service.Health, error) {
+		return fakeHealth{}, nil
+	}, Service.NewMaintenance())
+	router := gin.New()
+	router.PUT("/maintenance", handler.SetMaintenance)
+
+	for body, status := range map[string]int{
+		`{"message": "no enabled field"}`:      http.StatusUnprocessableEntity,
+		`{"enabled": true, "retry_after": -1}`: http.StatusUnprocessableEntity,
+		`{"enabled": true`:                     http.StatusBadRequest,
+	} {
+		req := httptest.NewRequest(http.MethodPut, "/maintenance", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, body)
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "/maintenance", strings.NewReader("enabled=true"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/log"
+	"github.com/oswaldom-code/api-template-gin/pkg/requestid"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+)
+
+// StatusCoder is implemented by responses sent with another status than 200,
+// e.g. 201 for a creation.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// Handle adapts fn, which knows nothing of gin, to a gin handler. The request is
+// bound into Req, a struct, from its path (`uri` tags), query (`form` tags), headers
+// (`header` tags) and body, and validated against its `binding` rules; invalid
+// requests are answered like dto.InvalidRequest. The response of fn is written with
+// dto.Success and its error with the error envelope:
+//   - *dto.APIError: its code and message, with the status of the code
+//   - *dto.ValidationError: 422 VALIDATION_ERROR with the failing fields
+//   - context.DeadlineExceeded: 504 TIMEOUT
+//   - other errors: logged with an incident ID and reported as 500 without their message
+func Handle[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req Req
+		if err := dto.BindRequest(c, &req); err != nil {
+			dto.InvalidRequest(c, "Invalid request", err)
+			return
+		}
+		resp, err := fn(c.Request.Context(), req)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+		statusCode := http.StatusOK
+		if coder, ok := any(resp).(StatusCoder); ok {
+			statusCode = coder.StatusCode()
+		}
+		dto.Success(c, statusCode, resp)
+	}
+}
+
+func handleError(c *gin.Context, err error) {
+	ctx := c.Request.Context()
+	var apiErr *dto.APIError
+	var validationErr *dto.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		dto.ValidationFailed(c, "Invalid request", validationErr.Fields)
+	case errors.As(err, &apiErr):
+		dto.Error(c, dto.StatusCode(apiErr.Code), apiErr.Code, apiErr.Message)
+	case errors.Is(err, context.DeadlineExceeded):
+		dto.Error(c, http.StatusGatewayTimeout, dto.ErrTimeout, "The request took too long to complete")
+	case errors.Is(err, context.Canceled) && ctx.Err() != nil:
+		// the client is gone, nothing can be sent
+		c.Abort()
+	default:
+		incidentID := requestid.New()
+		log.Error("Handler failed", log.ContextFields(ctx, log.Fields{
+			"incident_id": incidentID,
+			"error":       err.Error(),
+			"method":      c.Request.Method,
+			"path":        c.Request.URL.Path,
+		}))
+		dto.AbortWithErrorDetail(c, http.StatusInternalServerError, dto.ErrorDetail{
+			Code:       dto.ErrInternalServer,
+			Message:    "An unexpected error occurred",
+			IncidentID: incidentID,
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type renameOrderRequest struct {
+	ID      int    `uri:"id" binding:"required,min=1"`
+	Notify  bool   `form:"notify"`
+	Tenant  string `header:"X-Tenant" binding:"required"`
+	Name    string `json:"name" binding:"required,max=10"`
+	Comment string `json:"comment"`
+}
+
+type order struct {
+	ID     int    `json:"id"`
+	Tenant string `json:"tenant"`
+	Name   string `json:"name"`
+	Notify bool   `json:"notify"`
+}
+
+type createdOrder struct {
+	order
+}
+
+func (createdOrder) StatusCode() int {
+	return http.StatusCreated
+}
+
+// renameOrder is a handler as services would write it, testable without gin.
+func renameOrder(ctx context.Context, req renameOrderRequest) (order, error) {
+	switch req.ID {
+	case 404:
+		return order{}, dto.NewAPIError(dto.ErrNotFound, fmt.Sprintf("order %d not found", req.ID))
+	case 500:
+		return order{}, errors.New("pq: connection refused")
+	case 504:
+		return order{}, fmt.Errorf("renaming order: %w", context.DeadlineExceeded)
+	}
+	if req.Name == "reserved" {
+		return order{}, &dto.ValidationError{Fields: []dto.FieldError{{Field: "/name", Rule: "unique", Message: "is taken"}}}
+	}
+	return order{ID: req.ID, Tenant: req.Tenant, Name: req.Name, Notify: req.Notify}, nil
+}
+
+func setupHandleRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.PUT("/orders/:id", Handle(renameOrder))
+	router.POST("/orders/:id", Handle(func(ctx context.Context, req renameOrderRequest) (createdOrder, error) {
+		o, err := renameOrder(ctx, req)
+		return createdOrder{o}, err
+	}))
+	return router
+}
+
+func doHandle(router *gin.Engine, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tenant", "acme")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRenameOrder_WithoutGin(t *testing.T) {
+	o, err := renameOrder(context.Background(), renameOrderRequest{ID: 1, Tenant: "acme", Name: "gopher"})
+	require.NoError(t, err)
+	assert.Equal(t, order{ID: 1, Tenant: "acme", Name: "gopher"}, o)
+}
+
+func TestHandle_BindsEverySourceAndRendersTheResponse(t *testing.T) {
+	router := setupHandleRouter()
+
+	w := doHandle(router, http.MethodPut, "/orders/7?notify=true", `{"name": "gopher"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		Data order    `json:"data"`
+		Meta dto.Meta `json:"meta"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, order{ID: 7, Tenant: "acme", Name: "gopher", Notify: true}, resp.Data)
+	assert.NotEmpty(t, resp.Meta.Timestamp)
+
+	w = doHandle(router, http.MethodPost, "/orders/7", `{"name": "gopher"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestHandle_IgnoresParametersNamedAfterBodyFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.PUT("/orders/:id", Handle(func(ctx context.Context, req renameOrderRequest) (renameOrderRequest, error) {
+		return req, nil
+	}))
+
+	w := doHandle(router, http.MethodPut, "/orders/7?Comment=query&comment=query&Name=query", `{"name": "gopher"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		Data renameOrderRequest `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, renameOrderRequest{ID: 7, Tenant: "acme", Name: "gopher"}, resp.Data)
+
+	req := httptest.NewRequest(http.MethodPut, "/orders/7", strings.NewReader(`{"name": "gopher"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tenant", "acme")
+	req.Header.Set("Comment", "header")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Empty(t, resp.Data.Comment)
+}
+
+func TestHandle_InvalidRequests(t *testing.T) {
+	router := setupHandleRouter()
+
+	w := doHandle(router, http.MethodPut, "/orders/0", `{"name": "a name too long"}`)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var resp dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, dto.ErrValidation, resp.Error.Code)
+	assert.ElementsMatch(t, []dto.FieldError{
+		{Field: "/id", Rule: "required", Message: "is required"},
+		{Field: "/name", Rule: "max", Message: "must contain at most 10 characters"},
+	}, resp.Error.Details)
+
+	w = doHandle(router, http.MethodPut, "/orders/7", ``)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, []dto.FieldError{{Field: "/name", Rule: "required", Message: "is required"}}, resp.Error.Details)
+
+	w = doHandle(router, http.MethodPut, "/orders/seven", `{"name": "gopher"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doHandle(router, http.MethodPut, "/orders/7", `{"name": `)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHandle_MapsErrors(t *testing.T) {
+	router := setupHandleRouter()
+
+	tests := []struct {
+		target  string
+		body    string
+		status  int
+		code    dto.ErrorCode
+		message string
+	}{
+		{"/orders/404", `{"name": "gopher"}`, http.StatusNotFound, dto.ErrNotFound, "order 404 not found"},
+		{"/orders/500", `{"name": "gopher"}`, http.StatusInternalServerError, dto.ErrInternalServer, "An unexpected error occurred"},
+		{"/orders/504", `{"name": "gopher"}`, http.StatusGatewayTimeout, dto.ErrTimeout, "The request took too long to complete"},
+		{"/orders/7", `{"name": "reserved"}`, http.StatusUnprocessableEntity, dto.ErrValidation, "Invalid request"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target+tt.body, func(t *testing.T) {
+			w := doHandle(router, http.MethodPut, tt.target, tt.body)
+			assert.Equal(t, tt.status, w.Code)
+			var resp dto.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.code, resp.Error.Code)
+			assert.Equal(t, tt.message, resp.Error.Message)
+			assert.NotContains(t, w.Body.String(), "pq:")
+			if tt.code == dto.ErrInternalServer {
+				assert.NotEmpty(t, resp.Error.IncidentID)
+			}
+		})
+	}
+}
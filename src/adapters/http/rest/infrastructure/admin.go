@@ -0,0 +1,90 @@
+package infrastructure
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/pkg/listener"
+	"github.com/oswaldom-code/api-template-gin/pkg/log"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/handlers"
+	Service "github.com/oswaldom-code/api-template-gin/src/application/system_services"
+)
+
+// AdminInterface represents the handlers served on the admin listener.
+type AdminInterface interface {
+	MaintenanceInterface
+	Liveness(c *gin.Context)
+	Readiness(c *gin.Context)
+}
+
+// AdminServerOptions provides options for the admin server.
+type AdminServerOptions struct {
+	Middlewares []gin.HandlerFunc
+}
+
+// RegisterAdminHandlers registers metrics, health and maintenance endpoints behind the admin middlewares.
+// The returned group is where further operational routes are mounted.
+func RegisterAdminHandlers(router *gin.Engine, ai AdminInterface, options AdminServerOptions) *gin.RouterGroup {
+	admin := router.Group("/")
+	for _, m := range options.Middlewares {
+		admin.Use(m)
+	}
+	{
+		exposeMetrics(admin)
+		admin.GET("/health/live", ai.Liveness)
+		admin.GET("/health/ready", ai.Readiness)
+		RegisterMaintenanceHandlers(admin, ai)
+	}
+	return admin
+}
+
+// NewAdminServer creates the Gin engine for the admin listener.
+func NewAdminServer() *gin.Engine {
+	serverConfig := config.GetServerConfig()
+
+	router := gin.New()
+	if err := configureClientIP(router, serverConfig); err != nil {
+		panic("[ERROR] server configuration is not valid: " + err.Error())
+	}
+	router.Use(requestIDMiddleware, gin.LoggerWithFormatter(accessLogFormatter), recoveryMiddleware)
+	if securityConfig := config.GetSecurityHeadersConfig(); securityConfig.Enabled {
+		router.Use(newSecurityHeadersMiddleware(securityConfig))
+	}
+
+	middlewares, err := adminMiddlewares(serverConfig)
+	if err != nil {
+		panic("[ERROR] admin listener configuration is not valid: " + err.Error())
+	}
+
+	admin := RegisterAdminHandlers(router, handlers.NewAdminHandler(Service.HealthService, Service.MaintenanceService()), AdminServerOptions{
+		Middlewares: middlewares,
+	})
+	if serverConfig.DebugEndpoints {
+		RegisterDebugHandlers(admin)
+	}
+	return router
+}
+
+// adminMiddlewares authenticates the admin listener with server.admin.secret. Without
+// one, only a listener this host alone can reach (loopback or unix socket) may start:
+// it serves the maintenance toggle and, when enabled, pprof.
+func adminMiddlewares(serverConfig config.ServerConfig) ([]gin.HandlerFunc, error) {
+	if serverConfig.AdminSecret != "" {
+		return []gin.HandlerFunc{newBasicAuthorizationMiddleware(func() string {
+			return config.GetServerConfig().AdminSecret
+		})}, nil
+	}
+	spec, err := listener.ParseSpec(serverConfig.AdminListen)
+	if err != nil {
+		return nil, err
+	}
+	if !spec.Local() {
+		return nil, fmt.Errorf("server.admin.secret is required for %s, which is reachable from other hosts", spec)
+	}
+	log.Warn("Admin listener has no authentication, set server.admin.secret", log.Fields{
+		"listen": serverConfig.AdminListen,
+	})
+	return nil, nil
+}
@@ -0,0 +1,83 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/handlers"
+	Service "github.com/oswaldom-code/api-template-gin/src/application/system_services"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeHealth struct {
+	err error
+}
+
+func (f fakeHealth) TestDb(ctx context.Context) error {
+	return f.err
+}
+
+func setupAdminRouter(middlewares ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	adminHandler := handlers.NewAdminHandler(func() (Service.Health, error) {
+		return fakeHealth{}, nil
+	}, Service.NewMaintenance())
+	RegisterAdminHandlers(router, adminHandler, AdminServerOptions{Middlewares: middlewares})
+	return router
+}
+
+func TestAdmin_ExposesMetricsAndHealth(t *testing.T) {
+	router := setupAdminRouter()
+
+	for _, path := range []string{"/metrics", "/health/live", "/health/ready"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, w.Code, path)
+	}
+}
+
+func TestAdmin_RequiresOwnSecret(t *testing.T) {
+	router := setupAdminRouter(newBasicAuthorizationMiddleware(func() string { return "admin_secret" }))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("admin_secret")))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAdminMiddlewares_SecretRequiredOffHost(t *testing.T) {
+	middlewares, err := adminMiddlewares(config.ServerConfig{AdminListen: "tcp://0.0.0.0:9001", AdminSecret: "admin_secret"})
+	assert.NoError(t, err)
+	assert.Len(t, middlewares, 1)
+
+	for _, listen := range []string{"tcp://0.0.0.0:9001", "tcp://:9001", "systemd:admin"} {
+		_, err := adminMiddlewares(config.ServerConfig{AdminListen: listen})
+		assert.Error(t, err, listen)
+	}
+	for _, listen := range []string{"tcp://127.0.0.1:9001", "unix:///run/api-admin.sock"} {
+		middlewares, err := adminMiddlewares(config.ServerConfig{AdminListen: listen})
+		assert.NoError(t, err, listen)
+		assert.Empty(t, middlewares, listen)
+	}
+}
+
+func TestPublicRouter_DoesNotExposeMetricsWithAdminListener(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	setMetrics(router, false)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,146 @@
+package infrastructure
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+)
+
+// configureClientIP makes c.ClientIP() honor the client IP headers only for requests
+// coming from a trusted proxy. gin trusts every proxy by default, which lets any
+// client choose its address through X-Forwarded-For.
+//
+// Requests over a unix socket have no peer address. Their peer, a process of this
+// host, is trusted as a proxy: the client IP comes from the client IP headers, or is
+// the loopback address without them.
+func configureClientIP(router *gin.Engine, serverConfig config.ServerConfig) error {
+	router.ForwardedByClientIP = len(serverConfig.ClientIPHeaders) > 0
+	router.RemoteIPHeaders = serverConfig.ClientIPHeaders
+	if err := router.SetTrustedProxies(serverConfig.TrustedProxies); err != nil {
+		return fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+	trustedProxies, err := parsePrefixes(serverConfig.TrustedProxies)
+	if err != nil {
+		return fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+	unix := &unixClientIP{headers: serverConfig.ClientIPHeaders, trustedProxies: trustedProxies}
+	router.Use(unix.handle)
+	return nil
+}
+
+// unixClientIP sets the remote address of requests over a unix socket to their client IP.
+type unixClientIP struct {
+	headers        []string
+	trustedProxies []netip.Prefix
+}
+
+func (u *unixClientIP) handle(c *gin.Context) {
+	if local, ok := c.Request.Context().Value(http.LocalAddrContextKey).(net.Addr); ok && local.Network() == "unix" {
+		c.Request.RemoteAddr = net.JoinHostPort(u.clientIP(c.Request.Header), "0")
+	}
+	c.Next()
+}
+
+// clientIP reads the client IP headers in order the way gin does: the rightmost address
+// not of a trusted proxy, or the leftmost one.
+func (u *unixClientIP) clientIP(header http.Header) string {
+	for _, name := range u.headers {
+		items := strings.Split(header.Get(name), ",")
+		for i := len(items) - 1; i >= 0; i-- {
+			addr, err := netip.ParseAddr(strings.TrimSpace(items[i]))
+			if err != nil {
+				break
+			}
+			if i == 0 || !u.trusted(addr.Unmap()) {
+				return addr.String()
+			}
+		}
+	}
+	return "127.0.0.1"
+}
+
+func (u *unixClientIP) trusted(addr netip.Addr) bool {
+	for _, prefix := range u.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ipFilter is a compiled config.IPFilterConfig.
+type ipFilter struct {
+	allow []netip.Prefix
+	deny  []netip.Prefix
+}
+
+// newIPFilterMiddleware rejects clients outside cfg with 403. It relies on c.ClientIP(),
+// so the trusted proxies must be configured for deployments behind a proxy.
+func newIPFilterMiddleware(cfg config.IPFilterConfig) (gin.HandlerFunc, error) {
+	allow, err := parsePrefixes(cfg.Allow)
+	if err != nil {
+		return nil, err
+	}
+	deny, err := parsePrefixes(cfg.Deny)
+	if err != nil {
+		return nil, err
+	}
+	filter := &ipFilter{allow: allow, deny: deny}
+	return filter.handle, nil
+}
+
+func (f *ipFilter) handle(c *gin.Context) {
+	addr, err := netip.ParseAddr(c.ClientIP())
+	if err != nil || !f.allowed(addr.Unmap()) {
+		dto.AbortWithError(c, http.StatusForbidden, dto.ErrForbidden, "Access from this address is not allowed")
+		return
+	}
+	c.Next()
+}
+
+func (f *ipFilter) allowed(addr netip.Addr) bool {
+	for _, prefix := range f.deny {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	if len(f.allow) == 0 {
+		return true
+	}
+	for _, prefix := range f.allow {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePrefixes parses CIDRs and single IPs (as /32 or /128 prefixes).
+func parsePrefixes(entries []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR %q: %w", entry, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP %q: %w", entry, err)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
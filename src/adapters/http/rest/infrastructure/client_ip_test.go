@@ -0,0 +1,205 @@
+package infrastructure
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupClientIPRouter(t *testing.T, serverConfig config.ServerConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	require.NoError(t, configureClientIP(router, serverConfig))
+	router.GET("/ip", func(c *gin.Context) {
+		c.String(http.StatusOK, c.ClientIP())
+	})
+	return router
+}
+
+func clientIPRequest(router *gin.Engine, path, remoteAddr string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = remoteAddr
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestClientIP_OnlyTrustedProxiesSetTheClientIP(t *testing.T) {
+	router := setupClientIPRouter(t, config.ServerConfig{
+		TrustedProxies:  []string{"10.0.0.0/8"},
+		ClientIPHeaders: []string{"X-Forwarded-For"},
+	})
+	spoofed := map[string]string{"X-Forwarded-For": "203.0.113.7"}
+
+	w := clientIPRequest(router, "/ip", "10.1.2.3:4000", spoofed)
+	assert.Equal(t, "203.0.113.7", w.Body.String(), "forwarded by a trusted proxy")
+
+	w = clientIPRequest(router, "/ip", "198.51.100.9:4000", spoofed)
+	assert.Equal(t, "198.51.100.9", w.Body.String(), "untrusted peers cannot choose their address")
+}
+
+func TestClientIP_NoTrustedProxiesByDefault(t *testing.T) {
+	router := setupClientIPRouter(t, config.ServerConfig{
+		ClientIPHeaders: []string{"X-Forwarded-For", "X-Real-IP"},
+	})
+
+	w := clientIPRequest(router, "/ip", "10.1.2.3:4000", map[string]string{"X-Real-IP": "203.0.113.7"})
+
+	assert.Equal(t, "10.1.2.3", w.Body.String())
+}
+
+func TestClientIP_CustomHeader(t *testing.T) {
+	router := setupClientIPRouter(t, config.ServerConfig{
+		TrustedProxies:  []string{"192.0.2.1"},
+		ClientIPHeaders: []string{"CF-Connecting-IP"},
+	})
+
+	w := clientIPRequest(router, "/ip", "192.0.2.1:4000", map[string]string{
+		"CF-Connecting-IP": "203.0.113.7",
+		"X-Forwarded-For":  "198.51.100.1",
+	})
+
+	assert.Equal(t, "203.0.113.7", w.Body.String())
+}
+
+// unixSocketClient serves router on a unix socket and returns a client connecting to it.
+func unixSocketClient(t *testing.T, router *gin.Engine) *http.Client {
+	dir, err := os.MkdirTemp("", "api")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "api.sock")
+	ln, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	server := &http.Server{Handler: router}
+	go server.Serve(ln)
+	t.Cleanup(func() { server.Close() })
+
+	return &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+}
+
+func TestClientIP_UnixSocketPeerIsATrustedProxy(t *testing.T) {
+	router := setupClientIPRouter(t, config.ServerConfig{
+		TrustedProxies:  []string{"10.0.0.0/8"},
+		ClientIPHeaders: []string{"X-Forwarded-For"},
+	})
+	filter, err := newIPFilterMiddleware(config.IPFilterConfig{Allow: []string{"127.0.0.1", "203.0.113.0/24"}})
+	require.NoError(t, err)
+	router.GET("/filtered", filter, func(c *gin.Context) {
+		c.String(http.StatusOK, c.ClientIP())
+	})
+	client := unixSocketClient(t, router)
+
+	get := func(path, forwardedFor string) (int, string) {
+		req, err := http.NewRequest(http.MethodGet, "http://api"+path, nil)
+		require.NoError(t, err)
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	_, ip := get("/ip", "")
+	assert.Equal(t, "127.0.0.1", ip, "a local peer without forwarding headers")
+	_, ip = get("/ip", "203.0.113.7")
+	assert.Equal(t, "203.0.113.7", ip)
+	_, ip = get("/ip", "198.51.100.1, 203.0.113.7, 10.1.2.3")
+	assert.Equal(t, "203.0.113.7", ip, "the rightmost address not of a trusted proxy")
+
+	status, _ := get("/filtered", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = get("/filtered", "203.0.113.7")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = get("/filtered", "198.51.100.1")
+	assert.Equal(t, http.StatusForbidden, status)
+}
+
+func TestClientIP_InvalidTrustedProxy(t *testing.T) {
+	err := configureClientIP(gin.New(), config.ServerConfig{TrustedProxies: []string{"10.0.0.0/33"}})
+	assert.Error(t, err)
+}
+
+func setupIPFilterRouter(t *testing.T, public, protected config.IPFilterConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	require.NoError(t, configureClientIP(router, config.ServerConfig{}))
+
+	var publicMiddlewares, protectedMiddlewares []gin.HandlerFunc
+	for _, filter := range []struct {
+		cfg         config.IPFilterConfig
+		middlewares *[]gin.HandlerFunc
+	}{{public, &publicMiddlewares}, {protected, &protectedMiddlewares}} {
+		if filter.cfg.Enabled() {
+			middleware, err := newIPFilterMiddleware(filter.cfg)
+			require.NoError(t, err)
+			*filter.middlewares = append(*filter.middlewares, middleware)
+		}
+	}
+	router.Group("/", publicMiddlewares...).GET("/ping", func(c *gin.Context) { dto.OK(c, "pong") })
+	router.Group("/", protectedMiddlewares...).GET("/internal", func(c *gin.Context) { dto.OK(c, "ok") })
+	return router
+}
+
+func TestIPFilter_RestrictsProtectedGroupToInternalNetworks(t *testing.T) {
+	router := setupIPFilterRouter(t, config.IPFilterConfig{}, config.IPFilterConfig{
+		Allow: []string{"10.0.0.0/8", "fd00::/8", "127.0.0.1"},
+		Deny:  []string{"10.66.0.0/16"},
+	})
+
+	tests := []struct {
+		path, remoteAddr string
+		status           int
+	}{
+		{"/internal", "10.1.2.3:4000", http.StatusOK},
+		{"/internal", "127.0.0.1:4000", http.StatusOK},
+		{"/internal", "[fd00::1]:4000", http.StatusOK},
+		{"/internal", "[::ffff:10.1.2.3]:4000", http.StatusOK},
+		{"/internal", "10.66.1.1:4000", http.StatusForbidden},
+		{"/internal", "203.0.113.7:4000", http.StatusForbidden},
+		{"/ping", "203.0.113.7:4000", http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := clientIPRequest(router, tt.path, tt.remoteAddr, nil)
+		assert.Equal(t, tt.status, w.Code, "%s from %s", tt.path, tt.remoteAddr)
+		if tt.status == http.StatusForbidden {
+			assert.Contains(t, w.Body.String(), string(dto.ErrForbidden))
+		}
+	}
+}
+
+func TestIPFilter_DenyOnly(t *testing.T) {
+	router := setupIPFilterRouter(t, config.IPFilterConfig{Deny: []string{"203.0.113.0/24"}}, config.IPFilterConfig{})
+
+	assert.Equal(t, http.StatusForbidden, clientIPRequest(router, "/ping", "203.0.113.7:4000", nil).Code)
+	assert.Equal(t, http.StatusOK, clientIPRequest(router, "/ping", "198.51.100.1:4000", nil).Code)
+}
+
+func TestIPFilter_InvalidEntries(t *testing.T) {
+	_, err := newIPFilterMiddleware(config.IPFilterConfig{Allow: []string{"10.0.0.0/8", "intranet"}})
+	assert.Error(t, err)
+
+	_, err = newIPFilterMiddleware(config.IPFilterConfig{Deny: []string{"10.0.0.0/40"}})
+	assert.Error(t, err)
+}
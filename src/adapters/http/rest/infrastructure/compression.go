@@ -0,0 +1,322 @@
+package infrastructure
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/pkg/log"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+)
+
+// encoder is a compressing writer that can be pooled.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var encoderPools = map[string]*sync.Pool{
+	"gzip": {New: func() any {
+		w, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return w
+	}},
+	"br": {New: func() any {
+		return brotli.NewWriterLevel(nil, 5)
+	}},
+	"zstd": {New: func() any {
+		w, _ := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault), zstd.WithEncoderConcurrency(1))
+		return w
+	}},
+}
+
+// compressionPolicy is a compiled config.CompressionConfig.
+type compressionPolicy struct {
+	encodings    []string
+	minSize      int
+	exactTypes   map[string]bool
+	typePrefixes []string
+}
+
+// newCompressionMiddleware compresses responses with the encoding negotiated via
+// Accept-Encoding. Responses are buffered up to MinSize to decide whether to compress.
+func newCompressionMiddleware(cfg config.CompressionConfig) gin.HandlerFunc {
+	p := &compressionPolicy{
+		minSize:    cfg.MinSize,
+		exactTypes: map[string]bool{},
+	}
+	for _, encoding := range cfg.Encodings {
+		encoding = strings.ToLower(encoding)
+		if _, supported := encoderPools[encoding]; !supported {
+			log.Warn("Ignoring unsupported compression encoding", log.Fields{"encoding": encoding})
+			continue
+		}
+		p.encodings = append(p.encodings, encoding)
+	}
+	for _, contentType := range cfg.ContentTypes {
+		contentType = strings.ToLower(contentType)
+		if prefix, found := strings.CutSuffix(contentType, "/*"); found {
+			p.typePrefixes = append(p.typePrefixes, prefix+"/")
+		} else {
+			p.exactTypes[contentType] = true
+		}
+	}
+	return p.handle
+}
+
+func (p *compressionPolicy) handle(c *gin.Context) {
+	if c.Request.Method == http.MethodHead || streaming.matched(c) {
+		c.Next()
+		return
+	}
+
+	w := &compressWriter{
+		ResponseWriter: c.Writer,
+		policy:         p,
+		encoding:       negotiateEncoding(c.GetHeader("Accept-Encoding"), p.encodings),
+	}
+	c.Writer = w
+	defer func() {
+		// on panic the buffered body is dropped so recovery can still answer
+		c.Writer = w.ResponseWriter
+	}()
+	c.Next()
+	w.close()
+}
+
+// compressible reports whether contentType is in the allowlist. Event streams never are.
+func (p *compressionPolicy) compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "text/event-stream" {
+		return false
+	}
+	if p.exactTypes[mediaType] {
+		return true
+	}
+	for _, prefix := range p.typePrefixes {
+		if strings.HasPrefix(mediaType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiateEncoding picks the supported encoding with the highest q-value in
+// Accept-Encoding, ties going to the first one in supported.
+func negotiateEncoding(acceptEncoding string, supported []string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+	weights := map[string]float64{}
+	wildcard := 0.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		weight := 1.0
+		for _, param := range fields[1:] {
+			if value, found := strings.CutPrefix(strings.TrimSpace(param), "q="); found {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					weight = parsed
+				}
+			}
+		}
+		switch name {
+		case "*":
+			wildcard = weight
+		case "x-gzip":
+			weights["gzip"] = weight
+		default:
+			weights[name] = weight
+		}
+	}
+
+	best, bestWeight := "", 0.0
+	for _, encoding := range supported {
+		weight, listed := weights[encoding]
+		if !listed {
+			weight = wildcard
+		}
+		if weight > bestWeight {
+			best, bestWeight = encoding, weight
+		}
+	}
+	return best
+}
+
+// compressWriter buffers the start of the body until it can decide whether to
+// compress: enough bytes were written, the handler flushed, or the handler returned.
+type compressWriter struct {
+	gin.ResponseWriter
+	policy    *compressionPolicy
+	encoding  string
+	buf       []byte
+	size      int
+	decided   bool
+	streaming bool
+	encoder   encoder
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	w.size += len(data)
+	if !w.decided {
+		w.buf = append(w.buf, data...)
+		if len(w.buf) < w.policy.minSize {
+			return len(data), nil
+		}
+		return len(data), w.decide()
+	}
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided {
+		_ = w.decide()
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Flush marks the response as streamed: a response not compressed yet is sent as is.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.streaming = true
+		_ = w.decide()
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *compressWriter) Written() bool {
+	return len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+// Size is the uncompressed body size written by the handler.
+func (w *compressWriter) Size() int {
+	if !w.Written() {
+		return -1
+	}
+	return w.size
+}
+
+// decide sets the compression headers when the response qualifies and writes the buffer.
+func (w *compressWriter) decide() error {
+	w.decided = true
+	buf := w.buf
+	w.buf = nil
+
+	if w.shouldCompress(buf) {
+		header := w.Header()
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		header.Del("Accept-Ranges")
+		// the compressed representation is no longer byte-identical to the original
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+		w.encoder = encoderPools[w.encoding].Get().(encoder)
+		w.encoder.Reset(w.ResponseWriter)
+	}
+
+	if len(buf) == 0 {
+		return nil
+	}
+	if w.encoder != nil {
+		_, err := w.encoder.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+func (w *compressWriter) shouldCompress(buf []byte) bool {
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	status := w.Status()
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusPartialContent ||
+		status == http.StatusNotModified {
+		return false
+	}
+	contentType := header.Get("Content-Type")
+	if contentType == "" && len(buf) > 0 {
+		// sniffed here, net/http would otherwise sniff the compressed bytes
+		contentType = http.DetectContentType(buf)
+		header.Set("Content-Type", contentType)
+	}
+	if !w.policy.compressible(contentType) {
+		return false
+	}
+	if !varyContains(header, "Accept-Encoding") {
+		header.Add("Vary", "Accept-Encoding")
+	}
+	return w.encoding != "" && !w.streaming && len(buf) >= w.policy.minSize &&
+		!strings.Contains(header.Get("Cache-Control"), "no-transform")
+}
+
+// close writes what is still buffered and finishes the compressed stream.
+func (w *compressWriter) close() {
+	if !w.decided {
+		_ = w.decide()
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Close()
+		w.encoder.Reset(nil)
+		encoderPools[w.encoding].Put(w.encoder)
+		w.encoder = nil
+	}
+}
+
+func varyContains(header http.Header, name string) bool {
+	for _, value := range header.Values("Vary") {
+		for _, field := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// newDecompressionMiddleware transparently inflates gzip request bodies. maxSize bounds
+// the decompressed size so a small compressed body cannot exhaust memory.
+func newDecompressionMiddleware(maxSize int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		encoding := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding")))
+		if (encoding != "gzip" && encoding != "x-gzip") || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		reader, err := gzip.NewReader(c.Request.Body)
+		if err != nil {
+			dto.AbortWithError(c, http.StatusBadRequest, dto.ErrBadRequest, "request body is not valid gzip")
+			return
+		}
+		defer reader.Close()
+
+		// reads past maxSize fail with *http.MaxBytesError
+		c.Request.Body = http.MaxBytesReader(c.Writer, reader, maxSize)
+		c.Request.Header.Del("Content-Encoding")
+		c.Request.Header.Del("Content-Length")
+		c.Request.ContentLength = -1
+		c.Next()
+	}
+}
@@ -0,0 +1,217 @@
+package infrastructure
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var largeBody = strings.Repeat(`{"message":"pong"}`, 200)
+
+func setupCompressionRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(recoveryMiddleware, newCompressionMiddleware(config.CompressionConfig{
+		Encodings:    []string{"br", "zstd", "gzip"},
+		MinSize:      1024,
+		ContentTypes: []string{"text/*", "application/json"},
+	}))
+	router.GET("/large", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", []byte(largeBody))
+	})
+	router.GET("/small", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", []byte(`{"message":"pong"}`))
+	})
+	router.GET("/image", func(c *gin.Context) {
+		c.Data(http.StatusOK, "image/png", []byte(largeBody))
+	})
+	router.GET("/precompressed", func(c *gin.Context) {
+		c.Header("Content-Encoding", "gzip")
+		c.Data(http.StatusOK, "application/json", []byte(largeBody))
+	})
+	router.GET("/stream", func(c *gin.Context) {
+		c.Header("Content-Type", "text/plain")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("chunk")
+		c.Writer.Flush()
+		_, _ = c.Writer.WriteString(largeBody)
+	})
+	router.GET("/panic", func(c *gin.Context) {
+		_, _ = c.Writer.WriteString("partial")
+		panic("boom")
+	})
+	return router
+}
+
+func compressionRequest(router *gin.Engine, path, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func decode(t *testing.T, encoding string, body io.Reader) string {
+	var reader io.Reader
+	switch encoding {
+	case "gzip":
+		gz, err := gzip.NewReader(body)
+		require.NoError(t, err)
+		reader = gz
+	case "br":
+		reader = brotli.NewReader(body)
+	case "zstd":
+		zr, err := zstd.NewReader(body)
+		require.NoError(t, err)
+		defer zr.Close()
+		reader = zr
+	default:
+		reader = body
+	}
+	decoded, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(decoded)
+}
+
+func TestCompression_Encodings(t *testing.T) {
+	router := setupCompressionRouter()
+
+	for _, encoding := range []string{"gzip", "br", "zstd"} {
+		w := compressionRequest(router, "/large", encoding)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, encoding, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+		assert.Less(t, w.Body.Len(), len(largeBody))
+		assert.Equal(t, largeBody, decode(t, encoding, w.Body), encoding)
+	}
+}
+
+func TestCompression_Skipped(t *testing.T) {
+	router := setupCompressionRouter()
+
+	tests := []struct {
+		name, path, acceptEncoding string
+	}{
+		{"no Accept-Encoding", "/large", ""},
+		{"below min size", "/small", "gzip"},
+		{"content type not allowed", "/image", "gzip"},
+		{"already compressed", "/precompressed", "br"},
+		{"streaming", "/stream", "gzip"},
+		{"encoding refused", "/large", "gzip;q=0, identity"},
+	}
+	for _, tt := range tests {
+		w := compressionRequest(router, tt.path, tt.acceptEncoding)
+		assert.Equal(t, http.StatusOK, w.Code, tt.name)
+		if tt.path != "/precompressed" {
+			assert.Empty(t, w.Header().Get("Content-Encoding"), tt.name)
+		}
+	}
+
+	w := compressionRequest(router, "/stream", "gzip")
+	assert.Equal(t, "chunk"+largeBody, w.Body.String())
+}
+
+func TestCompression_PanicIsRecovered(t *testing.T) {
+	router := setupCompressionRouter()
+
+	w := compressionRequest(router, "/panic", "gzip")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.NotContains(t, w.Body.String(), "partial")
+	assert.Contains(t, w.Body.String(), "INTERNAL_ERROR")
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	supported := []string{"br", "zstd", "gzip"}
+
+	tests := []struct {
+		acceptEncoding, expected string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"gzip, br", "br"},
+		{"gzip;q=1.0, br;q=0.5", "gzip"},
+		{"x-gzip", "gzip"},
+		{"*", "br"},
+		{"*;q=0.1, zstd;q=0.5", "zstd"},
+		{"br;q=0, *", "zstd"},
+		{"deflate, identity", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, negotiateEncoding(tt.acceptEncoding, supported), tt.acceptEncoding)
+	}
+}
+
+func setupDecompressionRouter(maxSize int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(newDecompressionMiddleware(maxSize))
+	router.POST("/echo", func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.Status(http.StatusRequestEntityTooLarge)
+			return
+		}
+		c.String(http.StatusOK, "%s|%s", c.GetHeader("Content-Encoding"), body)
+	})
+	return router
+}
+
+func gzipBody(t *testing.T, content string) *bytes.Buffer {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	return &buf
+}
+
+func TestDecompression(t *testing.T) {
+	router := setupDecompressionRouter(1024)
+
+	req := httptest.NewRequest(http.MethodPost, "/echo", gzipBody(t, `{"name":"gopher"}`))
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `|{"name":"gopher"}`, w.Body.String())
+
+	req = httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("plain"))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "|plain", w.Body.String())
+}
+
+func TestDecompression_Limits(t *testing.T) {
+	router := setupDecompressionRouter(1024)
+
+	// compresses to a few bytes, inflates past the limit
+	req := httptest.NewRequest(http.MethodPost, "/echo", gzipBody(t, strings.Repeat("0", 1<<20)))
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("not gzip"))
+	req.Header.Set("Content-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "BAD_REQUEST")
+}
@@ -0,0 +1,272 @@
+package infrastructure
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	metrics "github.com/penglongli/gin-metrics/ginmetrics"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/pkg/log"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+)
+
+const (
+	metricConcurrencyLimit = "gin_concurrency_limit"
+	metricShedTotal        = "gin_shed_total"
+	// metricRequestDuration is the gin-metrics request latency histogram.
+	metricRequestDuration = "gin_request_duration"
+
+	concurrencyModeStatic   = "static"
+	concurrencyModeAIMD     = "aimd"
+	concurrencyModeGradient = "gradient"
+
+	concurrencyAdjustInterval = time.Second
+	aimdBackoff               = 0.9
+	gradientSmoothing         = 0.2
+	// gradientMinLatencyReset re-measures the no-load latency every this many adjustments.
+	gradientMinLatencyReset = 60
+)
+
+func registerConcurrencyMetrics() {
+	monitor := metrics.GetMonitor()
+	_ = monitor.AddMetric(&metrics.Metric{
+		Type:        metrics.Gauge,
+		Name:        metricConcurrencyLimit,
+		Description: "the current in-flight request limit.",
+		Labels:      []string{"scope"},
+	})
+	_ = monitor.AddMetric(&metrics.Metric{
+		Type:        metrics.Counter,
+		Name:        metricShedTotal,
+		Description: "the server shed requests counter.",
+		Labels:      []string{"scope", "uri"},
+	})
+}
+
+// latencyTotals are the cumulative sum and count of a request duration histogram series.
+type latencyTotals struct {
+	sum   float64
+	count float64
+}
+
+// concurrencyLimits holds the limiters of a server and adjusts the adaptive ones
+// from the gin-metrics request duration histogram.
+type concurrencyLimits struct {
+	cfg      config.ConcurrencyConfig
+	gatherer prometheus.Gatherer
+
+	mu       sync.Mutex
+	limiters []*concurrencyLimiter
+	// shed counts shed requests per URI across limiters: the histogram observes them
+	// with next to no latency, so they are taken out of the latency means.
+	shed map[string]float64
+}
+
+func newConcurrencyLimits(cfg config.ConcurrencyConfig) *concurrencyLimits {
+	return &concurrencyLimits{
+		cfg:      cfg,
+		gatherer: prometheus.DefaultGatherer,
+		shed:     map[string]float64{},
+	}
+}
+
+// limiter returns a middleware capping the in-flight requests of scope at limit.
+// It must run inside the metrics middleware so the requests it admits are observed.
+func (s *concurrencyLimits) limiter(scope string, limit int) gin.HandlerFunc {
+	l := &concurrencyLimiter{
+		limits:        s,
+		scope:         scope,
+		mode:          s.cfg.Mode,
+		minLimit:      int(math.Min(float64(s.cfg.MinLimit), float64(limit))),
+		maxLimit:      limit,
+		latencyTarget: s.cfg.LatencyTarget.Seconds(),
+		retryAfter:    strconv.Itoa(ceilSeconds(s.cfg.RetryAfter)),
+		exempt:        s.cfg.Exempt,
+		limit:         limit,
+		seen:          map[string]bool{},
+		prevTotals:    map[string]latencyTotals{},
+		prevShed:      map[string]float64{},
+	}
+	_ = metrics.GetMonitor().GetMetric(metricConcurrencyLimit).SetGaugeValue([]string{scope}, float64(limit))
+
+	s.mu.Lock()
+	s.limiters = append(s.limiters, l)
+	s.mu.Unlock()
+	return l.handle
+}
+
+// run adjusts the adaptive limiters until the process exits.
+func (s *concurrencyLimits) run() {
+	if s.cfg.Mode == concurrencyModeStatic {
+		return
+	}
+	ticker := time.NewTicker(concurrencyAdjustInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.adjust()
+	}
+}
+
+func (s *concurrencyLimits) adjust() {
+	totals, err := s.latencyTotals()
+	if err != nil {
+		log.Warn("Failed to read request latency, concurrency limits unchanged", log.Fields{"error": err.Error()})
+		return
+	}
+	s.mu.Lock()
+	shed := make(map[string]float64, len(s.shed))
+	for uri, count := range s.shed {
+		shed[uri] = count
+	}
+	limiters := s.limiters
+	s.mu.Unlock()
+
+	for _, l := range limiters {
+		l.adjust(totals, shed)
+	}
+}
+
+// latencyTotals reads the request duration histogram per URI.
+func (s *concurrencyLimits) latencyTotals() (map[string]latencyTotals, error) {
+	families, err := s.gatherer.Gather()
+	if err != nil {
+		return nil, err
+	}
+	totals := map[string]latencyTotals{}
+	for _, family := range families {
+		if family.GetName() != metricRequestDuration {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			var uri string
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "uri" {
+					uri = label.GetValue()
+				}
+			}
+			histogram := metric.GetHistogram()
+			totals[uri] = latencyTotals{sum: histogram.GetSampleSum(), count: float64(histogram.GetSampleCount())}
+		}
+	}
+	return totals, nil
+}
+
+func (s *concurrencyLimits) recordShed(uri string) {
+	s.mu.Lock()
+	s.shed[uri]++
+	s.mu.Unlock()
+}
+
+// concurrencyLimiter caps the in-flight requests of one scope: the server or a route group.
+type concurrencyLimiter struct {
+	limits        *concurrencyLimits
+	scope         string
+	mode          string
+	minLimit      int
+	maxLimit      int
+	latencyTarget float64
+	retryAfter    string
+	exempt        []string
+
+	mu       sync.Mutex
+	limit    int
+	inFlight int
+	// seen are the URIs whose latency drives this limiter.
+	seen        map[string]bool
+	prevTotals  map[string]latencyTotals
+	prevShed    map[string]float64
+	minLatency  float64
+	adjustments int
+}
+
+func (l *concurrencyLimiter) handle(c *gin.Context) {
+	// streams would hold a slot, and skew the latencies, for as long as they stay open
+	if l.exempted(c.Request.URL.Path) || streaming.matched(c) {
+		c.Next()
+		return
+	}
+	uri := c.FullPath()
+	if !l.acquire(uri) {
+		l.limits.recordShed(uri)
+		_ = metrics.GetMonitor().GetMetric(metricShedTotal).Inc([]string{l.scope, uri})
+		c.Header("Retry-After", l.retryAfter)
+		dto.AbortWithError(c, http.StatusServiceUnavailable, dto.ErrServiceUnavail, "The server is overloaded, retry later")
+		return
+	}
+	defer l.release()
+	c.Next()
+}
+
+func (l *concurrencyLimiter) exempted(path string) bool {
+	return exemptedPath(l.exempt, path)
+}
+
+func (l *concurrencyLimiter) acquire(uri string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.seen[uri] = true
+	if l.inFlight >= l.limit {
+		return false
+	}
+	l.inFlight++
+	return true
+}
+
+func (l *concurrencyLimiter) release() {
+	l.mu.Lock()
+	l.inFlight--
+	l.mu.Unlock()
+}
+
+// adjust moves the limit using the mean latency of the URIs it served since the last
+// adjustment. Without completed requests the limit is left as is.
+func (l *concurrencyLimiter) adjust(totals map[string]latencyTotals, shed map[string]float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var sum, count float64
+	for uri := range l.seen {
+		current, previous := totals[uri], l.prevTotals[uri]
+		sum += current.sum - previous.sum
+		count += current.count - previous.count - (shed[uri] - l.prevShed[uri])
+		l.prevTotals[uri] = current
+		l.prevShed[uri] = shed[uri]
+	}
+	if count < 1 || l.mode == concurrencyModeStatic {
+		return
+	}
+	l.limit = l.nextLimit(sum / count)
+	_ = metrics.GetMonitor().GetMetric(metricConcurrencyLimit).SetGaugeValue([]string{l.scope}, float64(l.limit))
+}
+
+// nextLimit applies the adaptive mode to a mean latency in seconds.
+//
+// aimd adds one while the latency is under target and backs off multiplicatively above it.
+// gradient scales the limit by the ratio of the no-load latency to the current one,
+// plus sqrt(limit) of headroom to probe for more capacity, smoothed across adjustments.
+func (l *concurrencyLimiter) nextLimit(latency float64) int {
+	limit := float64(l.limit)
+	switch l.mode {
+	case concurrencyModeAIMD:
+		if latency > l.latencyTarget {
+			limit = math.Floor(limit * aimdBackoff)
+		} else {
+			limit++
+		}
+	case concurrencyModeGradient:
+		if l.minLatency == 0 || latency < l.minLatency || l.adjustments%gradientMinLatencyReset == 0 {
+			l.minLatency = latency
+		}
+		l.adjustments++
+		gradient := math.Max(0.5, math.Min(1, l.minLatency/latency))
+		target := limit*gradient + math.Sqrt(limit)
+		limit = math.Round(limit*(1-gradientSmoothing) + target*gradientSmoothing)
+	}
+	return int(math.Max(float64(l.minLimit), math.Min(float64(l.maxLimit), limit)))
+}
@@ -0,0 +1,172 @@
+package infrastructure
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func testConcurrencyConfig(mode string) config.ConcurrencyConfig {
+	return config.ConcurrencyConfig{
+		Enabled:       true,
+		Mode:          mode,
+		MinLimit:      2,
+		LatencyTarget: 100 * time.Millisecond,
+		RetryAfter:    1500 * time.Millisecond,
+		Exempt:        []string{"/ping", "/health"},
+	}
+}
+
+func TestConcurrencyLimiter_ShedsAboveLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limits := newConcurrencyLimits(testConcurrencyConfig(concurrencyModeStatic))
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	router := gin.New()
+	router.Use(limits.limiter("global", 1))
+	router.GET("/slow", func(c *gin.Context) {
+		started <- struct{}{}
+		<-release
+		dto.OK(c, "done")
+	})
+	router.GET("/fast", func(c *gin.Context) { dto.OK(c, "done") })
+	router.GET("/ping", func(c *gin.Context) { dto.OK(c, "pong") })
+	router.GET("/health/ready", func(c *gin.Context) { dto.OK(c, "ready") })
+	router.GET("/v1/ping", func(c *gin.Context) { dto.OK(c, "pong") })
+	router.GET("/v1/events", func(c *gin.Context) { dto.OK(c, "event") })
+	streaming.add(&router.RouterGroup, http.MethodGet, "/v1/events")
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+	}()
+	<-started
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), string(dto.ErrServiceUnavail))
+
+	for _, path := range []string{"/ping", "/v1/ping", "/health/ready"} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, w.Code, "%s is exempt", path)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/events", nil))
+	assert.Equal(t, http.StatusOK, w.Code, "streaming routes are exempt")
+
+	// request headers do not make a route streaming
+	req := httptest.NewRequest(http.MethodGet, "/fast", nil)
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set("Upgrade", "websocket")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	close(release)
+	wg.Wait()
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	assert.Equal(t, http.StatusOK, w.Code, "the slot is released")
+}
+
+func newTestLimiter(mode string, limit int) *concurrencyLimiter {
+	limits := newConcurrencyLimits(testConcurrencyConfig(mode))
+	limits.limiter("test", limit)
+	l := limits.limiters[0]
+	l.seen["/orders"] = true
+	return l
+}
+
+// observe simulates the histogram totals after count more requests of the given latency.
+func observe(totals map[string]latencyTotals, count int, latency time.Duration) map[string]latencyTotals {
+	current := totals["/orders"]
+	current.count += float64(count)
+	current.sum += float64(count) * latency.Seconds()
+	return map[string]latencyTotals{"/orders": current}
+}
+
+func TestConcurrencyLimiter_AIMD(t *testing.T) {
+	l := newTestLimiter(concurrencyModeAIMD, 20)
+	l.limit = 10
+	totals := map[string]latencyTotals{}
+
+	totals = observe(totals, 10, 50*time.Millisecond)
+	l.adjust(totals, nil)
+	assert.Equal(t, 11, l.limit, "additive increase under the latency target")
+
+	totals = observe(totals, 10, 300*time.Millisecond)
+	l.adjust(totals, nil)
+	assert.Equal(t, 9, l.limit, "multiplicative decrease above the target")
+
+	l.adjust(totals, nil)
+	assert.Equal(t, 9, l.limit, "no requests, no change")
+
+	for i := 0; i < 20; i++ {
+		totals = observe(totals, 10, time.Second)
+		l.adjust(totals, nil)
+	}
+	assert.Equal(t, 2, l.limit, "bounded by the minimum limit")
+}
+
+func TestConcurrencyLimiter_AIMDIgnoresShedRequests(t *testing.T) {
+	l := newTestLimiter(concurrencyModeAIMD, 20)
+	l.limit = 10
+
+	// 2 requests at 300ms and 98 shed ones observed at ~0: the mean is still 300ms
+	totals := observe(map[string]latencyTotals{}, 2, 300*time.Millisecond)
+	totals = observe(totals, 98, 0)
+	l.adjust(totals, map[string]float64{"/orders": 98})
+
+	assert.Equal(t, 9, l.limit)
+}
+
+func TestConcurrencyLimiter_Gradient(t *testing.T) {
+	l := newTestLimiter(concurrencyModeGradient, 100)
+	l.limit = 50
+	totals := map[string]latencyTotals{}
+
+	totals = observe(totals, 10, 20*time.Millisecond)
+	l.adjust(totals, nil)
+	assert.Greater(t, l.limit, 50, "probes for more capacity at the no-load latency")
+
+	before := l.limit
+	for i := 0; i < 5; i++ {
+		totals = observe(totals, 10, 80*time.Millisecond)
+		l.adjust(totals, nil)
+	}
+	assert.Less(t, l.limit, before, "shrinks while latency is above the no-load latency")
+}
+
+func TestConcurrencyLimits_ReadsGinMetricsHistogram(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	histogram := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: metricRequestDuration,
+	}, []string{"uri"})
+	registry.MustRegister(histogram)
+	histogram.WithLabelValues("/orders").Observe(0.5)
+	histogram.WithLabelValues("/orders").Observe(1.5)
+
+	limits := newConcurrencyLimits(testConcurrencyConfig(concurrencyModeAIMD))
+	limits.gatherer = registry
+
+	totals, err := limits.latencyTotals()
+
+	assert.NoError(t, err)
+	assert.Equal(t, latencyTotals{sum: 2, count: 2}, totals["/orders"])
+}
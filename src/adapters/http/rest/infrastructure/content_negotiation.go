@@ -0,0 +1,22 @@
+package infrastructure
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+)
+
+// notAcceptableMiddleware answers 406 before the handler runs when the client accepts
+// none of the dto formats, so unsafe requests are not processed for nothing. Streaming
+// routes are left to their handlers.
+func notAcceptableMiddleware(c *gin.Context) {
+	if !streaming.matched(c) && !dto.Acceptable(c) {
+		dto.AbortWithError(c, http.StatusNotAcceptable, dto.ErrNotAcceptable,
+			"Supported media types: "+strings.Join(dto.MediaTypes(), ", "))
+		return
+	}
+	c.Next()
+}
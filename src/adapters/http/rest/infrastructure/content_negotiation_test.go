@@ -0,0 +1,62 @@
+package infrastructure
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotAcceptableMiddleware_RejectsBeforeHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	called := false
+	router := gin.New()
+	router.Use(notAcceptableMiddleware)
+	router.POST("/orders", func(c *gin.Context) {
+		called = true
+		dto.Created(c, nil)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	req.Header.Set("Accept", "text/html")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+	assert.False(t, called)
+
+	req.Header.Set("Accept", "text/html, application/cbor")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, dto.MIMECBOR, w.Header().Get("Content-Type"))
+}
+
+func TestNotAcceptableMiddleware_LetsEventStreamsThrough(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(notAcceptableMiddleware)
+	router.GET("/events", func(c *gin.Context) {
+		c.String(http.StatusOK, "")
+	})
+	streaming.add(&router.RouterGroup, http.MethodGet, "/events")
+	router.GET("/orders", func(c *gin.Context) {
+		c.String(http.StatusOK, "")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// request headers do not make a route streaming
+	req = httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Upgrade", "websocket")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+}
@@ -0,0 +1,207 @@
+package infrastructure
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+)
+
+const corsRegexPrefix = "regex:"
+
+// corsPolicy is a compiled config.CORSConfig.
+type corsPolicy struct {
+	anyOrigin        bool
+	exactOrigins     map[string]bool
+	wildcardOrigins  []wildcardOrigin
+	regexOrigins     []*regexp.Regexp
+	allowedMethods   map[string]bool
+	methods          string
+	anyHeader        bool
+	allowedHeaders   map[string]bool
+	headers          string
+	exposedHeaders   string
+	allowCredentials bool
+	maxAge           string
+}
+
+// wildcardOrigin matches https://*.example.com style origins.
+type wildcardOrigin struct {
+	scheme string
+	suffix string
+}
+
+// newCORSMiddleware compiles cfg into a middleware. It must run before any
+// authorization middleware so preflight requests are answered without credentials.
+func newCORSMiddleware(cfg config.CORSConfig) (gin.HandlerFunc, error) {
+	policy, err := newCORSPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return policy.handle, nil
+}
+
+func newCORSPolicy(cfg config.CORSConfig) (*corsPolicy, error) {
+	p := &corsPolicy{
+		exactOrigins:     map[string]bool{},
+		allowedMethods:   map[string]bool{},
+		allowedHeaders:   map[string]bool{},
+		allowCredentials: cfg.AllowCredentials,
+		exposedHeaders:   strings.Join(cfg.ExposedHeaders, ", "),
+		maxAge:           strconv.Itoa(cfg.MaxAge),
+	}
+
+	for _, origin := range cfg.AllowedOrigins {
+		switch {
+		case origin == "*":
+			p.anyOrigin = true
+		case strings.HasPrefix(origin, corsRegexPrefix):
+			// anchored so the pattern must match the whole origin, not a prefix or suffix of it
+			expr, err := regexp.Compile("^(?:" + strings.TrimPrefix(origin, corsRegexPrefix) + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid CORS origin pattern %q: %w", origin, err)
+			}
+			p.regexOrigins = append(p.regexOrigins, expr)
+		case strings.Contains(origin, "://*."):
+			parts := strings.SplitN(origin, "://*", 2)
+			p.wildcardOrigins = append(p.wildcardOrigins, wildcardOrigin{
+				scheme: strings.ToLower(parts[0]),
+				suffix: strings.ToLower(parts[1]),
+			})
+		default:
+			p.exactOrigins[strings.ToLower(origin)] = true
+		}
+	}
+
+	methods := make([]string, 0, len(cfg.AllowedMethods))
+	for _, method := range cfg.AllowedMethods {
+		method = strings.ToUpper(method)
+		p.allowedMethods[method] = true
+		methods = append(methods, method)
+	}
+	p.methods = strings.Join(methods, ", ")
+
+	headers := make([]string, 0, len(cfg.AllowedHeaders))
+	for _, header := range cfg.AllowedHeaders {
+		if header == "*" {
+			p.anyHeader = true
+			continue
+		}
+		p.allowedHeaders[http.CanonicalHeaderKey(header)] = true
+		headers = append(headers, http.CanonicalHeaderKey(header))
+	}
+	p.headers = strings.Join(headers, ", ")
+
+	return p, nil
+}
+
+func (p *corsPolicy) handle(c *gin.Context) {
+	origin := c.GetHeader("Origin")
+	preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+	c.Writer.Header().Add("Vary", "Origin")
+	if preflight {
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+	}
+
+	if origin == "" || !p.originAllowed(origin) {
+		if preflight {
+			// answered without CORS headers, the browser blocks the actual request
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+		return
+	}
+
+	if preflight {
+		p.handlePreflight(c, origin)
+		return
+	}
+
+	p.setOriginHeaders(c, origin)
+	if p.exposedHeaders != "" {
+		c.Header("Access-Control-Expose-Headers", p.exposedHeaders)
+	}
+	c.Next()
+}
+
+func (p *corsPolicy) handlePreflight(c *gin.Context, origin string) {
+	method := strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))
+	if !p.allowedMethods[method] {
+		c.AbortWithStatus(http.StatusNoContent)
+		return
+	}
+	requested := c.GetHeader("Access-Control-Request-Headers")
+	if !p.headersAllowed(requested) {
+		c.AbortWithStatus(http.StatusNoContent)
+		return
+	}
+
+	p.setOriginHeaders(c, origin)
+	c.Header("Access-Control-Allow-Methods", p.methods)
+	if p.anyHeader && requested != "" {
+		c.Header("Access-Control-Allow-Headers", requested)
+	} else if p.headers != "" {
+		c.Header("Access-Control-Allow-Headers", p.headers)
+	}
+	c.Header("Access-Control-Max-Age", p.maxAge)
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+func (p *corsPolicy) setOriginHeaders(c *gin.Context, origin string) {
+	if p.anyOrigin && !p.allowCredentials {
+		c.Header("Access-Control-Allow-Origin", "*")
+	} else {
+		// credentials cannot be combined with "*", the origin is echoed instead
+		c.Header("Access-Control-Allow-Origin", origin)
+	}
+	if p.allowCredentials {
+		c.Header("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func (p *corsPolicy) originAllowed(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+	lower := strings.ToLower(origin)
+	if p.exactOrigins[lower] {
+		return true
+	}
+	if len(p.wildcardOrigins) > 0 {
+		if parsed, err := url.Parse(lower); err == nil && parsed.Host != "" {
+			for _, wildcard := range p.wildcardOrigins {
+				if parsed.Scheme == wildcard.scheme && strings.HasSuffix(parsed.Host, wildcard.suffix) {
+					return true
+				}
+			}
+		}
+	}
+	for _, expr := range p.regexOrigins {
+		if expr.MatchString(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *corsPolicy) headersAllowed(requested string) bool {
+	if p.anyHeader || requested == "" {
+		return true
+	}
+	for _, header := range strings.Split(requested, ",") {
+		header = http.CanonicalHeaderKey(strings.TrimSpace(header))
+		if header != "" && !p.allowedHeaders[header] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,168 @@
+package infrastructure
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCORSConfig(origins ...string) config.CORSConfig {
+	return config.CORSConfig{
+		AllowedOrigins: origins,
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Authorization", "Content-Type"},
+		ExposedHeaders: []string{"X-Request-ID"},
+		MaxAge:         600,
+	}
+}
+
+func setupCORSRouter(t *testing.T, public, protected config.CORSConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	publicCORS, err := newCORSMiddleware(public)
+	require.NoError(t, err)
+	protectedCORS, err := newCORSMiddleware(protected)
+	require.NoError(t, err)
+
+	router := gin.New()
+	RegisterHandlersWithOptions(router, handlers.NewRestHandler(), GinServerOptions{
+		BaseURL:           "/",
+		PublicMiddlewares: []gin.HandlerFunc{publicCORS},
+		Middlewares:       []gin.HandlerFunc{protectedCORS, basicAuthorizationMiddleware},
+	})
+	return router
+}
+
+func corsRequest(router *gin.Engine, method, path, origin string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Origin", origin)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	router := setupCORSRouter(t, testCORSConfig("https://app.example.com"), testCORSConfig())
+
+	w := corsRequest(router, http.MethodOptions, "/ping", "https://app.example.com", map[string]string{
+		"Access-Control-Request-Method":  "GET",
+		"Access-Control-Request-Headers": "authorization, content-type",
+	})
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, POST", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Authorization, Content-Type", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+	assert.Contains(t, w.Header().Values("Vary"), "Origin")
+}
+
+func TestCORS_PreflightRejectsUnknownOriginMethodAndHeader(t *testing.T) {
+	router := setupCORSRouter(t, testCORSConfig("https://app.example.com"), testCORSConfig())
+
+	cases := []struct {
+		origin  string
+		headers map[string]string
+	}{
+		{"https://evil.example.org", map[string]string{"Access-Control-Request-Method": "GET"}},
+		{"https://app.example.com", map[string]string{"Access-Control-Request-Method": "DELETE"}},
+		{"https://app.example.com", map[string]string{
+			"Access-Control-Request-Method":  "GET",
+			"Access-Control-Request-Headers": "X-Secret",
+		}},
+	}
+	for _, tc := range cases {
+		w := corsRequest(router, http.MethodOptions, "/ping", tc.origin, tc.headers)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	}
+}
+
+func TestCORS_ActualRequest(t *testing.T) {
+	router := setupCORSRouter(t, testCORSConfig("https://app.example.com"), testCORSConfig())
+
+	w := corsRequest(router, http.MethodGet, "/ping", "https://app.example.com", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "X-Request-ID", w.Header().Get("Access-Control-Expose-Headers"))
+
+	w = corsRequest(router, http.MethodGet, "/ping", "https://other.example.com", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORS_OriginMatching(t *testing.T) {
+	policy, err := newCORSPolicy(testCORSConfig(
+		"https://exact.example.com",
+		"https://*.example.org",
+		`regex:^https://app-[0-9]+\.example\.net$`,
+	))
+	require.NoError(t, err)
+
+	assert.True(t, policy.originAllowed("https://exact.example.com"))
+	assert.True(t, policy.originAllowed("https://EXACT.example.com"))
+	assert.True(t, policy.originAllowed("https://a.b.example.org"))
+	assert.False(t, policy.originAllowed("https://example.org"), "wildcard must not match the apex domain")
+	assert.False(t, policy.originAllowed("http://a.example.org"), "wildcard must match the scheme")
+	assert.False(t, policy.originAllowed("https://evilexample.org"))
+	assert.True(t, policy.originAllowed("https://app-42.example.net"))
+	assert.False(t, policy.originAllowed("https://app-x.example.net"))
+}
+
+func TestCORS_RegexMatchesWholeOrigin(t *testing.T) {
+	policy, err := newCORSPolicy(testCORSConfig(`regex:https://.*\.example\.com`))
+	require.NoError(t, err)
+
+	assert.True(t, policy.originAllowed("https://app.example.com"))
+	assert.False(t, policy.originAllowed("https://evil.example.com.attacker.io"), "suffix spoofing")
+	assert.False(t, policy.originAllowed("http://attacker.io/https://app.example.com"), "prefix spoofing")
+}
+
+func TestCORS_InvalidRegex(t *testing.T) {
+	_, err := newCORSMiddleware(testCORSConfig("regex:("))
+	assert.Error(t, err)
+}
+
+func TestCORS_CredentialsEchoOriginInsteadOfWildcard(t *testing.T) {
+	anyOrigin := testCORSConfig("*")
+	router := setupCORSRouter(t, anyOrigin, anyOrigin)
+
+	w := corsRequest(router, http.MethodGet, "/ping", "https://a.example.com", nil)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+
+	anyOrigin.AllowCredentials = true
+	router = setupCORSRouter(t, anyOrigin, anyOrigin)
+
+	w = corsRequest(router, http.MethodGet, "/ping", "https://a.example.com", nil)
+	assert.Equal(t, "https://a.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestCORS_ProtectedPreflightSkipsAuthorization(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cors, err := newCORSMiddleware(testCORSConfig("https://admin.example.com"))
+	require.NoError(t, err)
+
+	router := gin.New()
+	protected := router.Group("/", cors, basicAuthorizationMiddleware)
+	protected.GET("/secure", func(c *gin.Context) { c.Status(http.StatusOK) })
+	preflightRoutes{}.add(protected, "/secure")
+
+	w := corsRequest(router, http.MethodOptions, "/secure", "https://admin.example.com", map[string]string{
+		"Access-Control-Request-Method": "GET",
+	})
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://admin.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+
+	w = corsRequest(router, http.MethodGet, "/secure", "https://admin.example.com", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "https://admin.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+}
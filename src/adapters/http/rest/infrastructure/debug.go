@@ -0,0 +1,113 @@
+package infrastructure
+
+import (
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+)
+
+// RegisterDebugHandlers mounts pprof and runtime diagnostics under /debug on routes.
+// routes must already be protected (admin listener or the protected group).
+func RegisterDebugHandlers(routes gin.IRoutes) {
+	routes.GET("/debug/pprof/", gin.WrapF(pprof.Index))
+	routes.GET("/debug/pprof/cmdline", gin.WrapF(pprof.Cmdline))
+	routes.GET("/debug/pprof/profile", gin.WrapF(pprof.Profile))
+	routes.POST("/debug/pprof/symbol", gin.WrapF(pprof.Symbol))
+	routes.GET("/debug/pprof/symbol", gin.WrapF(pprof.Symbol))
+	routes.GET("/debug/pprof/trace", gin.WrapF(pprof.Trace))
+	for _, profile := range []string{"allocs", "block", "goroutine", "heap", "mutex", "threadcreate"} {
+		routes.GET("/debug/pprof/"+profile, gin.WrapH(pprof.Handler(profile)))
+	}
+	routes.GET("/debug/runtime", runtimeStats)
+}
+
+type memStats struct {
+	Alloc        uint64  `json:"alloc"`
+	TotalAlloc   uint64  `json:"total_alloc"`
+	Sys          uint64  `json:"sys"`
+	HeapAlloc    uint64  `json:"heap_alloc"`
+	HeapInuse    uint64  `json:"heap_inuse"`
+	HeapObjects  uint64  `json:"heap_objects"`
+	StackInuse   uint64  `json:"stack_inuse"`
+	Mallocs      uint64  `json:"mallocs"`
+	Frees        uint64  `json:"frees"`
+	NextGC       uint64  `json:"next_gc"`
+	GCCPUPercent float64 `json:"gc_cpu_percent"`
+}
+
+type gcStats struct {
+	NumGC      int64  `json:"num_gc"`
+	LastGC     string `json:"last_gc,omitempty"`
+	PauseTotal string `json:"pause_total"`
+	LastPause  string `json:"last_pause,omitempty"`
+}
+
+type buildInfo struct {
+	GoVersion string            `json:"go_version"`
+	Path      string            `json:"path,omitempty"`
+	Version   string            `json:"version,omitempty"`
+	Settings  map[string]string `json:"settings,omitempty"`
+}
+
+type runtimeInfo struct {
+	Goroutines int       `json:"goroutines"`
+	GOMAXPROCS int       `json:"gomaxprocs"`
+	NumCPU     int       `json:"num_cpu"`
+	Memory     memStats  `json:"memory"`
+	GC         gcStats   `json:"gc"`
+	Build      buildInfo `json:"build"`
+}
+
+// runtimeStats reports goroutine, memory, GC and build information.
+func runtimeStats(c *gin.Context) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	var gc debug.GCStats
+	debug.ReadGCStats(&gc)
+
+	info := runtimeInfo{
+		Goroutines: runtime.NumGoroutine(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		NumCPU:     runtime.NumCPU(),
+		Memory: memStats{
+			Alloc:        mem.Alloc,
+			TotalAlloc:   mem.TotalAlloc,
+			Sys:          mem.Sys,
+			HeapAlloc:    mem.HeapAlloc,
+			HeapInuse:    mem.HeapInuse,
+			HeapObjects:  mem.HeapObjects,
+			StackInuse:   mem.StackInuse,
+			Mallocs:      mem.Mallocs,
+			Frees:        mem.Frees,
+			NextGC:       mem.NextGC,
+			GCCPUPercent: mem.GCCPUFraction * 100,
+		},
+		GC: gcStats{
+			NumGC:      gc.NumGC,
+			PauseTotal: gc.PauseTotal.String(),
+		},
+		Build: buildInfo{GoVersion: runtime.Version()},
+	}
+	if !gc.LastGC.IsZero() {
+		info.GC.LastGC = gc.LastGC.UTC().Format(time.RFC3339)
+	}
+	if len(gc.Pause) > 0 {
+		info.GC.LastPause = gc.Pause[0].String()
+	}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		info.Build.Path = build.Main.Path
+		info.Build.Version = build.Main.Version
+		info.Build.Settings = map[string]string{}
+		for _, setting := range build.Settings {
+			info.Build.Settings[setting.Key] = setting.Value
+		}
+	}
+
+	dto.OK(c, info)
+}
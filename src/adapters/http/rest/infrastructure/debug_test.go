@@ -0,0 +1,72 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupDebugRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	RegisterDebugHandlers(router.Group("/", basicAuthorizationMiddleware))
+	return router
+}
+
+func TestDebug_RequiresAuthorization(t *testing.T) {
+	router := setupDebugRouter()
+
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/heap", "/debug/runtime"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code, path)
+	}
+}
+
+func TestDebug_ServesProfilesAndRuntimeStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	RegisterDebugHandlers(router)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/pprof/goroutine?debug=1", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "goroutine profile")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/runtime", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data runtimeInfo `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Greater(t, resp.Data.Goroutines, 0)
+	assert.NotZero(t, resp.Data.Memory.Sys)
+	assert.NotEmpty(t, resp.Data.Build.GoVersion)
+}
+
+func TestNewGinServer_DebugEndpointsToggle(t *testing.T) {
+	os.Setenv("server.debug_endpoints", "false")
+	defer os.Unsetenv("server.debug_endpoints")
+
+	router := NewServer()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/runtime", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDebugEndpoints_DisabledByDefaultInProduction(t *testing.T) {
+	os.Unsetenv("server.debug_endpoints")
+	os.Setenv("environment", "production")
+	defer os.Unsetenv("environment")
+
+	assert.False(t, config.GetServerConfig().DebugEndpoints)
+}
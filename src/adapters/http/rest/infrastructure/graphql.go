@@ -0,0 +1,26 @@
+package infrastructure
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+)
+
+// GraphQLInterface represents the handlers of the GraphQL endpoint.
+type GraphQLInterface interface {
+	Serve(c *gin.Context)
+	Playground(c *gin.Context)
+}
+
+// RegisterGraphQLHandlers mounts the GraphQL endpoint on the protected group and,
+// when enabled, the playground on the public one: the page holds no data and
+// sends the credentials entered in its headers editor.
+func RegisterGraphQLHandlers(public, protected *gin.RouterGroup, gi GraphQLInterface, cfg config.GraphQLConfig) {
+	preflight := preflightRoutes{}
+	protected.GET(cfg.Path, gi.Serve)
+	protected.POST(cfg.Path, gi.Serve)
+	preflight.add(protected, cfg.Path)
+	if cfg.Playground {
+		public.GET(cfg.Path+"/playground", gi.Playground)
+	}
+}
@@ -0,0 +1,46 @@
+package infrastructure
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubGraphQL struct{}
+
+func (stubGraphQL) Serve(c *gin.Context)      { c.Status(http.StatusOK) }
+func (stubGraphQL) Playground(c *gin.Context) { c.Status(http.StatusOK) }
+
+func setupGraphQLRouter(playground bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	cfg := config.GraphQLConfig{Enabled: true, Path: "/graphql", Playground: playground}
+	RegisterGraphQLHandlers(router.Group("/"), router.Group("/", basicAuthorizationMiddleware), stubGraphQL{}, cfg)
+	return router
+}
+
+func TestGraphQL_EndpointRequiresAuthorizationPlaygroundDoesNot(t *testing.T) {
+	router := setupGraphQLRouter(true)
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, "/graphql", nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code, method)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/graphql/playground", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestGraphQL_PlaygroundDisabled(t *testing.T) {
+	router := setupGraphQLRouter(false)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/graphql/playground", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
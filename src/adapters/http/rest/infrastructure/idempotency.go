@@ -0,0 +1,245 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/pkg/log"
+	"github.com/oswaldom-code/api-template-gin/pkg/requestid"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/memory"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/repository"
+	"github.com/oswaldom-code/api-template-gin/src/application/system_services/ports"
+	"github.com/oswaldom-code/api-template-gin/src/domain/entities"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+	idempotencyKeyMaxLength   = 255
+)
+
+// unreplayedHeaders are set per response by other middlewares and never stored
+// for replays, by the idempotency middleware or the response cache.
+var unreplayedHeaders = []string{"Content-Encoding", "Content-Length", "Vary", "Date", requestid.Header}
+
+// newIdempotencyStore builds the configured store, falling back to memory when
+// the database store cannot be initialized.
+func newIdempotencyStore(store string) ports.IdempotencyStore {
+	if store == "postgres" {
+		idempotencyStore, err := repository.NewIdempotencyStore()
+		if err == nil {
+			return idempotencyStore
+		}
+		log.Error("Failed to initialize database idempotency store, using memory", log.Fields{"error": err.Error()})
+	}
+	return memory.NewIdempotencyStore()
+}
+
+type idempotency struct {
+	store ports.IdempotencyStore
+	cfg   config.IdempotencyConfig
+}
+
+// newIdempotencyMiddleware replays the first response of POST and PATCH requests sent
+// with an Idempotency-Key. Keys are scoped to the principal, or the client IP on public
+// routes, and bound to the request fingerprint: reusing one for another payload is a 409.
+// Failed requests (5xx, panics, deadlines) release the key so the client can retry.
+func newIdempotencyMiddleware(store ports.IdempotencyStore, cfg config.IdempotencyConfig) gin.HandlerFunc {
+	i := &idempotency{store: store, cfg: cfg}
+	return i.handle
+}
+
+func (i *idempotency) handle(c *gin.Context) {
+	key := c.GetHeader(idempotencyKeyHeader)
+	method := c.Request.Method
+	if key == "" || (method != http.MethodPost && method != http.MethodPatch) || streaming.matched(c) {
+		c.Next()
+		return
+	}
+	if !validIdempotencyKey(key) {
+		dto.AbortWithError(c, http.StatusBadRequest, dto.ErrBadRequest, "Idempotency-Key must be 1 to 255 printable ASCII characters")
+		return
+	}
+
+	fingerprint, ok := i.fingerprint(c)
+	if !ok {
+		return
+	}
+	storageKey := i.scope(c) + ":" + key
+	record, reserved, err := i.store.Reserve(c.Request.Context(), storageKey, fingerprint, i.cfg.TTL)
+	if err != nil {
+		log.Error("Failed to reserve idempotency key", log.Fields{"error": err.Error()})
+		dto.AbortWithError(c, http.StatusServiceUnavailable, dto.ErrServiceUnavail, "Idempotency-Key could not be checked, retry later")
+		return
+	}
+	if !reserved {
+		i.replay(c, record, fingerprint)
+		return
+	}
+	i.record(c, storageKey)
+}
+
+// scope is the owner of the key: the authenticated principal or the client IP.
+func (i *idempotency) scope(c *gin.Context) string {
+	if principal := c.GetString(principalKey); principal != "" {
+		return "principal:" + principal
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// fingerprint hashes the method, URI and body, restoring the body for the handler.
+func (i *idempotency) fingerprint(c *gin.Context) (string, bool) {
+	var body []byte
+	if c.Request.Body != nil {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(c.Request.Body, i.cfg.MaxBodySize+1))
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) || int64(len(body)) > i.cfg.MaxBodySize {
+			dto.AbortWithError(c, http.StatusRequestEntityTooLarge, dto.ErrBadRequest, "request body is too large for an idempotent request")
+			return "", false
+		}
+		if err != nil {
+			dto.AbortWithError(c, http.StatusBadRequest, dto.ErrBadRequest, "request body could not be read")
+			return "", false
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(c.Request.Method + " " + c.Request.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil)), true
+}
+
+func (i *idempotency) replay(c *gin.Context, record entities.IdempotencyRecord, fingerprint string) {
+	if record.Fingerprint != fingerprint {
+		dto.AbortWithError(c, http.StatusConflict, dto.ErrConflict, "Idempotency-Key was already used with a different request")
+		return
+	}
+	if !record.Completed() {
+		c.Header("Retry-After", "1")
+		dto.AbortWithError(c, http.StatusConflict, dto.ErrConflict, "A request with this Idempotency-Key is still in progress")
+		return
+	}
+
+	c.Header(idempotencyReplayedHeader, "true")
+	writeStoredResponse(c, record.Response.StatusCode, record.Response.Header, record.Response.Body)
+}
+
+// record runs the handler and stores its response, or releases the key when the
+// response should not be replayed.
+func (i *idempotency) record(c *gin.Context, storageKey string) {
+	// the outcome is stored even when the client went away
+	ctx := context.WithoutCancel(c.Request.Context())
+	existing := headerNames(c.Writer.Header())
+	recorder := &recordingWriter{ResponseWriter: c.Writer, limit: i.cfg.MaxBodySize}
+	c.Writer = recorder
+
+	completed := false
+	defer func() {
+		c.Writer = recorder.ResponseWriter
+		if !completed {
+			if err := i.store.Release(ctx, storageKey); err != nil {
+				log.Error("Failed to release idempotency key", log.Fields{"error": err.Error()})
+			}
+		}
+	}()
+	c.Next()
+
+	status := recorder.Status()
+	if status >= http.StatusInternalServerError || recorder.truncated || c.Request.Context().Err() != nil {
+		return
+	}
+	header := storedHeader(recorder.Header(), existing)
+	response := entities.IdempotentResponse{StatusCode: status, Header: header, Body: recorder.body.Bytes()}
+	if err := i.store.Complete(ctx, storageKey, response); err != nil {
+		log.Error("Failed to store idempotent response", log.Fields{"error": err.Error()})
+		return
+	}
+	completed = true
+}
+
+// validIdempotencyKey accepts 1 to 255 printable ASCII characters.
+func validIdempotencyKey(key string) bool {
+	if len(key) > idempotencyKeyMaxLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func headerNames(header http.Header) map[string]bool {
+	names := make(map[string]bool, len(header))
+	for name := range header {
+		names[name] = true
+	}
+	return names
+}
+
+// storedHeader returns the headers the handler set, those not in existing before it
+// ran, without unreplayedHeaders.
+func storedHeader(header http.Header, existing map[string]bool) map[string][]string {
+	stored := map[string][]string{}
+	for name, values := range header {
+		if !existing[name] {
+			stored[name] = values
+		}
+	}
+	for _, name := range unreplayedHeaders {
+		delete(stored, http.CanonicalHeaderKey(name))
+	}
+	return stored
+}
+
+// writeStoredResponse replays a stored response and aborts the chain.
+func writeStoredResponse(c *gin.Context, statusCode int, header map[string][]string, body []byte) {
+	responseHeader := c.Writer.Header()
+	for name, values := range header {
+		responseHeader[name] = values
+	}
+	c.Writer.WriteHeader(statusCode)
+	if len(body) == 0 {
+		c.Writer.WriteHeaderNow()
+	} else {
+		_, _ = c.Writer.Write(body)
+	}
+	c.Abort()
+}
+
+// recordingWriter copies the response body while writing it. Bodies over limit
+// are not kept, the response is then not replayable.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	limit     int64
+	truncated bool
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	if !w.truncated {
+		if int64(w.body.Len()+len(data)) > w.limit {
+			w.truncated = true
+			w.body.Reset()
+		} else {
+			w.body.Write(data)
+		}
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
@@ -0,0 +1,147 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupIdempotencyRouter(calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(principalKey, c.GetHeader("X-Test-Principal"))
+	}, newIdempotencyMiddleware(memory.NewIdempotencyStore(), config.IdempotencyConfig{
+		Enabled: true, TTL: time.Hour, MaxBodySize: 64,
+	}))
+	router.POST("/orders", func(c *gin.Context) {
+		*calls++
+		var body map[string]any
+		if err := c.ShouldBindJSON(&body); err != nil {
+			dto.BadRequest(c, err.Error())
+			return
+		}
+		c.Header("Location", "/orders/1")
+		dto.Created(c, gin.H{"call": *calls})
+	})
+	router.POST("/fail", func(c *gin.Context) {
+		*calls++
+		dto.InternalError(c, "boom")
+	})
+	return router
+}
+
+func idempotentRequest(router *gin.Engine, path, key, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(idempotencyKeyHeader, key)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_ReplaysFirstResponse(t *testing.T) {
+	calls := 0
+	router := setupIdempotencyRouter(&calls)
+
+	first := idempotentRequest(router, "/orders", "k1", `{"item":"a"}`, nil)
+	require.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, first.Header().Get(idempotencyReplayedHeader))
+
+	second := idempotentRequest(router, "/orders", "k1", `{"item":"a"}`, nil)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, "true", second.Header().Get(idempotencyReplayedHeader))
+	assert.Equal(t, "/orders/1", second.Header().Get("Location"))
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, 1, calls)
+}
+
+func TestIdempotency_StreamingHeadersDoNotSkipTheKey(t *testing.T) {
+	calls := 0
+	router := setupIdempotencyRouter(&calls)
+
+	first := idempotentRequest(router, "/orders", "k1", `{"item":"a"}`, nil)
+	require.Equal(t, http.StatusCreated, first.Code)
+	second := idempotentRequest(router, "/orders", "k1", `{"item":"a"}`, map[string]string{
+		"Accept":  "application/json, text/event-stream",
+		"Upgrade": "websocket",
+	})
+	assert.Equal(t, "true", second.Header().Get(idempotencyReplayedHeader))
+	assert.Equal(t, 1, calls)
+}
+
+func TestIdempotency_ConflictOnDifferentPayload(t *testing.T) {
+	calls := 0
+	router := setupIdempotencyRouter(&calls)
+
+	idempotentRequest(router, "/orders", "k1", `{"item":"a"}`, nil)
+	w := idempotentRequest(router, "/orders", "k1", `{"item":"b"}`, nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var resp dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, dto.ErrConflict, resp.Error.Code)
+	assert.Equal(t, 1, calls)
+}
+
+func TestIdempotency_KeysAreScopedToPrincipal(t *testing.T) {
+	calls := 0
+	router := setupIdempotencyRouter(&calls)
+
+	idempotentRequest(router, "/orders", "k1", `{"item":"a"}`, map[string]string{"X-Test-Principal": "alice"})
+	w := idempotentRequest(router, "/orders", "k1", `{"item":"b"}`, map[string]string{"X-Test-Principal": "bob"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotency_ServerErrorsReleaseTheKey(t *testing.T) {
+	calls := 0
+	router := setupIdempotencyRouter(&calls)
+
+	assert.Equal(t, http.StatusInternalServerError, idempotentRequest(router, "/fail", "k1", `{}`, nil).Code)
+	assert.Equal(t, http.StatusInternalServerError, idempotentRequest(router, "/fail", "k1", `{}`, nil).Code)
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotency_IgnoresRequestsWithoutKey(t *testing.T) {
+	calls := 0
+	router := setupIdempotencyRouter(&calls)
+
+	idempotentRequest(router, "/orders", "", `{"item":"a"}`, nil)
+	w := idempotentRequest(router, "/orders", "", `{"item":"a"}`, nil)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Header().Get(idempotencyReplayedHeader))
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotency_RejectsInvalidRequests(t *testing.T) {
+	calls := 0
+	router := setupIdempotencyRouter(&calls)
+
+	assert.Equal(t, http.StatusBadRequest, idempotentRequest(router, "/orders", strings.Repeat("k", 256), `{}`, nil).Code)
+	assert.Equal(t, http.StatusBadRequest, idempotentRequest(router, "/orders", "k\x01", `{}`, nil).Code)
+	assert.Equal(t, http.StatusRequestEntityTooLarge,
+		idempotentRequest(router, "/orders", "k1", `{"item":"`+strings.Repeat("a", 64)+`"}`, nil).Code)
+	assert.Equal(t, 0, calls)
+}
+
+func TestValidIdempotencyKey(t *testing.T) {
+	assert.True(t, validIdempotencyKey("8e03978e-40d5-43e8-bc93-6894a57f9324"))
+	assert.False(t, validIdempotencyKey("café"))
+	assert.False(t, validIdempotencyKey(strings.Repeat("k", 256)))
+}
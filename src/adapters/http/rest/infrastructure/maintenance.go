@@ -0,0 +1,98 @@
+package infrastructure
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/netip"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+	Service "github.com/oswaldom-code/api-template-gin/src/application/system_services"
+)
+
+// maintenancePath is where maintenance mode is read and toggled.
+const maintenancePath = "/maintenance"
+
+// MaintenanceInterface represents the handlers toggling maintenance mode.
+type MaintenanceInterface interface {
+	MaintenanceStatus(c *gin.Context)
+	SetMaintenance(c *gin.Context)
+}
+
+// RegisterMaintenanceHandlers mounts the maintenance mode endpoint on routes.
+// routes must already be protected (admin listener or the protected group).
+func RegisterMaintenanceHandlers(routes gin.IRoutes, mi MaintenanceInterface) {
+	routes.GET(maintenancePath, mi.MaintenanceStatus)
+	routes.PUT(maintenancePath, mi.SetMaintenance)
+}
+
+type maintenanceGate struct {
+	maintenance Service.Maintenance
+	cfg         config.MaintenanceConfig
+	allowIPs    []netip.Prefix
+	// allowKeys are credential fingerprints of cfg.AllowAPIKeys
+	allowKeys []string
+}
+
+// newMaintenanceMiddleware answers 503 SERVICE_UNAVAILABLE while maintenance mode is on,
+// except on the exempt paths, with or without a version prefix, and for the allowed
+// client IPs and API keys.
+func newMaintenanceMiddleware(maintenance Service.Maintenance, cfg config.MaintenanceConfig) (gin.HandlerFunc, error) {
+	allowIPs, err := parsePrefixes(cfg.AllowIPs)
+	if err != nil {
+		return nil, err
+	}
+	g := &maintenanceGate{maintenance: maintenance, cfg: cfg, allowIPs: allowIPs}
+	for _, key := range cfg.AllowAPIKeys {
+		g.allowKeys = append(g.allowKeys, credentialFingerprint(key))
+	}
+	return g.handle, nil
+}
+
+func (g *maintenanceGate) handle(c *gin.Context) {
+	mode := g.maintenance.Status()
+	if !mode.Enabled || g.exempted(c.Request.URL.Path) || g.allowed(c) {
+		c.Next()
+		return
+	}
+	message, retryAfter := mode.Message, mode.RetryAfter
+	if message == "" {
+		message = g.cfg.Message
+	}
+	if retryAfter == 0 {
+		retryAfter = g.cfg.RetryAfter
+	}
+	if retryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+	}
+	dto.AbortWithError(c, http.StatusServiceUnavailable, dto.ErrServiceUnavail, message)
+}
+
+func (g *maintenanceGate) exempted(path string) bool {
+	return exemptedPath(g.cfg.Exempt, path)
+}
+
+func (g *maintenanceGate) allowed(c *gin.Context) bool {
+	if len(g.allowIPs) > 0 {
+		if addr, err := netip.ParseAddr(c.ClientIP()); err == nil {
+			addr = addr.Unmap()
+			for _, prefix := range g.allowIPs {
+				if prefix.Contains(addr) {
+					return true
+				}
+			}
+		}
+	}
+	if apiKey := c.GetHeader(g.cfg.APIKeyHeader); apiKey != "" && len(g.allowKeys) > 0 {
+		fingerprint := credentialFingerprint(apiKey)
+		for _, key := range g.allowKeys {
+			if subtle.ConstantTimeCompare([]byte(fingerprint), []byte(key)) == 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+	Service "github.com/oswaldom-code/api-template-gin/src/application/system_services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupMaintenanceRouter(t *testing.T, maintenance Service.Maintenance, cfg config.MaintenanceConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	middleware, err := newMaintenanceMiddleware(maintenance, cfg)
+	require.NoError(t, err)
+	router.Use(middleware)
+	for _, path := range []string{"/ping", "/v1/ping", "/v1/orders", "/health/ready", "/metrics"} {
+		router.GET(path, func(c *gin.Context) {
+			c.String(http.StatusOK, "ok")
+		})
+	}
+	return router
+}
+
+func maintenanceConfig() config.MaintenanceConfig {
+	return config.MaintenanceConfig{
+		Message:      "Down for maintenance",
+		RetryAfter:   90 * time.Second,
+		APIKeyHeader: "X-API-Key",
+		Exempt:       []string{"/ping", "/health", "/metrics"},
+	}
+}
+
+func TestMaintenance_Disabled(t *testing.T) {
+	router := setupMaintenanceRouter(t, Service.NewMaintenance(), maintenanceConfig())
+
+	w := clientIPRequest(router, "/v1/orders", "198.51.100.9:4000", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestMaintenance_Returns503WithRetryAfter(t *testing.T) {
+	maintenance := Service.NewMaintenance()
+	maintenance.Enable("", 0)
+	router := setupMaintenanceRouter(t, maintenance, maintenanceConfig())
+
+	w := clientIPRequest(router, "/v1/orders", "198.51.100.9:4000", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "90", w.Header().Get("Retry-After"))
+	var resp dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, dto.ErrServiceUnavail, resp.Error.Code)
+	assert.Equal(t, "Down for maintenance", resp.Error.Message)
+
+	maintenance.Enable("Migrating the database", 2*time.Minute)
+	w = clientIPRequest(router, "/v1/orders", "198.51.100.9:4000", nil)
+	assert.Equal(t, "120", w.Header().Get("Retry-After"))
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "Migrating the database", resp.Error.Message)
+}
+
+func TestMaintenance_ExemptPaths(t *testing.T) {
+	maintenance := Service.NewMaintenance()
+	maintenance.Enable("", 0)
+	router := setupMaintenanceRouter(t, maintenance, maintenanceConfig())
+
+	for _, path := range []string{"/ping", "/v1/ping", "/health/ready", "/metrics"} {
+		w := clientIPRequest(router, path, "198.51.100.9:4000", nil)
+		assert.Equal(t, http.StatusOK, w.Code, path)
+	}
+}
+
+func TestMaintenance_AllowedClients(t *testing.T) {
+	maintenance := Service.NewMaintenance()
+	maintenance.Enable("", 0)
+	cfg := maintenanceConfig()
+	cfg.AllowIPs = []string{"10.0.0.0/8"}
+	cfg.AllowAPIKeys = []string{"operator-key"}
+	router := setupMaintenanceRouter(t, maintenance, cfg)
+
+	w := clientIPRequest(router, "/v1/orders", "10.1.2.3:4000", nil)
+	assert.Equal(t, http.StatusOK, w.Code, "allowed IP")
+
+	w = clientIPRequest(router, "/v1/orders", "198.51.100.9:4000", map[string]string{"X-API-Key": "operator-key"})
+	assert.Equal(t, http.StatusOK, w.Code, "allowed API key")
+
+	w = clientIPRequest(router, "/v1/orders", "198.51.100.9:4000", map[string]string{"X-API-Key": "other-key"})
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestMaintenance_InvalidAllowIP(t *testing.T) {
+	cfg := maintenanceConfig()
+	cfg.AllowIPs = []string{"not-an-ip"}
+
+	_, err := newMaintenanceMiddleware(Service.NewMaintenance(), cfg)
+	assert.Error(t, err)
+}
@@ -0,0 +1,126 @@
+package infrastructure
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/pkg/log"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/memory"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/repository"
+	"github.com/oswaldom-code/api-template-gin/src/application/system_services/ports"
+	"github.com/oswaldom-code/api-template-gin/src/domain/entities"
+)
+
+const (
+	rateLimitByIP        = "ip"
+	rateLimitByAPIKey    = "api_key"
+	rateLimitByPrincipal = "principal"
+)
+
+type rateLimiter struct {
+	store        ports.RateLimitStore
+	group        string
+	policy       entities.RateLimitPolicy
+	routes       map[string]entities.RateLimitPolicy
+	keyBy        string
+	apiKeyHeader string
+	// route returns the route pattern routes are keyed by
+	route func(c *gin.Context) string
+}
+
+// newRateLimitStore builds the configured store, falling back to memory when
+// the database store cannot be initialized.
+func newRateLimitStore(store string) ports.RateLimitStore {
+	if store == "postgres" {
+		rateLimitStore, err := repository.NewRateLimitStore()
+		if err == nil {
+			return rateLimitStore
+		}
+		log.Error("Failed to initialize database rate limit store, using memory", log.Fields{"error": err.Error()})
+	}
+	return memory.NewRateLimitStore()
+}
+
+// newRateLimitMiddleware limits requests per client key. Route limits in cfg
+// take precedence over the group limit and are counted separately. route returns the
+// pattern of the matched route, e.g. apiVersions.route.
+func newRateLimitMiddleware(store ports.RateLimitStore, group string, cfg config.RateLimitConfig, route func(c *gin.Context) string) gin.HandlerFunc {
+	algorithm := entities.RateLimitAlgorithm(cfg.Algorithm)
+	limiter := &rateLimiter{
+		store:        store,
+		group:        group,
+		policy:       entities.RateLimitPolicy{Algorithm: algorithm, Limit: cfg.Limit, Window: cfg.Window},
+		routes:       map[string]entities.RateLimitPolicy{},
+		keyBy:        cfg.KeyBy,
+		apiKeyHeader: cfg.APIKeyHeader,
+		route:        route,
+	}
+	for _, route := range cfg.Routes {
+		limiter.routes[route.Method+" "+route.Path] = entities.RateLimitPolicy{
+			Algorithm: algorithm,
+			Limit:     route.Limit,
+			Window:    route.Window,
+		}
+	}
+	return limiter.handle
+}
+
+func (l *rateLimiter) handle(c *gin.Context) {
+	policy, scope := l.policy, l.group
+	route := c.Request.Method + " " + l.route(c)
+	if routePolicy, ok := l.routes[route]; ok {
+		policy, scope = routePolicy, l.group+":"+route
+	}
+
+	decision, err := l.store.Allow(c.Request.Context(), scope+":"+l.clientKey(c), policy)
+	if err != nil {
+		// a failing store must not take the API down
+		log.Warn("Rate limit store failed, allowing request", log.ContextFields(c.Request.Context(), log.Fields{
+			"error": err.Error(),
+		}))
+		c.Next()
+		return
+	}
+
+	c.Header("RateLimit-Limit", strconv.Itoa(decision.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.Reset)))
+	c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, ceilSeconds(policy.Window)))
+
+	if !decision.Allowed {
+		retryAfter := ceilSeconds(decision.RetryAfter)
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		dto.TooManyRequests(c, "Rate limit exceeded, retry later")
+		return
+	}
+	c.Next()
+}
+
+// clientKey identifies the caller, falling back to the client IP when the
+// configured credential is missing.
+func (l *rateLimiter) clientKey(c *gin.Context) string {
+	switch l.keyBy {
+	case rateLimitByAPIKey:
+		if apiKey := c.GetHeader(l.apiKeyHeader); apiKey != "" {
+			return "key:" + credentialFingerprint(apiKey)
+		}
+	case rateLimitByPrincipal:
+		if principal := c.GetString(principalKey); principal != "" {
+			return "principal:" + principal
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,117 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/memory"
+	"github.com/oswaldom-code/api-template-gin/src/domain/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Allow(context.Context, string, entities.RateLimitPolicy) (entities.RateLimitDecision, error) {
+	return entities.RateLimitDecision{}, errors.New("store down")
+}
+
+func setupRateLimitRouter(cfg config.RateLimitConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(newRateLimitMiddleware(memory.NewRateLimitStore(), "public", cfg, (*gin.Context).FullPath))
+	router.GET("/ping", func(c *gin.Context) { dto.OK(c, gin.H{"ping": "pong"}) })
+	router.GET("/search", func(c *gin.Context) { dto.OK(c, nil) })
+	return router
+}
+
+func rateLimitRequest(router *gin.Engine, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimit_RejectsWith429AndHeaders(t *testing.T) {
+	router := setupRateLimitRouter(config.RateLimitConfig{
+		Algorithm: "token_bucket", Limit: 2, Window: time.Minute, KeyBy: "ip",
+	})
+
+	w := rateLimitRequest(router, "/ping", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "2;w=60", w.Header().Get("RateLimit-Policy"))
+
+	rateLimitRequest(router, "/ping", nil)
+	w = rateLimitRequest(router, "/ping", nil)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+
+	var resp dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, dto.ErrRateLimited, resp.Error.Code)
+}
+
+func TestRateLimit_RouteLimitOverridesGroupLimit(t *testing.T) {
+	router := setupRateLimitRouter(config.RateLimitConfig{
+		Algorithm: "sliding_window", Limit: 100, Window: time.Minute, KeyBy: "ip",
+		Routes: []config.RateLimitRoute{{Method: "GET", Path: "/search", Limit: 1, Window: time.Minute}},
+	})
+
+	assert.Equal(t, http.StatusOK, rateLimitRequest(router, "/search", nil).Code)
+	assert.Equal(t, http.StatusTooManyRequests, rateLimitRequest(router, "/search", nil).Code)
+	assert.Equal(t, http.StatusOK, rateLimitRequest(router, "/ping", nil).Code)
+}
+
+func TestRateLimit_KeysByAPIKey(t *testing.T) {
+	router := setupRateLimitRouter(config.RateLimitConfig{
+		Algorithm: "token_bucket", Limit: 1, Window: time.Minute, KeyBy: "api_key", APIKeyHeader: "X-API-Key",
+	})
+
+	assert.Equal(t, http.StatusOK, rateLimitRequest(router, "/ping", map[string]string{"X-API-Key": "a"}).Code)
+	assert.Equal(t, http.StatusTooManyRequests, rateLimitRequest(router, "/ping", map[string]string{"X-API-Key": "a"}).Code)
+	assert.Equal(t, http.StatusOK, rateLimitRequest(router, "/ping", map[string]string{"X-API-Key": "b"}).Code)
+	// no key falls back to the client IP
+	assert.Equal(t, http.StatusOK, rateLimitRequest(router, "/ping", nil).Code)
+}
+
+func TestRateLimit_KeysByPrincipal(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(principalKey, c.GetHeader("X-Test-Principal"))
+	}, newRateLimitMiddleware(memory.NewRateLimitStore(), "protected", config.RateLimitConfig{
+		Algorithm: "token_bucket", Limit: 1, Window: time.Minute, KeyBy: "principal",
+	}, (*gin.Context).FullPath))
+	router.GET("/ping", func(c *gin.Context) { dto.OK(c, nil) })
+
+	alice := map[string]string{"X-Test-Principal": "alice"}
+	assert.Equal(t, http.StatusOK, rateLimitRequest(router, "/ping", alice).Code)
+	assert.Equal(t, http.StatusTooManyRequests, rateLimitRequest(router, "/ping", alice).Code)
+	assert.Equal(t, http.StatusOK, rateLimitRequest(router, "/ping", map[string]string{"X-Test-Principal": "bob"}).Code)
+}
+
+func TestRateLimit_FailsOpenWhenStoreFails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(newRateLimitMiddleware(failingRateLimitStore{}, "public", config.RateLimitConfig{
+		Algorithm: "token_bucket", Limit: 1, Window: time.Minute, KeyBy: "ip",
+	}, (*gin.Context).FullPath))
+	router.GET("/ping", func(c *gin.Context) { dto.OK(c, nil) })
+
+	assert.Equal(t, http.StatusOK, rateLimitRequest(router, "/ping", nil).Code)
+}
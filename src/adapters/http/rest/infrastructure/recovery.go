@@ -0,0 +1,107 @@
+package infrastructure
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strings"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+	metrics "github.com/penglongli/gin-metrics/ginmetrics"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/log"
+	"github.com/oswaldom-code/api-template-gin/pkg/requestid"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+)
+
+const metricPanicTotal = "gin_panic_total"
+
+func registerPanicMetric() {
+	// AddMetric fails when the metric already exists, which is fine on repeated setup
+	_ = metrics.GetMonitor().AddMetric(&metrics.Metric{
+		Type:        metrics.Counter,
+		Name:        metricPanicTotal,
+		Description: "the server recovered panics counter.",
+		Labels:      []string{"uri", "method"},
+	})
+}
+
+// recoveryMiddleware replaces gin.Recovery: it logs the panic with its stack,
+// counts it and answers with the standard error envelope carrying an incident ID.
+// The stack is only included in the response in debug mode.
+func recoveryMiddleware(c *gin.Context) {
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+		if recovered == http.ErrAbortHandler {
+			// deliberate abort, let net/http close the connection
+			panic(recovered)
+		}
+
+		stack := string(debug.Stack())
+		if handlerPanic, ok := recovered.(*handlerPanic); ok {
+			// raised again by a middleware running the handler on another goroutine
+			recovered, stack = handlerPanic.value, handlerPanic.stack
+		}
+		incidentID := requestid.New()
+		_ = metrics.GetMonitor().GetMetric(metricPanicTotal).Inc([]string{c.FullPath(), c.Request.Method})
+
+		log.Error("Panic recovered", log.ContextFields(c.Request.Context(), log.Fields{
+			"incident_id": incidentID,
+			"panic":       fmt.Sprint(recovered),
+			"method":      c.Request.Method,
+			"path":        c.Request.URL.Path,
+			"stack":       stack,
+		}))
+
+		if isBrokenPipe(recovered) || c.Writer.Written() {
+			// the client is gone or the response already started, nothing can be sent
+			c.Abort()
+			return
+		}
+
+		detail := dto.ErrorDetail{
+			Code:       dto.ErrInternalServer,
+			Message:    "An unexpected error occurred",
+			IncidentID: incidentID,
+		}
+		if gin.IsDebugging() {
+			detail.Message = fmt.Sprintf("panic: %v", recovered)
+			detail.Stack = stack
+		}
+		dto.AbortWithErrorDetail(c, http.StatusInternalServerError, detail)
+	}()
+	c.Next()
+}
+
+// handlerPanic carries a panic recovered on another goroutine with its original stack.
+type handlerPanic struct {
+	value any
+	stack string
+}
+
+// isBrokenPipe reports whether the panic was caused by the client closing the connection.
+func isBrokenPipe(recovered any) bool {
+	err, ok := recovered.(error)
+	if !ok {
+		return false
+	}
+	if errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		var sysErr *os.SyscallError
+		if errors.As(opErr.Err, &sysErr) {
+			message := strings.ToLower(sysErr.Error())
+			return strings.Contains(message, "broken pipe") || strings.Contains(message, "connection reset by peer")
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oswaldom-code/api-template-gin/pkg/requestid"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRecoveryRouter(mode string) *gin.Engine {
+	gin.SetMode(mode)
+	registerPanicMetric()
+	router := gin.New()
+	router.Use(requestIDMiddleware, recoveryMiddleware)
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	router.GET("/abort", func(c *gin.Context) {
+		panic(http.ErrAbortHandler)
+	})
+	return router
+}
+
+func TestRecovery_ReturnsErrorEnvelopeWithIncidentID(t *testing.T) {
+	router := setupRecoveryRouter(gin.ReleaseMode)
+	defer gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var resp dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, dto.ErrInternalServer, resp.Error.Code)
+	assert.NotEmpty(t, resp.Error.IncidentID)
+	assert.Empty(t, resp.Error.Stack, "stack must not leak outside debug mode")
+	assert.NotContains(t, resp.Error.Message, "boom")
+	assert.Equal(t, w.Header().Get(requestid.Header), resp.Meta.RequestID)
+}
+
+func TestRecovery_IncludesStackInDebugMode(t *testing.T) {
+	router := setupRecoveryRouter(gin.DebugMode)
+	defer gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	var resp dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Contains(t, resp.Error.Message, "boom")
+	assert.Contains(t, resp.Error.Stack, "recovery_test.go")
+}
+
+func TestRecovery_CountsPanics(t *testing.T) {
+	router := setupRecoveryRouter(gin.TestMode)
+	exposeMetrics(router)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, w.Body.String(), `gin_panic_total{method="GET",uri="/panic"}`)
+}
+
+func TestRecovery_RepanicsOnAbortHandler(t *testing.T) {
+	router := setupRecoveryRouter(gin.TestMode)
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+	})
+}
@@ -0,0 +1,49 @@
+package infrastructure
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/requestid"
+)
+
+// requestIDMiddleware reuses a valid inbound X-Request-ID or generates a new one,
+// stores it in the gin and request contexts and echoes it in the response.
+func requestIDMiddleware(c *gin.Context) {
+	id := c.GetHeader(requestid.Header)
+	if !requestid.IsValid(id) {
+		id = requestid.New()
+	}
+
+	c.Set(requestid.Key, id)
+	c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), id))
+	c.Header(requestid.Header, id)
+	c.Next()
+}
+
+// accessLogFormatter is gin's default access log line with the request ID appended.
+func accessLogFormatter(param gin.LogFormatterParams) string {
+	var statusColor, methodColor, resetColor string
+	if param.IsOutputColor() {
+		statusColor = param.StatusCodeColor()
+		methodColor = param.MethodColor()
+		resetColor = param.ResetColor()
+	}
+	if param.Latency > time.Minute {
+		param.Latency = param.Latency.Truncate(time.Second)
+	}
+	requestID, _ := param.Keys[requestid.Key].(string)
+
+	return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v | %s\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		statusColor, param.StatusCode, resetColor,
+		param.Latency,
+		param.ClientIP,
+		methodColor, param.Method, resetColor,
+		param.Path,
+		requestID,
+		param.ErrorMessage,
+	)
+}
@@ -0,0 +1,70 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oswaldom-code/api-template-gin/pkg/requestid"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRequestIDRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(requestIDMiddleware)
+	router.GET("/ping", func(c *gin.Context) {
+		assert.Equal(t, c.GetString(requestid.Key), requestid.FromContext(c.Request.Context()))
+		dto.OK(c, gin.H{"ping": "pong"})
+	})
+	router.GET("/fail", func(c *gin.Context) {
+		dto.BadRequest(c, "invalid input")
+	})
+	return router
+}
+
+func TestRequestID_GeneratedWhenMissing(t *testing.T) {
+	router := setupRequestIDRouter(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ping", nil))
+
+	id := w.Header().Get(requestid.Header)
+	require.NotEmpty(t, id)
+
+	var resp dto.SuccessResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, id, resp.Meta.RequestID)
+}
+
+func TestRequestID_PropagatesInboundHeader(t *testing.T) {
+	router := setupRequestIDRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+	req.Header.Set(requestid.Header, "client-req-42")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "client-req-42", w.Header().Get(requestid.Header))
+
+	var resp dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "client-req-42", resp.Meta.RequestID)
+}
+
+func TestRequestID_ReplacesInvalidInboundHeader(t *testing.T) {
+	router := setupRequestIDRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(requestid.Header, "bad id\r\nX-Injected: 1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	id := w.Header().Get(requestid.Header)
+	assert.True(t, requestid.IsValid(id))
+	assert.NotContains(t, id, "bad")
+}
@@ -0,0 +1,260 @@
+package infrastructure
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	metrics "github.com/penglongli/gin-metrics/ginmetrics"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/pkg/log"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+	"github.com/oswaldom-code/api-template-gin/src/application/system_services/ports"
+	"github.com/oswaldom-code/api-template-gin/src/domain/entities"
+)
+
+const (
+	metricCacheHitTotal  = "gin_cache_hit_total"
+	metricCacheMissTotal = "gin_cache_miss_total"
+
+	cacheStatusHeader = "X-Cache"
+)
+
+// cacheableStatus are the statuses cacheable by default (RFC 9111 section 4.2.2).
+var cacheableStatus = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusPermanentRedirect:    true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+}
+
+func registerResponseCacheMetrics() {
+	monitor := metrics.GetMonitor()
+	_ = monitor.AddMetric(&metrics.Metric{
+		Type:        metrics.Counter,
+		Name:        metricCacheHitTotal,
+		Description: "the responses served from the response cache counter.",
+		Labels:      []string{"uri"},
+	})
+	_ = monitor.AddMetric(&metrics.Metric{
+		Type:        metrics.Counter,
+		Name:        metricCacheMissTotal,
+		Description: "the cacheable requests run by their handler counter.",
+		Labels:      []string{"uri"},
+	})
+}
+
+// unmatchedCacheRoutes returns the paths of cacheRoutes no GET route of routes has.
+// Cache routes are looked up by route pattern, versioned ones included: "/v1/ping".
+func unmatchedCacheRoutes(routes gin.RoutesInfo, cacheRoutes []config.CacheRoute) []string {
+	registered := map[string]bool{}
+	for _, route := range routes {
+		if route.Method == http.MethodGet {
+			registered[route.Path] = true
+		}
+	}
+	unmatched := []string{}
+	for _, route := range cacheRoutes {
+		if !registered[route.Path] {
+			unmatched = append(unmatched, route.Path)
+		}
+	}
+	return unmatched
+}
+
+// cacheFlight is a handler call for a key that concurrent misses wait for.
+type cacheFlight struct {
+	done     chan struct{}
+	response entities.CachedResponse
+	cached   bool
+}
+
+type responseCache struct {
+	cache  ports.ResponseCache
+	cfg    config.ResponseCacheConfig
+	routes map[string]time.Duration
+	// route returns the route pattern routes are keyed by
+	route func(c *gin.Context) string
+	now   func() time.Time
+
+	mu      sync.Mutex
+	flights map[string]*cacheFlight
+}
+
+// newResponseCacheMiddleware serves the configured GET routes from cache. The TTL comes
+// from the response Cache-Control (s-maxage, then max-age) or the route default;
+// no-store, no-cache, Set-Cookie and private responses without a principal in the
+// key are not stored. Concurrent misses for a key wait for a single handler call.
+// route returns the pattern of the matched route, e.g. apiVersions.route.
+func newResponseCacheMiddleware(cache ports.ResponseCache, cfg config.ResponseCacheConfig, route func(c *gin.Context) string) gin.HandlerFunc {
+	r := &responseCache{
+		cache:   cache,
+		cfg:     cfg,
+		routes:  map[string]time.Duration{},
+		route:   route,
+		now:     time.Now,
+		flights: map[string]*cacheFlight{},
+	}
+	for _, route := range cfg.Routes {
+		r.routes[route.Path] = route.TTL
+	}
+	return r.handle
+}
+
+func (r *responseCache) handle(c *gin.Context) {
+	routeTTL, cacheable := r.routes[r.route(c)]
+	if c.Request.Method != http.MethodGet || !cacheable {
+		c.Next()
+		return
+	}
+	requestDirectives := cacheControl(c.GetHeader("Cache-Control"))
+	key := r.key(c)
+	ctx := c.Request.Context()
+
+	// no-cache asks for a response from the origin, which then refreshes the cache
+	if _, noCache := requestDirectives["no-cache"]; !noCache {
+		response, found, err := r.cache.Get(ctx, key)
+		if err != nil {
+			log.Warn("Failed to read the response cache", log.Fields{"error": err.Error()})
+		}
+		if found && response.Fresh(r.now()) {
+			r.serve(c, response)
+			return
+		}
+	}
+
+	r.mu.Lock()
+	if flight, ok := r.flights[key]; ok {
+		r.mu.Unlock()
+		select {
+		case <-flight.done:
+		case <-ctx.Done():
+			c.Abort()
+			return
+		}
+		if flight.cached {
+			r.serve(c, flight.response)
+			return
+		}
+		c.Next()
+		return
+	}
+	flight := &cacheFlight{done: make(chan struct{})}
+	r.flights[key] = flight
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		delete(r.flights, key)
+		r.mu.Unlock()
+		close(flight.done)
+	}()
+
+	_ = metrics.GetMonitor().GetMetric(metricCacheMissTotal).Inc([]string{r.route(c)})
+	c.Header(cacheStatusHeader, "MISS")
+	existing := headerNames(c.Writer.Header())
+	recorder := &recordingWriter{ResponseWriter: c.Writer, limit: r.cfg.MaxBodySize}
+	c.Writer = recorder
+	storedAt := r.now()
+	defer func() {
+		c.Writer = recorder.ResponseWriter
+	}()
+	c.Next()
+
+	if _, noStore := requestDirectives["no-store"]; noStore || recorder.truncated || ctx.Err() != nil {
+		return
+	}
+	ttl, ok := r.ttl(c, recorder, routeTTL)
+	if !ok {
+		return
+	}
+	flight.response = entities.CachedResponse{
+		StatusCode: recorder.Status(),
+		Header:     storedHeader(recorder.Header(), existing),
+		Body:       recorder.body.Bytes(),
+		Tags:       dto.ResponseTags(c),
+		StoredAt:   storedAt,
+		ExpiresAt:  storedAt.Add(ttl),
+	}
+	if err := r.cache.Set(ctx, key, flight.response); err != nil {
+		log.Warn("Failed to store the response in cache", log.Fields{"error": err.Error()})
+		return
+	}
+	flight.cached = true
+}
+
+func (r *responseCache) serve(c *gin.Context, response entities.CachedResponse) {
+	_ = metrics.GetMonitor().GetMetric(metricCacheHitTotal).Inc([]string{r.route(c)})
+	c.Header(cacheStatusHeader, "HIT")
+	c.Header("Age", strconv.Itoa(int(response.Age(r.now()).Seconds())))
+	writeStoredResponse(c, response.StatusCode, response.Header, response.Body)
+}
+
+// key identifies the cached variant of the request.
+func (r *responseCache) key(c *gin.Context) string {
+	var key strings.Builder
+	key.WriteString(c.Request.Method + " " + c.Request.URL.Path)
+	if r.cfg.KeyQuery {
+		// Encode sorts the parameters so their order does not matter
+		key.WriteString("?" + c.Request.URL.Query().Encode())
+	}
+	for _, name := range r.cfg.KeyHeaders {
+		key.WriteString("\n" + name + ": " + strings.Join(c.Request.Header.Values(name), ","))
+	}
+	if r.cfg.KeyPrincipal {
+		key.WriteString("\nprincipal: " + c.GetString(principalKey))
+	}
+	sum := sha256.Sum256([]byte(key.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// ttl returns how long the response may be cached, if at all.
+func (r *responseCache) ttl(c *gin.Context, recorder *recordingWriter, routeTTL time.Duration) (time.Duration, bool) {
+	if !cacheableStatus[recorder.Status()] || recorder.Header().Get("Set-Cookie") != "" {
+		return 0, false
+	}
+	directives := cacheControl(recorder.Header().Get("Cache-Control"))
+	if _, noStore := directives["no-store"]; noStore {
+		return 0, false
+	}
+	if _, noCache := directives["no-cache"]; noCache {
+		return 0, false
+	}
+	// a private response may only be served back to its principal
+	if _, private := directives["private"]; private && (!r.cfg.KeyPrincipal || c.GetString(principalKey) == "") {
+		return 0, false
+	}
+	for _, directive := range []string{"s-maxage", "max-age"} {
+		if value, ok := directives[directive]; ok {
+			seconds, err := strconv.Atoi(value)
+			if err != nil || seconds <= 0 {
+				return 0, false
+			}
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+	return routeTTL, routeTTL > 0
+}
+
+// cacheControl parses Cache-Control directives, lower cased, with unquoted values.
+func cacheControl(value string) map[string]string {
+	directives := map[string]string{}
+	for _, directive := range strings.Split(value, ",") {
+		name, argument, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		if name != "" {
+			directives[strings.ToLower(name)] = strings.Trim(argument, `"`)
+		}
+	}
+	return directives
+}
@@ -0,0 +1,198 @@
+package infrastructure
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/memory"
+	"github.com/oswaldom-code/api-template-gin/src/application/system_services/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupCacheRouter(cache ports.ResponseCache, calls *int32, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	configureMonitor()
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(principalKey, c.GetHeader("X-Test-Principal"))
+	}, newResponseCacheMiddleware(cache, config.ResponseCacheConfig{
+		MaxBodySize:  1024,
+		Routes:       []config.CacheRoute{{Path: "/items", TTL: time.Minute}, {Path: "/fresh"}},
+		KeyQuery:     true,
+		KeyHeaders:   []string{"Accept"},
+		KeyPrincipal: true,
+	}, (*gin.Context).FullPath))
+	wrapped := func(c *gin.Context) {
+		atomic.AddInt32(calls, 1)
+		handler(c)
+	}
+	router.GET("/items", wrapped)
+	router.GET("/fresh", wrapped)
+	router.GET("/uncached", wrapped)
+	return router
+}
+
+func cacheRequest(router *gin.Engine, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestResponseCache_ServesHits(t *testing.T) {
+	var calls int32
+	router := setupCacheRouter(memory.NewResponseCache(10), &calls, func(c *gin.Context) {
+		c.Header("ETag", `"v1"`)
+		dto.OK(c, gin.H{"items": []int{1, 2}})
+	})
+
+	first := cacheRequest(router, "/items?b=2&a=1", nil)
+	assert.Equal(t, "MISS", first.Header().Get(cacheStatusHeader))
+
+	second := cacheRequest(router, "/items?a=1&b=2", nil)
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, "HIT", second.Header().Get(cacheStatusHeader))
+	assert.Equal(t, "0", second.Header().Get("Age"))
+	assert.Equal(t, `"v1"`, second.Header().Get("ETag"))
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, int32(1), calls)
+
+	// other query, Accept or principal are other variants
+	cacheRequest(router, "/items?a=2", nil)
+	cacheRequest(router, "/items?a=1&b=2", map[string]string{"Accept": "application/cbor"})
+	cacheRequest(router, "/items?a=1&b=2", map[string]string{"X-Test-Principal": "alice"})
+	assert.Equal(t, int32(4), calls)
+
+	// request no-cache goes to the handler
+	cacheRequest(router, "/items?a=1&b=2", map[string]string{"Cache-Control": "no-cache"})
+	cacheRequest(router, "/uncached", nil)
+	cacheRequest(router, "/uncached", nil)
+	assert.Equal(t, int32(7), calls)
+}
+
+func TestResponseCache_HonorsCacheControl(t *testing.T) {
+	tests := []struct {
+		name         string
+		path         string
+		cacheControl string
+		principal    string
+		cached       bool
+	}{
+		{"route ttl", "/items", "", "", true},
+		{"max-age", "/fresh", "max-age=60", "", true},
+		{"no ttl", "/fresh", "", "", false},
+		{"no-store", "/items", "no-store", "", false},
+		{"no-cache", "/items", "no-cache", "", false},
+		{"max-age=0", "/items", "max-age=0", "", false},
+		{"private without principal", "/items", "private, max-age=60", "", false},
+		{"private with principal", "/items", "private, max-age=60", "alice", true},
+	}
+	for _, tt := range tests {
+		var calls int32
+		router := setupCacheRouter(memory.NewResponseCache(10), &calls, func(c *gin.Context) {
+			if tt.cacheControl != "" {
+				c.Header("Cache-Control", tt.cacheControl)
+			}
+			dto.OK(c, nil)
+		})
+		headers := map[string]string{"X-Test-Principal": tt.principal}
+		cacheRequest(router, tt.path, headers)
+		w := cacheRequest(router, tt.path, headers)
+		assert.Equal(t, tt.cached, w.Header().Get(cacheStatusHeader) == "HIT", tt.name)
+	}
+}
+
+func TestResponseCache_SkipsErrorsAndLargeBodies(t *testing.T) {
+	var calls int32
+	status := http.StatusInternalServerError
+	body := "boom"
+	router := setupCacheRouter(memory.NewResponseCache(10), &calls, func(c *gin.Context) {
+		c.String(status, body)
+	})
+
+	cacheRequest(router, "/items", nil)
+	cacheRequest(router, "/items", nil)
+	assert.Equal(t, int32(2), calls)
+
+	status, body = http.StatusOK, string(make([]byte, 2048))
+	cacheRequest(router, "/items", nil)
+	cacheRequest(router, "/items", nil)
+	assert.Equal(t, int32(4), calls)
+}
+
+func TestResponseCache_InvalidatesByTag(t *testing.T) {
+	var calls int32
+	cache := memory.NewResponseCache(10)
+	router := setupCacheRouter(cache, &calls, func(c *gin.Context) {
+		dto.TagResponse(c, "items")
+		dto.OK(c, nil)
+	})
+
+	cacheRequest(router, "/items", nil)
+	assert.Equal(t, "HIT", cacheRequest(router, "/items", nil).Header().Get(cacheStatusHeader))
+
+	require.NoError(t, cache.InvalidateTags(context.Background(), "items"))
+	assert.Equal(t, "MISS", cacheRequest(router, "/items", nil).Header().Get(cacheStatusHeader))
+	assert.Equal(t, int32(2), calls)
+}
+
+func TestResponseCache_CoalescesConcurrentMisses(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	router := setupCacheRouter(memory.NewResponseCache(10), &calls, func(c *gin.Context) {
+		<-release
+		dto.OK(c, gin.H{"ok": true})
+	})
+
+	const clients = 5
+	var wg sync.WaitGroup
+	codes := make([]int, clients)
+	for i := 0; i < clients; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = cacheRequest(router, "/items", nil).Code
+		}(i)
+	}
+	// let every request reach the cache before the handler answers
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls)
+	for _, code := range codes {
+		assert.Equal(t, http.StatusOK, code)
+	}
+}
+
+func TestUnmatchedCacheRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/v1/ping", func(c *gin.Context) {})
+	router.POST("/v1/orders", func(c *gin.Context) {})
+
+	unmatched := unmatchedCacheRoutes(router.Routes(), []config.CacheRoute{
+		{Path: "/v1/ping", TTL: 30 * time.Second},
+		{Path: "/ping", TTL: 30 * time.Second},
+		{Path: "/v1/orders"},
+	})
+
+	assert.Equal(t, []string{"/ping", "/v1/orders"}, unmatched)
+}
+
+func TestCacheControl(t *testing.T) {
+	directives := cacheControl(`Private, max-age="60", no-transform`)
+	assert.Equal(t, map[string]string{"private": "", "max-age": "60", "no-transform": ""}, directives)
+}
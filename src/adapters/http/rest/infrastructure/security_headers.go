@@ -0,0 +1,86 @@
+package infrastructure
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+)
+
+// cspOverride replaces the Content-Security-Policy for paths under prefix.
+type cspOverride struct {
+	prefix string
+	policy string
+}
+
+// securityHeaders is a compiled config.SecurityHeadersConfig.
+type securityHeaders struct {
+	hsts      string
+	csp       string
+	overrides []cspOverride // longest prefix first
+	static    map[string]string
+}
+
+// newSecurityHeadersMiddleware sets the security response headers. It is installed on
+// the engine so NoRoute handlers (static files, 404s) get the headers as well.
+func newSecurityHeadersMiddleware(cfg config.SecurityHeadersConfig) gin.HandlerFunc {
+	h := &securityHeaders{
+		csp: cfg.ContentSecurityPolicy,
+		static: map[string]string{
+			"X-Content-Type-Options": "nosniff",
+			"X-Frame-Options":        cfg.FrameOptions,
+			"Referrer-Policy":        cfg.ReferrerPolicy,
+			"Permissions-Policy":     cfg.PermissionsPolicy,
+		},
+	}
+	if cfg.HSTSMaxAge > 0 {
+		h.hsts = "max-age=" + strconv.Itoa(cfg.HSTSMaxAge)
+		if cfg.HSTSIncludeSubdomains {
+			h.hsts += "; includeSubDomains"
+		}
+		if cfg.HSTSPreload {
+			h.hsts += "; preload"
+		}
+	}
+	for prefix, policy := range cfg.CSPOverrides {
+		h.overrides = append(h.overrides, cspOverride{prefix: strings.TrimSuffix(prefix, "/"), policy: policy})
+	}
+	sort.Slice(h.overrides, func(i, j int) bool {
+		return len(h.overrides[i].prefix) > len(h.overrides[j].prefix)
+	})
+	return h.handle
+}
+
+func (h *securityHeaders) handle(c *gin.Context) {
+	header := c.Writer.Header()
+	for name, value := range h.static {
+		if value != "" {
+			header.Set(name, value)
+		}
+	}
+	if csp := h.policyFor(c.Request.URL.Path); csp != "" {
+		header.Set("Content-Security-Policy", csp)
+	}
+	// browsers ignore HSTS received over plain HTTP
+	if h.hsts != "" && isHTTPS(c) {
+		header.Set("Strict-Transport-Security", h.hsts)
+	}
+	c.Next()
+}
+
+// policyFor returns the CSP of the longest override prefix matching path, or the default.
+func (h *securityHeaders) policyFor(path string) string {
+	for _, override := range h.overrides {
+		if path == override.prefix || strings.HasPrefix(path, override.prefix+"/") || override.prefix == "" {
+			return override.policy
+		}
+	}
+	return h.csp
+}
+
+func isHTTPS(c *gin.Context) bool {
+	return c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https")
+}
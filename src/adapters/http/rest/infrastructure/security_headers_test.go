@@ -0,0 +1,92 @@
+package infrastructure
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/handlers"
+	"github.com/stretchr/testify/assert"
+)
+
+func testSecurityHeadersConfig() config.SecurityHeadersConfig {
+	return config.SecurityHeadersConfig{
+		Enabled:               true,
+		HSTSMaxAge:            63072000,
+		HSTSIncludeSubdomains: true,
+		ContentSecurityPolicy: "default-src 'none'",
+		CSPOverrides: map[string]string{
+			"/metrics": "",
+			"/app/":    "default-src 'self'",
+		},
+		FrameOptions:   "DENY",
+		ReferrerPolicy: "no-referrer",
+	}
+}
+
+func setupSecurityHeadersRouter(cfg config.SecurityHeadersConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(newSecurityHeadersMiddleware(cfg))
+	RegisterHandlers(router, handlers.NewRestHandler())
+	router.GET("/metrics", func(c *gin.Context) { c.String(http.StatusOK, "") })
+	router.NoRoute(func(c *gin.Context) { c.String(http.StatusOK, "static") })
+	return router
+}
+
+func TestSecurityHeaders_Defaults(t *testing.T) {
+	router := setupSecurityHeadersRouter(testSecurityHeadersConfig())
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "no-referrer", w.Header().Get("Referrer-Policy"))
+	assert.Equal(t, "default-src 'none'", w.Header().Get("Content-Security-Policy"))
+	assert.Empty(t, w.Header().Values("Permissions-Policy"), "empty values omit the header")
+	assert.Empty(t, w.Header().Get("Strict-Transport-Security"), "HSTS is not sent over HTTP")
+}
+
+func TestSecurityHeaders_HSTSOverHTTPS(t *testing.T) {
+	cfg := testSecurityHeadersConfig()
+	cfg.HSTSPreload = true
+	router := setupSecurityHeadersRouter(cfg)
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.TLS = &tls.ConnectionState{}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "max-age=63072000; includeSubDomains; preload", w.Header().Get("Strict-Transport-Security"))
+
+	req = httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.NotEmpty(t, w.Header().Get("Strict-Transport-Security"))
+}
+
+func TestSecurityHeaders_CSPOverrides(t *testing.T) {
+	router := setupSecurityHeadersRouter(testSecurityHeadersConfig())
+
+	tests := []struct {
+		path string
+		csp  string
+	}{
+		{"/metrics", ""},
+		{"/app", "default-src 'self'"},
+		{"/app/assets/main.js", "default-src 'self'"},
+		{"/application", "default-src 'none'"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, tt.csp, w.Header().Get("Content-Security-Policy"), tt.path)
+		assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"), tt.path)
+	}
+}
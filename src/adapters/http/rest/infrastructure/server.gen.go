@@ -1,6 +1,12 @@
 package infrastructure
 
-import "github.com/gin-gonic/gin"
+import (
+	"net/http"
+	"path"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
 
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
@@ -9,8 +15,10 @@ type ServerInterface interface {
 
 // GinServerOptions provides options for the Gin server.
 type GinServerOptions struct {
-	BaseURL     string
-	Middlewares []gin.HandlerFunc
+	BaseURL string
+	// PublicMiddlewares run on the public group, Middlewares on the protected group.
+	PublicMiddlewares []gin.HandlerFunc
+	Middlewares       []gin.HandlerFunc
 }
 
 // RegisterHandlers creates http.Handler with routing matching OpenAPI spec.
@@ -20,11 +28,17 @@ func RegisterHandlers(router *gin.Engine, si ServerInterface) *gin.Engine {
 
 // RegisterHandlersWithOptions creates http.Handler with public and protected route groups.
 func RegisterHandlersWithOptions(router *gin.Engine, si ServerInterface, options GinServerOptions) *gin.Engine {
+	preflight := preflightRoutes{}
+
 	public := router.Group(options.BaseURL)
+	for _, m := range options.PublicMiddlewares {
+		public.Use(m)
+	}
 	{
 		public.GET("/ping", func(c *gin.Context) {
 			si.Ping(c)
 		})
+		preflight.add(public, "/ping")
 	}
 
 	protected := router.Group(options.BaseURL)
@@ -34,7 +48,56 @@ func RegisterHandlersWithOptions(router *gin.Engine, si ServerInterface, options
 	{
 		// Add protected routes here as the API grows
 		// Example: protected.GET("/users", si.ListUsers)
+		//          preflight.add(protected, "/users")
+		// WebSocket routes are authenticated on the upgrade request and need no preflight.
+		// Routes holding their connection open (WebSocket, Server-Sent Events) are marked
+		// so request deadlines, load shedding, compression, 406 answers and idempotency
+		// keys skip them.
+		// Example: protected.GET("/events", si.Events)
+		//          streaming.add(protected, http.MethodGet, "/events")
 	}
 
 	return router
 }
+
+// preflightRoutes registers an OPTIONS route next to API routes so CORS preflight
+// requests run through the middlewares of the group that owns the route.
+// The first group registering a path owns its preflight.
+type preflightRoutes map[string]bool
+
+func (p preflightRoutes) add(group *gin.RouterGroup, relativePath string) {
+	fullPath := path.Join(group.BasePath(), relativePath)
+	if p[fullPath] {
+		return
+	}
+	p[fullPath] = true
+	group.OPTIONS(relativePath, func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// streamingRoutes are the routes, e.g. "GET /v1/events", whose responses stream or
+// upgrade the connection. They are matched on the route, never on request headers,
+// which clients choose.
+type streamingRoutes struct {
+	mu     sync.RWMutex
+	routes map[string]bool
+}
+
+var streaming = &streamingRoutes{routes: map[string]bool{}}
+
+func (s *streamingRoutes) add(group *gin.RouterGroup, method, relativePath string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes[method+" "+path.Join(group.BasePath(), relativePath)] = true
+}
+
+// matched reports whether the request matched a streaming route.
+func (s *streamingRoutes) matched(c *gin.Context) bool {
+	if c.FullPath() == "" {
+		return false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.routes[c.Request.Method+" "+c.FullPath()]
+}
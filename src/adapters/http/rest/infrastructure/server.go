@@ -1,31 +1,57 @@
 package infrastructure
 
 import (
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"io"
 	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	metrics "github.com/penglongli/gin-metrics/ginmetrics"
 
 	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/pkg/log"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/graphql"
 	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
 	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/handlers"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/websocket"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/memory"
+	Service "github.com/oswaldom-code/api-template-gin/src/application/system_services"
+	"github.com/oswaldom-code/api-template-gin/src/application/system_services/ports"
 )
 
-var basicAuthorizationMiddleware gin.HandlerFunc = func(c *gin.Context) {
-	// get token from header
-	token := c.GetHeader("Authorization")
-	expected := "Basic " + base64.StdEncoding.EncodeToString([]byte(config.GetAuthenticationKey().Secret))
-	// validate token
-	if token != expected {
-		dto.Unauthorized(c, "Invalid or missing auth token")
-		return
+// principalKey stores the authenticated principal in the gin context.
+const principalKey = "principal"
+
+// credentialFingerprint identifies a credential without keeping it in memory, logs or stores.
+func credentialFingerprint(credential string) string {
+	sum := sha256.Sum256([]byte(credential))
+	return hex.EncodeToString(sum[:8])
+}
+
+var basicAuthorizationMiddleware = newBasicAuthorizationMiddleware(func() string {
+	return config.GetAuthenticationKey().Secret
+})
+
+// newBasicAuthorizationMiddleware validates the Authorization header against the secret returned by secret.
+func newBasicAuthorizationMiddleware(secret func() string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// get token from header
+		token := c.GetHeader("Authorization")
+		expected := "Basic " + base64.StdEncoding.EncodeToString([]byte(secret()))
+		// validate token
+		if token != expected {
+			dto.Unauthorized(c, "Invalid or missing auth token")
+			return
+		}
+		c.Set(principalKey, credentialFingerprint(token))
+		c.Next()
 	}
-	c.Next()
 }
 
-func setMetrics(router *gin.Engine) {
+func configureMonitor() *metrics.Monitor {
 	// get global Monitor object
 	monitor := metrics.GetMonitor()
 	// +optional set metric path, default /debug/metrics
@@ -35,11 +61,106 @@ func setMetrics(router *gin.Engine) {
 	// +optional set request duration, default {0.1, 0.3, 1.2, 5, 10}
 	// used to p95, p99
 	monitor.SetDuration([]float64{0.1, 0.3, 1.2, 5, 10})
-	monitor.Use(router)
+	registerPanicMetric()
+	registerConcurrencyMetrics()
+	registerVersioningMetrics()
+	registerResponseCacheMetrics()
+	websocket.RegisterMetrics()
+	return monitor
+}
+
+// setMetrics collects request metrics on router; the /metrics endpoint is only
+// added when expose is true, otherwise it lives on the admin listener.
+func setMetrics(router *gin.Engine, expose bool) {
+	monitor := configureMonitor()
+	if expose {
+		monitor.Use(router)
+		return
+	}
+	monitor.UseWithoutExposingEndpoint(router)
+}
+
+// exposeMetrics adds the /metrics endpoint to routes.
+func exposeMetrics(routes gin.IRoutes) {
+	configureMonitor().Expose(routes)
+}
+
+// middlewareStores holds the stores shared by the route group middlewares, built on first use.
+type middlewareStores struct {
+	rateLimit   ports.RateLimitStore
+	idempotency ports.IdempotencyStore
+	// concurrency is nil when load shedding is disabled
+	concurrency *concurrencyLimits
 }
 
-// NewGinServer creates a new Gin server.
+func (s *middlewareStores) rateLimitStore(store string) ports.RateLimitStore {
+	if s.rateLimit == nil {
+		s.rateLimit = newRateLimitStore(store)
+	}
+	return s.rateLimit
+}
+
+func (s *middlewareStores) idempotencyStore(store string) ports.IdempotencyStore {
+	if s.idempotency == nil {
+		s.idempotency = newIdempotencyStore(store)
+	}
+	return s.idempotency
+}
+
+// routeGroupMiddlewares builds the middleware chain of a route group ("public" or "protected").
+// Cross-cutting middlewares run first, then authorization, then the ones that need the principal.
+// Per-route settings are keyed by the pattern route returns.
+func routeGroupMiddlewares(group string, stores *middlewareStores, route func(c *gin.Context) string, authorization ...gin.HandlerFunc) []gin.HandlerFunc {
+	var middlewares, authenticated []gin.HandlerFunc
+	if ipFilterConfig := config.GetIPFilterConfig(group); ipFilterConfig.Enabled() {
+		ipFilter, err := newIPFilterMiddleware(ipFilterConfig)
+		if err != nil {
+			panic("[ERROR] IP filter configuration is not valid: " + err.Error())
+		}
+		middlewares = append(middlewares, ipFilter)
+	}
+	if corsConfig := config.GetCORSConfig(group); corsConfig.Enabled() {
+		cors, err := newCORSMiddleware(corsConfig)
+		if err != nil {
+			panic("[ERROR] CORS configuration is not valid: " + err.Error())
+		}
+		middlewares = append(middlewares, cors)
+	}
+	middlewares = append(middlewares, notAcceptableMiddleware)
+	if limit := config.GetConcurrencyConfig().GroupLimits[group]; stores.concurrency != nil && limit > 0 {
+		middlewares = append(middlewares, stores.concurrency.limiter(group, limit))
+	}
+	if rateLimitConfig := config.GetRateLimitConfig(group); rateLimitConfig.Enabled {
+		if err := rateLimitConfig.Validate(); err != nil {
+			panic("[ERROR] rate limit configuration is not valid: " + err.Error())
+		}
+		rateLimit := newRateLimitMiddleware(stores.rateLimitStore(rateLimitConfig.Store), group, rateLimitConfig, route)
+		if rateLimitConfig.KeyBy == rateLimitByPrincipal {
+			authenticated = append(authenticated, rateLimit)
+		} else {
+			middlewares = append(middlewares, rateLimit)
+		}
+	}
+	if cacheConfig := config.GetResponseCacheConfig(); cacheConfig.Enabled && len(cacheConfig.Routes) > 0 {
+		// after the rate limits so cache hits still count, the shared cache is what services invalidate
+		authenticated = append(authenticated, newResponseCacheMiddleware(memory.SharedResponseCache(), cacheConfig, route))
+	}
+	if idempotencyConfig := config.GetIdempotencyConfig(); idempotencyConfig.Enabled {
+		// keys are scoped to the principal, so replays run after authorization
+		authenticated = append(authenticated, newIdempotencyMiddleware(stores.idempotencyStore(idempotencyConfig.Store), idempotencyConfig))
+	}
+	middlewares = append(middlewares, authorization...)
+	return append(middlewares, authenticated...)
+}
+
+// NewGinServer creates a new Gin server with handler serving the default API version.
 func NewGinServer(handler ServerInterface) *gin.Engine {
+	return NewVersionedGinServer(map[string]ServerInterface{config.GetVersioningConfig().DefaultVersion: handler})
+}
+
+// NewVersionedGinServer creates a new Gin server serving each API version ("v1", "v2")
+// under its own path prefix with its handlers in versionHandlers.
+func NewVersionedGinServer(versionHandlers map[string]ServerInterface) *gin.Engine {
 	// get configuration
 	serverConfig := config.GetServerConfig()
 	// validate parameters configuration
@@ -62,24 +183,103 @@ func NewGinServer(handler ServerInterface) *gin.Engine {
 	}
 
 	// create routes
-	router := gin.Default()
-	// set metrics
-	setMetrics(router)
-	// register handlers with route groups (public + protected)
+	router := gin.New()
+	if err := configureClientIP(router, serverConfig); err != nil {
+		panic("[ERROR] server configuration is not valid: " + err.Error())
+	}
+	router.Use(requestIDMiddleware, gin.LoggerWithFormatter(accessLogFormatter), recoveryMiddleware)
+	if securityConfig := config.GetSecurityHeadersConfig(); securityConfig.Enabled {
+		router.Use(newSecurityHeadersMiddleware(securityConfig))
+	}
+	compressionConfig := config.GetCompressionConfig()
+	if compressionConfig.Enabled {
+		router.Use(newCompressionMiddleware(compressionConfig))
+	}
+	if compressionConfig.MaxDecompressedSize > 0 {
+		router.Use(newDecompressionMiddleware(compressionConfig.MaxDecompressedSize))
+	}
+	// set metrics, the endpoint moves to the admin listener when one is configured
+	setMetrics(router, !serverConfig.AdminEnabled())
+	// maintenance mode covers every route, without taking load shedding slots
+	maintenanceConfig := config.GetMaintenanceConfig()
+	if !serverConfig.AdminEnabled() {
+		// the endpoint stays reachable to turn maintenance off
+		maintenanceConfig.Exempt = append(maintenanceConfig.Exempt, maintenancePath)
+	}
+	maintenance, err := newMaintenanceMiddleware(Service.MaintenanceService(), maintenanceConfig)
+	if err != nil {
+		panic("[ERROR] maintenance configuration is not valid: " + err.Error())
+	}
+	router.Use(maintenance)
+	stores := &middlewareStores{}
+	// load shedding runs inside the metrics middleware too, its latency histogram drives the adaptive modes
+	if concurrencyConfig := config.GetConcurrencyConfig(); concurrencyConfig.Enabled {
+		stores.concurrency = newConcurrencyLimits(concurrencyConfig)
+		if concurrencyConfig.Limit > 0 {
+			router.Use(stores.concurrency.limiter("global", concurrencyConfig.Limit))
+		}
+		go stores.concurrency.run()
+	}
+	// per-route settings match the versioned pattern of a route, whether or not the path has the version
+	versions, err := newAPIVersions(versionHandlers, config.GetVersioningConfig())
+	if err != nil {
+		panic("[ERROR] API versioning configuration is not valid: " + err.Error())
+	}
+	// deadlines run inside the metrics middleware so timed out requests are recorded as such
+	if timeoutConfig := config.GetTimeoutConfig(); timeoutConfig.Default > 0 || len(timeoutConfig.Routes) > 0 {
+		router.Use(newTimeoutMiddleware(timeoutConfig, versions.route))
+	}
+	// register handlers with route groups (public + protected) for every API version
 	ginServerOptions := GinServerOptions{
-		BaseURL:     "/",
-		Middlewares: []gin.HandlerFunc{basicAuthorizationMiddleware},
+		BaseURL:           "/",
+		PublicMiddlewares: routeGroupMiddlewares("public", stores, versions.route),
+		Middlewares:       routeGroupMiddlewares("protected", stores, versions.route, basicAuthorizationMiddleware),
+	}
+	versions.register(router, ginServerOptions)
+	protected := router.Group(ginServerOptions.BaseURL, ginServerOptions.Middlewares...)
+	// GraphQL evolves its schema instead of versions, it is served once
+	if graphqlConfig := config.GetGraphQLConfig(); graphqlConfig.Enabled {
+		graphqlHandler, err := graphql.NewHandler(graphqlConfig, Service.HealthService, Service.MaintenanceService())
+		if err != nil {
+			panic("[ERROR] GraphQL schema is not valid: " + err.Error())
+		}
+		public := router.Group(ginServerOptions.BaseURL, ginServerOptions.PublicMiddlewares...)
+		RegisterGraphQLHandlers(public, protected, graphqlHandler, graphqlConfig)
+	}
+	if cacheConfig := config.GetResponseCacheConfig(); cacheConfig.Enabled {
+		for _, path := range unmatchedCacheRoutes(router.Routes(), cacheConfig.Routes) {
+			log.Warn("Cache route matches no GET route, use the route pattern, e.g. /v1/ping", log.Fields{"route": path})
+		}
+	}
+	// operational endpoints go to the admin listener when there is one, otherwise behind the protected middlewares
+	if !serverConfig.AdminEnabled() {
+		RegisterMaintenanceHandlers(protected, handlers.NewAdminHandler(Service.HealthService, Service.MaintenanceService()))
+		if serverConfig.DebugEndpoints {
+			RegisterDebugHandlers(protected)
+		}
+	}
+	// serve static files (and the optional SPA fallback) on unmatched routes
+	if serverConfig.Static != "" {
+		RegisterStatic(router, StaticOptions{
+			FS:          os.DirFS(serverConfig.Static),
+			MountPath:   serverConfig.StaticMountPath,
+			MaxAge:      time.Duration(serverConfig.StaticMaxAge) * time.Second,
+			SPA:         serverConfig.StaticSPA,
+			APIPrefixes: append(versions.prefixes(), serverConfig.StaticAPIPrefixes...),
+		})
 	}
-	RegisterHandlersWithOptions(router, handler, ginServerOptions)
 	return router
 }
 
-func loadHandlers() *handlers.Handler {
-	return handlers.NewRestHandler()
+// loadHandlers returns the handlers of each API version.
+func loadHandlers() map[string]ServerInterface {
+	return map[string]ServerInterface{
+		"v1": handlers.NewRestHandler(),
+	}
 }
 
 func NewServer() *gin.Engine {
-	return NewGinServer(
+	return NewVersionedGinServer(
 		loadHandlers(),
 	)
 }
@@ -0,0 +1,211 @@
+package infrastructure
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+)
+
+const staticIndexFile = "index.html"
+
+// StaticOptions configures static file serving. FS may be an os.DirFS or an embed.FS.
+type StaticOptions struct {
+	FS fs.FS
+	// MountPath is the URL prefix the files are served under, e.g. "/" or "/assets".
+	MountPath string
+	// MaxAge is the Cache-Control max-age for regular files. index.html is always revalidated.
+	MaxAge time.Duration
+	// SPA serves index.html for unknown paths that do not look like files.
+	SPA bool
+	// APIPrefixes are never answered by the SPA fallback.
+	APIPrefixes []string
+}
+
+// precompressed lists the encodings looked up as sibling files, in order of preference.
+var precompressed = []struct {
+	encoding  string
+	extension string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+type staticHandler struct {
+	options StaticOptions
+	// etags caches content hashes for files without a modification time (embed.FS).
+	etags sync.Map
+}
+
+// RegisterStatic serves files from options.FS on every path that has no registered route.
+// Routes registered on the router always take precedence.
+func RegisterStatic(router *gin.Engine, options StaticOptions) {
+	options.MountPath = "/" + strings.Trim(options.MountPath, "/")
+	h := &staticHandler{options: options}
+	router.NoRoute(h.serve)
+}
+
+func (h *staticHandler) serve(c *gin.Context) {
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		dto.NotFound(c, "Resource not found")
+		return
+	}
+
+	name, ok := h.fileName(c.Request.URL.Path)
+	if !ok {
+		dto.NotFound(c, "Resource not found")
+		return
+	}
+
+	if h.serveFile(c, name) {
+		return
+	}
+	if h.options.SPA && h.isSPARoute(c.Request.URL.Path) && h.serveFile(c, staticIndexFile) {
+		return
+	}
+	dto.NotFound(c, "Resource not found")
+}
+
+// fileName maps a request path to a name inside the file system.
+func (h *staticHandler) fileName(urlPath string) (string, bool) {
+	mount := h.options.MountPath
+	if mount != "/" {
+		if urlPath != mount && !strings.HasPrefix(urlPath, mount+"/") {
+			return "", false
+		}
+		urlPath = strings.TrimPrefix(urlPath, mount)
+	}
+	name := strings.TrimPrefix(path.Clean("/"+urlPath), "/")
+	if name == "" {
+		name = "."
+	}
+	return name, fs.ValidPath(name)
+}
+
+// isSPARoute reports whether an unknown path should be answered with index.html.
+func (h *staticHandler) isSPARoute(urlPath string) bool {
+	for _, prefix := range h.options.APIPrefixes {
+		if urlPath == prefix || strings.HasPrefix(urlPath, strings.TrimSuffix(prefix, "/")+"/") {
+			return false
+		}
+	}
+	// paths that look like files (/app.js, /logo.png) get a real 404
+	return !strings.Contains(path.Base(urlPath), ".")
+}
+
+// serveFile writes name (or its index.html when name is a directory) and reports whether it existed.
+func (h *staticHandler) serveFile(c *gin.Context, name string) bool {
+	info, err := fs.Stat(h.options.FS, name)
+	if err != nil {
+		return false
+	}
+	if info.IsDir() {
+		// directory listing is disabled, only the index is served
+		name = path.Join(name, staticIndexFile)
+		if info, err = fs.Stat(h.options.FS, name); err != nil || info.IsDir() {
+			return false
+		}
+	}
+
+	header := c.Writer.Header()
+	header.Add("Vary", "Accept-Encoding")
+	if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+		header.Set("Content-Type", ctype)
+	}
+	if path.Base(name) == staticIndexFile {
+		header.Set("Cache-Control", "no-cache")
+	} else {
+		header.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(h.options.MaxAge.Seconds())))
+	}
+
+	servedName, encoding := h.negotiateEncoding(c.GetHeader("Accept-Encoding"), name)
+	if encoding != "" {
+		header.Set("Content-Encoding", encoding)
+		if header.Get("Content-Type") == "" {
+			header.Set("Content-Type", "application/octet-stream")
+		}
+	}
+
+	file, err := h.options.FS.Open(servedName)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	servedInfo, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	// files are streamed, only file systems whose files cannot seek are read into memory
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return false
+		}
+		content = bytes.NewReader(data)
+	}
+	etag, err := h.etag(servedName, servedInfo, content)
+	if err != nil {
+		return false
+	}
+	header.Set("ETag", etag)
+
+	http.ServeContent(c.Writer, c.Request, name, servedInfo.ModTime(), content)
+	return true
+}
+
+// negotiateEncoding picks a precompressed sibling (name.br, name.gz) accepted by the client.
+func (h *staticHandler) negotiateEncoding(acceptEncoding, name string) (string, string) {
+	if acceptEncoding == "" {
+		return name, ""
+	}
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		if len(fields) > 1 && strings.ReplaceAll(strings.TrimSpace(fields[1]), " ", "") == "q=0" {
+			continue
+		}
+		accepted[strings.ToLower(fields[0])] = true
+	}
+	for _, candidate := range precompressed {
+		if !accepted[candidate.encoding] {
+			continue
+		}
+		if info, err := fs.Stat(h.options.FS, name+candidate.extension); err == nil && !info.IsDir() {
+			return name + candidate.extension, candidate.encoding
+		}
+	}
+	return name, ""
+}
+
+// etag builds a validator from size and modification time, or from a content hash
+// when the file system reports no modification time (embed.FS).
+func (h *staticHandler) etag(name string, info fs.FileInfo, content io.ReadSeeker) (string, error) {
+	if !info.ModTime().IsZero() {
+		return fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()), nil
+	}
+	if cached, ok := h.etags.Load(name); ok {
+		return cached.(string), nil
+	}
+	hash := fnv.New64a()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	tag := fmt.Sprintf(`"%x"`, hash.Sum64())
+	h.etags.Store(name, tag)
+	return tag, nil
+}
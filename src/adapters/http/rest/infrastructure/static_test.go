@@ -0,0 +1,160 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupStaticRouter(options StaticOptions) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ping", func(c *gin.Context) { dto.OK(c, gin.H{"ping": "pong"}) })
+	if options.FS == nil {
+		options.FS = fstest.MapFS{
+			"index.html":       {Data: []byte("<html>app</html>")},
+			"app.js":           {Data: []byte("console.log('app')"), ModTime: time.Unix(1700000000, 0)},
+			"app.js.br":        {Data: []byte("brotli-bytes")},
+			"app.js.gz":        {Data: []byte("gzip-bytes")},
+			"assets/logo.svg":  {Data: []byte("<svg/>")},
+			"docs/index.html":  {Data: []byte("<html>docs</html>")},
+			"private/note.txt": {Data: []byte("note")},
+		}
+	}
+	RegisterStatic(router, options)
+	return router
+}
+
+func serve(router *gin.Engine, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestStatic_ServesFileWithCacheHeaders(t *testing.T) {
+	router := setupStaticRouter(StaticOptions{MountPath: "/", MaxAge: time.Hour})
+
+	w := serve(router, httptest.NewRequest(http.MethodGet, "/app.js", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "console.log('app')", w.Body.String())
+	assert.Equal(t, "public, max-age=3600", w.Header().Get("Cache-Control"))
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+	assert.Contains(t, w.Header().Get("Content-Type"), "javascript")
+}
+
+// unseekableFS hides the Seek method of the files of its FS.
+type unseekableFS struct {
+	fs.FS
+}
+
+func (u unseekableFS) Open(name string) (fs.File, error) {
+	file, err := u.FS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return struct{ fs.File }{file}, nil
+}
+
+func TestStatic_ServesRanges(t *testing.T) {
+	files := fstest.MapFS{
+		"video.mp4": {Data: []byte("0123456789"), ModTime: time.Unix(1700000000, 0)},
+		"clip.mp4":  {Data: []byte("abcdefghij")},
+	}
+	for name, fsys := range map[string]fs.FS{"seekable": files, "unseekable": unseekableFS{files}} {
+		router := setupStaticRouter(StaticOptions{FS: fsys, MountPath: "/"})
+
+		for path, expected := range map[string]string{"/video.mp4": "2345", "/clip.mp4": "cdef"} {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set("Range", "bytes=2-5")
+			w := serve(router, req)
+
+			assert.Equal(t, http.StatusPartialContent, w.Code, name+path)
+			assert.Equal(t, expected, w.Body.String(), name+path)
+			assert.NotEmpty(t, w.Header().Get("ETag"), name+path)
+		}
+	}
+}
+
+func TestStatic_IfNoneMatchReturnsNotModified(t *testing.T) {
+	router := setupStaticRouter(StaticOptions{MountPath: "/"})
+
+	first := serve(router, httptest.NewRequest(http.MethodGet, "/assets/logo.svg", nil))
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	req := httptest.NewRequest(http.MethodGet, "/assets/logo.svg", nil)
+	req.Header.Set("If-None-Match", etag)
+	w := serve(router, req)
+
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestStatic_PrefersPrecompressedVariants(t *testing.T) {
+	router := setupStaticRouter(StaticOptions{MountPath: "/"})
+
+	req := httptest.NewRequest(http.MethodGet, "/app.js", nil)
+	req.Header.Set("Accept-Encoding", "gzip, br")
+	w := serve(router, req)
+	assert.Equal(t, "br", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "brotli-bytes", w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Type"), "javascript")
+
+	req = httptest.NewRequest(http.MethodGet, "/app.js", nil)
+	req.Header.Set("Accept-Encoding", "gzip, br;q=0")
+	w = serve(router, req)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "gzip-bytes", w.Body.String())
+}
+
+func TestStatic_DirectoryListingDisabled(t *testing.T) {
+	router := setupStaticRouter(StaticOptions{MountPath: "/"})
+
+	w := serve(router, httptest.NewRequest(http.MethodGet, "/private/", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var resp dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, dto.ErrNotFound, resp.Error.Code)
+
+	w = serve(router, httptest.NewRequest(http.MethodGet, "/docs/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "<html>docs</html>", w.Body.String())
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+}
+
+func TestStatic_MountPath(t *testing.T) {
+	router := setupStaticRouter(StaticOptions{MountPath: "/static/"})
+
+	assert.Equal(t, http.StatusOK, serve(router, httptest.NewRequest(http.MethodGet, "/static/app.js", nil)).Code)
+	assert.Equal(t, http.StatusNotFound, serve(router, httptest.NewRequest(http.MethodGet, "/app.js", nil)).Code)
+	assert.Equal(t, http.StatusOK, serve(router, httptest.NewRequest(http.MethodGet, "/ping", nil)).Code)
+}
+
+func TestStatic_SPAFallback(t *testing.T) {
+	router := setupStaticRouter(StaticOptions{MountPath: "/", SPA: true, APIPrefixes: []string{"/api"}})
+
+	w := serve(router, httptest.NewRequest(http.MethodGet, "/dashboard/settings", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "<html>app</html>", w.Body.String())
+
+	w = serve(router, httptest.NewRequest(http.MethodGet, "/api/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), string(dto.ErrNotFound))
+
+	w = serve(router, httptest.NewRequest(http.MethodGet, "/missing.js", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serve(router, httptest.NewRequest(http.MethodPost, "/dashboard", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
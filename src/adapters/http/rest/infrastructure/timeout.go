@@ -0,0 +1,255 @@
+package infrastructure
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+)
+
+// requestTimeouts is a compiled config.TimeoutConfig.
+type requestTimeouts struct {
+	defaultTimeout time.Duration
+	routes         map[string]time.Duration
+	statusCode     int
+	// route returns the route pattern routes are keyed by
+	route func(c *gin.Context) string
+}
+
+// newTimeoutMiddleware sets a per-route deadline on the request context. Handlers run on
+// their own goroutine with a buffered writer: when the deadline passes first the client
+// gets the error envelope right away and whatever the handler writes later is dropped.
+// Services and repositories see the deadline through c.Request.Context(). route returns
+// the pattern of the matched route, e.g. apiVersions.route.
+func newTimeoutMiddleware(cfg config.TimeoutConfig, route func(c *gin.Context) string) gin.HandlerFunc {
+	t := &requestTimeouts{
+		defaultTimeout: cfg.Default,
+		routes:         map[string]time.Duration{},
+		statusCode:     cfg.StatusCode,
+		route:          route,
+	}
+	for _, route := range cfg.Routes {
+		t.routes[route.Method+" "+route.Path] = route.Timeout
+	}
+	return t.handle
+}
+
+// timeoutFor returns the deadline of the matched route. Unmatched requests (static
+// files, 404s) and streaming routes have none.
+func (t *requestTimeouts) timeoutFor(c *gin.Context) time.Duration {
+	if c.FullPath() == "" || streaming.matched(c) {
+		return 0
+	}
+	if timeout, ok := t.routes[c.Request.Method+" "+t.route(c)]; ok {
+		return timeout
+	}
+	return t.defaultTimeout
+}
+
+func (t *requestTimeouts) handle(c *gin.Context) {
+	timeout := t.timeoutFor(c)
+	if timeout <= 0 {
+		c.Next()
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+	c.Request = c.Request.WithContext(ctx)
+
+	writer := c.Writer
+	buffered := newTimeoutWriter(writer)
+	c.Writer = buffered
+
+	done := make(chan struct{})
+	var panicked any
+	go func() {
+		defer close(done)
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				if err, ok := recovered.(error); ok && errors.Is(err, http.ErrHandlerTimeout) {
+					// gin panics when rendering fails, here because the deadline already passed
+					return
+				}
+				if recovered == http.ErrAbortHandler {
+					panicked = recovered
+					return
+				}
+				panicked = &handlerPanic{value: recovered, stack: string(debug.Stack())}
+			}
+		}()
+		c.Next()
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && buffered.timeout() {
+			t.writeTimeout(c, writer)
+		}
+		// the handler still owns the gin context until it returns
+		<-done
+	}
+
+	c.Writer = writer
+	if panicked != nil {
+		panic(panicked)
+	}
+	// a handler returning right after the deadline usually reacted to it, its response is dropped
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) && buffered.timeout() {
+		t.writeTimeout(c, writer)
+	}
+	if buffered.timedOut {
+		c.Abort()
+		return
+	}
+	buffered.flushTo(writer)
+}
+
+// writeTimeout answers with the error envelope, bypassing c.Writer which the handler
+// goroutine still uses. Content-Length and a flush complete the response for the client
+// while the handler finishes.
+func (t *requestTimeouts) writeTimeout(c *gin.Context, writer gin.ResponseWriter) {
+	code := dto.ErrTimeout
+	if t.statusCode == http.StatusServiceUnavailable {
+		code = dto.ErrServiceUnavail
+	}
+	body, _ := json.Marshal(dto.NewErrorResponse(c, code, "The request took too long to complete"))
+
+	header := writer.Header()
+	header.Set("Content-Type", "application/json; charset=utf-8")
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	writer.WriteHeader(t.statusCode)
+	_, _ = writer.Write(body)
+	writer.Flush()
+}
+
+// timeoutWriter buffers the handler response. Once timed out, writes fail with
+// http.ErrHandlerTimeout.
+type timeoutWriter struct {
+	gin.ResponseWriter
+	mu          sync.Mutex
+	header      http.Header
+	body        bytes.Buffer
+	status      int
+	wroteHeader bool
+	timedOut    bool
+}
+
+func newTimeoutWriter(w gin.ResponseWriter) *timeoutWriter {
+	return &timeoutWriter{
+		ResponseWriter: w,
+		header:         w.Header().Clone(),
+		status:         http.StatusOK,
+	}
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if code > 0 && !w.wroteHeader {
+		w.status = code
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.wroteHeader = true
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.wroteHeader = true
+	return w.body.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.status
+}
+
+func (w *timeoutWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.wroteHeader
+}
+
+func (w *timeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.wroteHeader {
+		return -1
+	}
+	return w.body.Len()
+}
+
+// Flush is a no-op, the response is sent once the handler returns.
+func (w *timeoutWriter) Flush() {}
+
+func (w *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, errors.New("hijacking is not supported on routes with a deadline")
+}
+
+func (w *timeoutWriter) Pusher() http.Pusher {
+	return nil
+}
+
+// timeout marks the writer as timed out, reporting false if it already was.
+func (w *timeoutWriter) timeout() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return false
+	}
+	w.timedOut = true
+	return true
+}
+
+// flushTo copies the buffered headers, status and body to the real writer.
+func (w *timeoutWriter) flushTo(writer gin.ResponseWriter) {
+	header := writer.Header()
+	for name := range header {
+		if _, ok := w.header[name]; !ok {
+			header.Del(name)
+		}
+	}
+	for name, values := range w.header {
+		header[name] = values
+	}
+	writer.WriteHeader(w.status)
+	if !w.wroteHeader {
+		// gin writes the header after the handlers when nothing was written
+		return
+	}
+	if w.body.Len() == 0 {
+		writer.WriteHeaderNow()
+		return
+	}
+	_, _ = writer.Write(w.body.Bytes())
+}
@@ -0,0 +1,135 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTimeoutRouter(statusCode int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(requestIDMiddleware, recoveryMiddleware, newTimeoutMiddleware(config.TimeoutConfig{
+		Default: 50 * time.Millisecond,
+		Routes: []config.TimeoutRoute{
+			{Method: http.MethodGet, Path: "/unbounded", Timeout: 0},
+		},
+		StatusCode: statusCode,
+	}, (*gin.Context).FullPath))
+	router.GET("/fast", func(c *gin.Context) {
+		c.Header("X-Handler", "fast")
+		_, hasDeadline := c.Request.Context().Deadline()
+		dto.Created(c, gin.H{"deadline": hasDeadline})
+	})
+	router.GET("/cooperative", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			dto.InternalError(c, c.Request.Context().Err().Error())
+		case <-time.After(time.Second):
+			dto.OK(c, "late")
+		}
+	})
+	router.GET("/stubborn", func(c *gin.Context) {
+		time.Sleep(150 * time.Millisecond)
+		dto.OK(c, "late")
+	})
+	router.GET("/unbounded", func(c *gin.Context) {
+		_, hasDeadline := c.Request.Context().Deadline()
+		dto.OK(c, gin.H{"deadline": hasDeadline})
+	})
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	router.GET("/events", func(c *gin.Context) {
+		_, hasDeadline := c.Request.Context().Deadline()
+		dto.OK(c, gin.H{"deadline": hasDeadline})
+	})
+	streaming.add(&router.RouterGroup, http.MethodGet, "/events")
+	return router
+}
+
+func timeoutRequest(router *gin.Engine, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestTimeout_FastHandlerPassesThrough(t *testing.T) {
+	router := setupTimeoutRouter(http.StatusGatewayTimeout)
+
+	w := timeoutRequest(router, "/fast")
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "fast", w.Header().Get("X-Handler"))
+	assert.NotEmpty(t, w.Header().Get("X-Request-ID"))
+	assert.Contains(t, w.Body.String(), `"deadline":true`)
+}
+
+func TestTimeout_DeadlineExceeded(t *testing.T) {
+	router := setupTimeoutRouter(http.StatusGatewayTimeout)
+
+	for _, path := range []string{"/cooperative", "/stubborn"} {
+		start := time.Now()
+		w := timeoutRequest(router, path)
+
+		assert.Equal(t, http.StatusGatewayTimeout, w.Code, path)
+		var resp dto.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), "a single envelope is written: %s", w.Body.String())
+		assert.Equal(t, dto.ErrTimeout, resp.Error.Code)
+		assert.NotEmpty(t, resp.Meta.RequestID)
+		assert.Less(t, time.Since(start), time.Second, path)
+	}
+}
+
+func TestTimeout_ServiceUnavailableStatus(t *testing.T) {
+	router := setupTimeoutRouter(http.StatusServiceUnavailable)
+
+	w := timeoutRequest(router, "/cooperative")
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), string(dto.ErrServiceUnavail))
+}
+
+func TestTimeout_RouteOverrideDisablesDeadline(t *testing.T) {
+	router := setupTimeoutRouter(http.StatusGatewayTimeout)
+
+	w := timeoutRequest(router, "/unbounded")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"deadline":false`)
+}
+
+func TestTimeout_PanicReachesRecovery(t *testing.T) {
+	router := setupTimeoutRouter(http.StatusGatewayTimeout)
+
+	w := timeoutRequest(router, "/panic")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	var resp dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, dto.ErrInternalServer, resp.Error.Code)
+	assert.NotEmpty(t, resp.Error.IncidentID)
+}
+
+func TestTimeout_StreamingRoutesHaveNoDeadline(t *testing.T) {
+	router := setupTimeoutRouter(http.StatusGatewayTimeout)
+
+	w := timeoutRequest(router, "/events")
+	assert.Contains(t, w.Body.String(), `"deadline":false`)
+
+	// request headers do not make a route streaming
+	req := httptest.NewRequest(http.MethodGet, "/fast", nil)
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set("Upgrade", "x")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Contains(t, w.Body.String(), `"deadline":true`)
+}
@@ -0,0 +1,245 @@
+package infrastructure
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	metrics "github.com/penglongli/gin-metrics/ginmetrics"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/pkg/log"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+)
+
+const (
+	// apiVersionKey stores the API version serving the request in the gin context.
+	apiVersionKey    = "api_version"
+	apiVersionHeader = "API-Version"
+
+	metricDeprecatedTotal = "gin_deprecated_requests_total"
+)
+
+var apiVersionPattern = regexp.MustCompile(`^v[0-9]+$`)
+
+func registerVersioningMetrics() {
+	_ = metrics.GetMonitor().AddMetric(&metrics.Metric{
+		Type:        metrics.Counter,
+		Name:        metricDeprecatedTotal,
+		Description: "the requests to deprecated API versions and routes counter.",
+		Labels:      []string{"version", "route"},
+	})
+}
+
+// apiVersions serves each API version under /<version> with its own handlers. Unversioned
+// paths go to the version selected by the Accept header parameter, e.g.
+// "Accept: application/json; version=2", or the default one.
+type apiVersions struct {
+	defaultVersion string
+	handlers       map[string]ServerInterface
+	// deprecations are keyed by version ("v1") or versioned route ("GET /v1/ping").
+	deprecations map[string]config.APIDeprecation
+	// unversioned are the routes served without a version prefix ("GET /ping").
+	unversioned map[string]bool
+}
+
+func newAPIVersions(handlers map[string]ServerInterface, cfg config.VersioningConfig) (*apiVersions, error) {
+	for version := range handlers {
+		if !apiVersionPattern.MatchString(version) {
+			return nil, fmt.Errorf("invalid API version %q, expected v<number>", version)
+		}
+	}
+	if _, ok := handlers[cfg.DefaultVersion]; !ok {
+		return nil, fmt.Errorf("default API version %q has no handlers", cfg.DefaultVersion)
+	}
+	v := &apiVersions{
+		defaultVersion: cfg.DefaultVersion,
+		handlers:       handlers,
+		deprecations:   map[string]config.APIDeprecation{},
+		unversioned:    map[string]bool{},
+	}
+	for _, deprecation := range cfg.Deprecations {
+		v.deprecations[deprecation.Target] = deprecation
+	}
+	return v, nil
+}
+
+// versions returns the served versions in order.
+func (v *apiVersions) versions() []string {
+	versions := make([]string, 0, len(v.handlers))
+	for version := range v.handlers {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		a, _ := strconv.Atoi(versions[i][1:])
+		b, _ := strconv.Atoi(versions[j][1:])
+		return a < b
+	})
+	return versions
+}
+
+// register adds the routes of every version, then the unversioned ones. Group
+// middlewares are shared so limits and stores apply across versions.
+func (v *apiVersions) register(router *gin.Engine, options GinServerOptions) {
+	for _, version := range v.versions() {
+		RegisterHandlersWithOptions(router, v.handlers[version], GinServerOptions{
+			BaseURL:           path.Join(options.BaseURL, version),
+			PublicMiddlewares: append([]gin.HandlerFunc{v.pinned(version)}, options.PublicMiddlewares...),
+			Middlewares:       append([]gin.HandlerFunc{v.pinned(version)}, options.Middlewares...),
+		})
+	}
+	versioned := map[string]bool{}
+	for _, route := range router.Routes() {
+		versioned[route.Method+" "+route.Path] = true
+	}
+	RegisterHandlersWithOptions(router, versionDispatcher{handlers: v.handlers}, GinServerOptions{
+		BaseURL:           options.BaseURL,
+		PublicMiddlewares: append([]gin.HandlerFunc{v.negotiate}, options.PublicMiddlewares...),
+		Middlewares:       append([]gin.HandlerFunc{v.negotiate}, options.Middlewares...),
+	})
+	for _, route := range router.Routes() {
+		if key := route.Method + " " + route.Path; !versioned[key] {
+			v.unversioned[key] = true
+		}
+	}
+}
+
+// route returns the pattern of the matched route with its API version, "/v1/ping" for
+// /v1/ping and for /ping negotiated to v1, so per-route settings and limits apply to
+// both paths alike. Routes outside the versions are returned as they are.
+func (v *apiVersions) route(c *gin.Context) string {
+	if !v.unversioned[c.Request.Method+" "+c.FullPath()] {
+		return c.FullPath()
+	}
+	// global middlewares run before the version is negotiated
+	version := c.GetString(apiVersionKey)
+	if version == "" {
+		version = acceptedVersion(c.GetHeader("Accept"))
+	}
+	if version == "" {
+		version = v.defaultVersion
+	}
+	return path.Join("/", version, c.FullPath())
+}
+
+// prefixes returns the path prefixes of the served versions.
+func (v *apiVersions) prefixes() []string {
+	prefixes := []string{}
+	for _, version := range v.versions() {
+		prefixes = append(prefixes, "/"+version)
+	}
+	return prefixes
+}
+
+// pinned serves the routes under /<version>.
+func (v *apiVersions) pinned(version string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		v.serve(c, version, c.FullPath())
+	}
+}
+
+// negotiate serves the unversioned routes, answering 406 for versions not served.
+func (v *apiVersions) negotiate(c *gin.Context) {
+	if !varyContains(c.Writer.Header(), "Accept") {
+		c.Writer.Header().Add("Vary", "Accept")
+	}
+	version := acceptedVersion(c.GetHeader("Accept"))
+	if version == "" {
+		version = v.defaultVersion
+	}
+	if _, ok := v.handlers[version]; !ok {
+		dto.AbortWithError(c, http.StatusNotAcceptable, dto.ErrNotAcceptable, fmt.Sprintf("API version %q is not supported", version))
+		return
+	}
+	v.serve(c, version, v.route(c))
+}
+
+// serve records the version of the request and flags deprecated versions and routes
+// with the Deprecation (RFC 9745) and Sunset (RFC 8594) headers. Calls to them are
+// logged once the request is authenticated, so callers can be told to migrate.
+func (v *apiVersions) serve(c *gin.Context, version, versionedPath string) {
+	c.Set(apiVersionKey, version)
+	c.Header(apiVersionHeader, version)
+
+	route := c.Request.Method + " " + versionedPath
+	deprecation, deprecated := v.deprecations[route]
+	if !deprecated {
+		deprecation, deprecated = v.deprecations[version]
+	}
+	if !deprecated {
+		c.Next()
+		return
+	}
+	c.Header("Deprecation", "@"+strconv.FormatInt(deprecation.Since.Unix(), 10))
+	if !deprecation.Sunset.IsZero() {
+		c.Header("Sunset", deprecation.Sunset.UTC().Format(http.TimeFormat))
+	}
+	c.Next()
+
+	_ = metrics.GetMonitor().GetMetric(metricDeprecatedTotal).Inc([]string{version, route})
+	log.Info("Deprecated API called", log.Fields{
+		"api_version": version,
+		"route":       route,
+		"principal":   c.GetString(principalKey),
+		"client_ip":   c.ClientIP(),
+		"user_agent":  c.Request.UserAgent(),
+	})
+}
+
+// exemptedPath matches the exempt path prefixes against path, and against path without
+// its leading API version segment, so "/ping" exempts "/v1/ping" too.
+func exemptedPath(exempt []string, path string) bool {
+	paths := []string{path}
+	if segment, rest, found := strings.Cut(strings.TrimPrefix(path, "/"), "/"); found && apiVersionPattern.MatchString(segment) {
+		paths = append(paths, "/"+rest)
+	}
+	for _, p := range paths {
+		for _, prefix := range exempt {
+			prefix = strings.TrimSuffix(prefix, "/")
+			if p == prefix || strings.HasPrefix(p, prefix+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// acceptedVersion returns the version parameter of the first Accept media range
+// carrying one, normalized to v<number>.
+func acceptedVersion(accept string) string {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		_, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+		if version := strings.ToLower(params["version"]); version != "" {
+			if !strings.HasPrefix(version, "v") {
+				version = "v" + version
+			}
+			return version
+		}
+	}
+	return ""
+}
+
+// versionDispatcher serves the unversioned routes with the handlers of the negotiated
+// version. Every ServerInterface method is forwarded the same way.
+type versionDispatcher struct {
+	handlers map[string]ServerInterface
+}
+
+var _ ServerInterface = versionDispatcher{}
+
+func (d versionDispatcher) handler(c *gin.Context) ServerInterface {
+	return d.handlers[c.GetString(apiVersionKey)]
+}
+
+func (d versionDispatcher) Ping(c *gin.Context) {
+	d.handler(c).Ping(c)
+}
@@ -0,0 +1,151 @@
+package infrastructure
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type versionedHandler string
+
+func (h versionedHandler) Ping(c *gin.Context) {
+	c.String(http.StatusOK, string(h))
+}
+
+func setupVersionedRouter(t *testing.T, cfg config.VersioningConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	configureMonitor()
+	versions, err := newAPIVersions(map[string]ServerInterface{
+		"v1": versionedHandler("v1"),
+		"v2": versionedHandler("v2"),
+	}, cfg)
+	require.NoError(t, err)
+	router := gin.New()
+	versions.register(router, GinServerOptions{BaseURL: "/"})
+	return router
+}
+
+func versionedRequest(router *gin.Engine, path, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestVersioning_RoutesByPathAndAccept(t *testing.T) {
+	router := setupVersionedRouter(t, config.VersioningConfig{DefaultVersion: "v1"})
+
+	tests := []struct {
+		path, accept, want string
+	}{
+		{"/v1/ping", "", "v1"},
+		{"/v2/ping", "", "v2"},
+		{"/ping", "", "v1"},
+		{"/ping", "application/json; version=2", "v2"},
+		{"/ping", "text/plain, application/json;version=v2", "v2"},
+		// the path wins over the Accept header
+		{"/v1/ping", "application/json; version=2", "v1"},
+	}
+	for _, tt := range tests {
+		w := versionedRequest(router, tt.path, tt.accept)
+		assert.Equal(t, http.StatusOK, w.Code, tt.path)
+		assert.Equal(t, tt.want, w.Body.String(), "%s %s", tt.path, tt.accept)
+		assert.Equal(t, tt.want, w.Header().Get(apiVersionHeader))
+	}
+	assert.Equal(t, "Accept", versionedRequest(router, "/ping", "").Header().Get("Vary"))
+}
+
+func TestVersioning_RouteSettingsApplyToVersionedAndUnversionedPaths(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	configureMonitor()
+	versions, err := newAPIVersions(map[string]ServerInterface{
+		"v1": versionedHandler("v1"),
+		"v2": versionedHandler("v2"),
+	}, config.VersioningConfig{DefaultVersion: "v1"})
+	require.NoError(t, err)
+	router := gin.New()
+	router.Use(newTimeoutMiddleware(config.TimeoutConfig{
+		Default: time.Minute,
+		Routes:  []config.TimeoutRoute{{Method: http.MethodGet, Path: "/v1/ping", Timeout: 0}},
+	}, versions.route))
+	versions.register(router, GinServerOptions{
+		BaseURL: "/",
+		PublicMiddlewares: []gin.HandlerFunc{
+			func(c *gin.Context) {
+				_, hasDeadline := c.Request.Context().Deadline()
+				c.Header("X-Deadline", strconv.FormatBool(hasDeadline))
+			},
+			newRateLimitMiddleware(memory.NewRateLimitStore(), "public", config.RateLimitConfig{
+				Algorithm: "token_bucket", Limit: 100, Window: time.Minute, KeyBy: "ip",
+				Routes: []config.RateLimitRoute{{Method: http.MethodGet, Path: "/v1/ping", Limit: 2, Window: time.Minute}},
+			}, versions.route),
+			newResponseCacheMiddleware(memory.NewResponseCache(100), config.ResponseCacheConfig{
+				MaxBodySize: 1024,
+				Routes:      []config.CacheRoute{{Path: "/v1/ping", TTL: time.Minute}},
+			}, versions.route),
+		},
+	})
+
+	for _, path := range []string{"/ping", "/v1/ping"} {
+		w := versionedRequest(router, path, "")
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.Equal(t, "false", w.Header().Get("X-Deadline"), path)
+		assert.NotEmpty(t, w.Header().Get(cacheStatusHeader), path)
+	}
+	// /ping and /v1/ping share one rate limit bucket
+	assert.Equal(t, http.StatusTooManyRequests, versionedRequest(router, "/ping", "").Code)
+
+	// the same path negotiated to another version is another route
+	w := versionedRequest(router, "/ping", "application/json; version=2")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "true", w.Header().Get("X-Deadline"))
+	assert.Empty(t, w.Header().Get(cacheStatusHeader))
+}
+
+func TestVersioning_UnknownVersionIsNotAcceptable(t *testing.T) {
+	router := setupVersionedRouter(t, config.VersioningConfig{DefaultVersion: "v1"})
+
+	w := versionedRequest(router, "/ping", "application/json; version=9")
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+	assert.Equal(t, http.StatusNotFound, versionedRequest(router, "/v9/ping", "").Code)
+}
+
+func TestVersioning_DeprecationHeaders(t *testing.T) {
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	router := setupVersionedRouter(t, config.VersioningConfig{
+		DefaultVersion: "v1",
+		Deprecations: []config.APIDeprecation{
+			{Target: "v1", Since: since, Sunset: sunset},
+			{Target: "GET /v2/ping", Since: since},
+		},
+	})
+
+	for _, path := range []string{"/v1/ping", "/ping"} {
+		w := versionedRequest(router, path, "")
+		assert.Equal(t, "@1767225600", w.Header().Get("Deprecation"), path)
+		assert.Equal(t, "Thu, 31 Dec 2026 00:00:00 GMT", w.Header().Get("Sunset"), path)
+	}
+
+	w := versionedRequest(router, "/ping", "application/json; version=2")
+	assert.Equal(t, "@1767225600", w.Header().Get("Deprecation"))
+	assert.Empty(t, w.Header().Get("Sunset"))
+}
+
+func TestNewAPIVersions_RejectsInvalidVersions(t *testing.T) {
+	_, err := newAPIVersions(map[string]ServerInterface{"v1": versionedHandler("v1")}, config.VersioningConfig{DefaultVersion: "v2"})
+	assert.Error(t, err)
+	_, err = newAPIVersions(map[string]ServerInterface{"beta": versionedHandler("beta")}, config.VersioningConfig{DefaultVersion: "beta"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,47 @@
+package infrastructure
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	gorillaws "github.com/gorilla/websocket"
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebSocketRoute_AuthenticatesUpgrade(t *testing.T) {
+	t.Setenv("auth.secret", "test_secret")
+	gin.SetMode(gin.TestMode)
+	hub := websocket.NewHub(config.GetWebSocketConfig())
+	router := gin.New()
+	protected := router.Group("/", basicAuthorizationMiddleware)
+	protected.GET("/ws/echo", func(c *gin.Context) {
+		hub.Serve(c, func(conn *websocket.Conn, message websocket.Message) {
+			_ = conn.Send(message)
+		})
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/echo"
+
+	_, resp, err := gorillaws.DefaultDialer.Dial(url, nil)
+	assert.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	ws, _, err := gorillaws.DefaultDialer.Dial(url, http.Header{
+		"Authorization": {"Basic " + base64.StdEncoding.EncodeToString([]byte("test_secret"))},
+	})
+	require.NoError(t, err)
+	defer ws.Close()
+	require.NoError(t, ws.WriteMessage(gorillaws.TextMessage, []byte("hello")))
+	_, data, err := ws.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+}
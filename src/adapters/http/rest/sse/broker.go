@@ -0,0 +1,241 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/src/application/system_services/ports"
+	"github.com/oswaldom-code/api-template-gin/src/domain/entities"
+)
+
+// ErrBrokerClosed is returned once the broker has been closed for shutdown.
+var ErrBrokerClosed = errors.New("event broker is closed")
+
+var (
+	sharedBroker     *Broker
+	sharedBrokerOnce sync.Once
+)
+
+var _ ports.EventPublisher = (*Broker)(nil)
+
+type topic struct {
+	subscribers map[*subscriber]struct{}
+	// replay holds the last events, oldest first
+	replay []entities.Event
+}
+
+type subscriber struct {
+	events chan entities.Event
+	topics []string
+	closed bool
+}
+
+// Broker fans events out to the subscribers of their topic. Events are per instance;
+// each topic keeps the last ones so clients can resume with Last-Event-ID. Topics
+// are dropped once they have no subscribers and their replay window has expired.
+type Broker struct {
+	cfg config.SSEConfig
+	now func() time.Time
+
+	mu        sync.Mutex
+	lastID    uint64
+	topics    map[string]*topic
+	expiredAt time.Time
+	closed    bool
+}
+
+// NewBroker returns an in-process event broker.
+func NewBroker(cfg config.SSEConfig) *Broker {
+	return &Broker{
+		cfg:    cfg,
+		now:    time.Now,
+		topics: map[string]*topic{},
+	}
+}
+
+// SharedBroker returns the process-wide broker, configured from the environment, so
+// the events services publish reach the streams handlers open.
+func SharedBroker() *Broker {
+	sharedBrokerOnce.Do(func() {
+		sharedBroker = NewBroker(config.GetSSEConfig())
+	})
+	return sharedBroker
+}
+
+// Publish assigns the event its ID and sends it to the subscribers of its topic.
+// Subscribers too far behind to take it are disconnected; they resume from the
+// replay buffer when they reconnect.
+func (b *Broker) Publish(_ context.Context, event entities.Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrBrokerClosed
+	}
+	b.lastID++
+	event.ID = strconv.FormatUint(b.lastID, 10)
+	event.PublishedAt = b.now()
+	b.expire()
+
+	t := b.topic(event.Topic)
+	if b.cfg.ReplaySize > 0 {
+		if len(t.replay) >= b.cfg.ReplaySize {
+			t.replay = append(t.replay[:0], t.replay[len(t.replay)-b.cfg.ReplaySize+1:]...)
+		}
+		t.replay = append(t.replay, event)
+	}
+	for s := range t.subscribers {
+		select {
+		case s.events <- event:
+		default:
+			b.remove(s)
+		}
+	}
+	b.release(event.Topic)
+	return nil
+}
+
+// Subscription is a client subscribed to one or more topics.
+type Subscription struct {
+	// Replay holds the buffered events after the Last-Event-ID, in publish order.
+	Replay []entities.Event
+	// Events is closed when the broker shuts down or drops the subscriber for falling behind.
+	Events <-chan entities.Event
+
+	broker     *Broker
+	subscriber *subscriber
+}
+
+// Subscribe registers a subscriber to topics. With a lastEventID, the buffered events
+// published after it are returned for replay; older ones may be gone.
+func (b *Broker) Subscribe(topics []string, lastEventID string) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrBrokerClosed
+	}
+	b.expire()
+	s := &subscriber{events: make(chan entities.Event, b.cfg.BufferSize), topics: unique(topics)}
+	subscription := &Subscription{Events: s.events, broker: b, subscriber: s}
+	after, err := strconv.ParseUint(lastEventID, 10, 64)
+	resume := lastEventID != "" && err == nil
+	cutoff := b.now().Add(-b.cfg.ReplayTTL)
+	for _, name := range s.topics {
+		t := b.topic(name)
+		t.subscribers[s] = struct{}{}
+		if !resume {
+			continue
+		}
+		for _, event := range t.replay {
+			if b.cfg.ReplayTTL > 0 && !event.PublishedAt.After(cutoff) {
+				continue
+			}
+			if id, _ := strconv.ParseUint(event.ID, 10, 64); id > after {
+				subscription.Replay = append(subscription.Replay, event)
+			}
+		}
+	}
+	sort.Slice(subscription.Replay, func(i, j int) bool {
+		x, _ := strconv.ParseUint(subscription.Replay[i].ID, 10, 64)
+		y, _ := strconv.ParseUint(subscription.Replay[j].ID, 10, 64)
+		return x < y
+	})
+	return subscription, nil
+}
+
+// Close unsubscribes; it is safe to call more than once.
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.remove(s.subscriber)
+}
+
+// Close disconnects every subscriber and rejects further publishing, so open streams
+// end and the HTTP server can shut down gracefully.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for _, t := range b.topics {
+		for s := range t.subscribers {
+			b.remove(s)
+		}
+	}
+}
+
+// topic returns the named topic, creating it. Callers hold b.mu.
+func (b *Broker) topic(name string) *topic {
+	t, ok := b.topics[name]
+	if !ok {
+		t = &topic{subscribers: map[*subscriber]struct{}{}}
+		b.topics[name] = t
+	}
+	return t
+}
+
+// remove unsubscribes s from its topics and closes its channel. Callers hold b.mu.
+func (b *Broker) remove(s *subscriber) {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.events)
+	for _, name := range s.topics {
+		if t, ok := b.topics[name]; ok {
+			delete(t.subscribers, s)
+			b.release(name)
+		}
+	}
+}
+
+// release drops the named topic when nothing keeps it: no subscribers and no events
+// left to replay, or no replay window at all. Callers hold b.mu.
+func (b *Broker) release(name string) {
+	t, ok := b.topics[name]
+	if !ok || len(t.subscribers) > 0 {
+		return
+	}
+	if len(t.replay) == 0 || b.cfg.ReplaySize <= 0 || b.cfg.ReplayTTL <= 0 {
+		delete(b.topics, name)
+	}
+}
+
+// expire drops the replay events older than the replay window, and the topics it
+// leaves unused. It sweeps the topics at most once per window. Callers hold b.mu.
+func (b *Broker) expire() {
+	now := b.now()
+	if b.cfg.ReplayTTL <= 0 || now.Sub(b.expiredAt) < b.cfg.ReplayTTL {
+		return
+	}
+	b.expiredAt = now
+	cutoff := now.Add(-b.cfg.ReplayTTL)
+	for name, t := range b.topics {
+		kept := sort.Search(len(t.replay), func(i int) bool {
+			return t.replay[i].PublishedAt.After(cutoff)
+		})
+		t.replay = append(t.replay[:0], t.replay[kept:]...)
+		b.release(name)
+	}
+}
+
+func unique(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	return result
+}
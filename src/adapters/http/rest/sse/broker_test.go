@@ -0,0 +1,141 @@
+package sse
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/src/domain/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBroker() *Broker {
+	return NewBroker(config.SSEConfig{ReplaySize: 3, ReplayTTL: time.Minute, BufferSize: 2})
+}
+
+func ids(events []entities.Event) []string {
+	result := []string{}
+	for _, event := range events {
+		result = append(result, event.ID)
+	}
+	return result
+}
+
+func TestBroker_PublishesToTopicSubscribers(t *testing.T) {
+	broker := testBroker()
+	orders, err := broker.Subscribe([]string{"orders"}, "")
+	require.NoError(t, err)
+	users, err := broker.Subscribe([]string{"users"}, "")
+	require.NoError(t, err)
+
+	require.NoError(t, broker.Publish(context.Background(), entities.Event{Topic: "orders", Type: "created", Data: "42"}))
+
+	event := <-orders.Events
+	assert.Equal(t, "1", event.ID)
+	assert.Equal(t, "created", event.Type)
+	assert.False(t, event.PublishedAt.IsZero())
+	assert.Empty(t, users.Events)
+}
+
+func TestBroker_ReplaysAfterLastEventID(t *testing.T) {
+	broker := testBroker()
+	for _, topic := range []string{"orders", "users", "orders", "orders", "orders"} {
+		require.NoError(t, broker.Publish(context.Background(), entities.Event{Topic: topic}))
+	}
+
+	subscription, err := broker.Subscribe([]string{"orders", "users", "orders"}, "1")
+	require.NoError(t, err)
+	// orders keeps its last 3 events (3, 4, 5), users has 2
+	assert.Equal(t, []string{"2", "3", "4", "5"}, ids(subscription.Replay))
+
+	subscription, err = broker.Subscribe([]string{"orders"}, "")
+	require.NoError(t, err)
+	assert.Empty(t, subscription.Replay, "no replay without Last-Event-ID")
+}
+
+func TestBroker_DropsSlowSubscribers(t *testing.T) {
+	broker := testBroker()
+	subscription, err := broker.Subscribe([]string{"orders"}, "")
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, broker.Publish(context.Background(), entities.Event{Topic: "orders"}))
+	}
+
+	assert.Equal(t, []string{"1", "2"}, ids(drain(subscription)))
+}
+
+func TestBroker_CloseEndsSubscriptions(t *testing.T) {
+	broker := testBroker()
+	subscription, err := broker.Subscribe([]string{"orders"}, "")
+	require.NoError(t, err)
+
+	broker.Close()
+	broker.Close()
+
+	_, open := <-subscription.Events
+	assert.False(t, open)
+	subscription.Close()
+	assert.ErrorIs(t, broker.Publish(context.Background(), entities.Event{Topic: "orders"}), ErrBrokerClosed)
+	_, err = broker.Subscribe([]string{"orders"}, "")
+	assert.ErrorIs(t, err, ErrBrokerClosed)
+}
+
+func TestSubscription_Close(t *testing.T) {
+	broker := testBroker()
+	subscription, err := broker.Subscribe([]string{"orders"}, "")
+	require.NoError(t, err)
+
+	subscription.Close()
+	require.NoError(t, broker.Publish(context.Background(), entities.Event{Topic: "orders"}))
+
+	assert.Empty(t, drain(subscription))
+	assert.Empty(t, broker.topics["orders"].subscribers)
+}
+
+func TestBroker_DropsUnusedTopics(t *testing.T) {
+	broker := testBroker()
+	now := time.Now()
+	broker.now = func() time.Time { return now }
+
+	require.NoError(t, broker.Publish(context.Background(), entities.Event{Topic: "orders"}))
+	subscription, err := broker.Subscribe([]string{"users"}, "")
+	require.NoError(t, err)
+	subscription.Close()
+	assert.NotContains(t, broker.topics, "users", "no subscribers and nothing to replay")
+	assert.Contains(t, broker.topics, "orders", "replay window still open")
+
+	now = now.Add(time.Minute)
+	subscription, err = broker.Subscribe([]string{"orders"}, "0")
+	require.NoError(t, err)
+	assert.Empty(t, subscription.Replay, "expired events are not replayed")
+	subscription.Close()
+	require.NoError(t, broker.Publish(context.Background(), entities.Event{Topic: "invoices"}))
+
+	assert.NotContains(t, broker.topics, "orders")
+	assert.Contains(t, broker.topics, "invoices")
+}
+
+func TestBroker_WithoutReplayWindowDropsTopicsWithTheirLastSubscriber(t *testing.T) {
+	broker := NewBroker(config.SSEConfig{ReplaySize: 3, BufferSize: 2})
+	subscription, err := broker.Subscribe([]string{"orders"}, "")
+	require.NoError(t, err)
+	require.NoError(t, broker.Publish(context.Background(), entities.Event{Topic: "orders"}))
+	require.NoError(t, broker.Publish(context.Background(), entities.Event{Topic: "users"}))
+	assert.Contains(t, broker.topics, "orders")
+	assert.NotContains(t, broker.topics, "users")
+
+	subscription.Close()
+	assert.Empty(t, broker.topics)
+}
+
+// drain reads the events of a closed subscription.
+func drain(subscription *Subscription) []entities.Event {
+	events := []entities.Event{}
+	for event := range subscription.Events {
+		events = append(events, event)
+	}
+	return events
+}
@@ -0,0 +1,91 @@
+package sse
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	ginsse "github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/log"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+	"github.com/oswaldom-code/api-template-gin/src/domain/entities"
+)
+
+// ContentType is the media type of event streams.
+const ContentType = ginsse.ContentType
+
+// Stream subscribes the client to topics and writes their events as Server-Sent Events
+// until the client disconnects, falls behind or the broker shuts down. Events missed
+// since the Last-Event-ID header are replayed first, and heartbeat comments keep idle
+// connections open through proxies.
+func Stream(c *gin.Context, broker *Broker, topics ...string) {
+	if len(topics) == 0 {
+		dto.BadRequest(c, "No event topic to subscribe to")
+		return
+	}
+	subscription, err := broker.Subscribe(topics, c.GetHeader("Last-Event-ID"))
+	if err != nil {
+		if errors.Is(err, ErrBrokerClosed) {
+			dto.Error(c, http.StatusServiceUnavailable, dto.ErrServiceUnavail, "The server is shutting down, retry later")
+			return
+		}
+		dto.InternalError(c, "Event stream could not be opened")
+		return
+	}
+	defer subscription.Close()
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", ContentType)
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	// ask nginx not to buffer the stream
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	if broker.cfg.Retry > 0 {
+		fmt.Fprintf(c.Writer, "retry:%d\n\n", broker.cfg.Retry.Milliseconds())
+	}
+	for _, event := range subscription.Replay {
+		if !write(c, event) {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	var heartbeat <-chan time.Time
+	if broker.cfg.Heartbeat > 0 {
+		ticker := time.NewTicker(broker.cfg.Heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-heartbeat:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return
+			}
+			if !write(c, event) {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+func write(c *gin.Context, event entities.Event) bool {
+	err := ginsse.Encode(c.Writer, ginsse.Event{Id: event.ID, Event: event.Type, Data: event.Data})
+	if err != nil {
+		log.Warn("Failed to write event", log.Fields{"topic": event.Topic, "id": event.ID, "error": err.Error()})
+		return false
+	}
+	return true
+}
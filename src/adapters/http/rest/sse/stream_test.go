@@ -0,0 +1,140 @@
+package sse
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/src/domain/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupStreamServer(t *testing.T, broker *Broker) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/events", func(c *gin.Context) {
+		Stream(c, broker, c.QueryArray("topic")...)
+	})
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func openStream(t *testing.T, url, lastEventID string) (*http.Response, *bufio.Reader) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", ContentType)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp, bufio.NewReader(resp.Body)
+}
+
+// readUntil returns the stream lines up to and including the first one with suffix.
+func readUntil(t *testing.T, reader *bufio.Reader, suffix string) []string {
+	lines := []string{}
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		lines = append(lines, line)
+		if strings.HasSuffix(line, suffix) {
+			return lines
+		}
+	}
+}
+
+// waitForSubscribers waits until topic has n subscribers.
+func waitForSubscribers(t *testing.T, broker *Broker, topic string, n int) {
+	require.Eventually(t, func() bool {
+		broker.mu.Lock()
+		defer broker.mu.Unlock()
+		// topics without subscribers may be dropped
+		if broker.topics[topic] == nil {
+			return n == 0
+		}
+		return len(broker.topics[topic].subscribers) == n
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestStream_WritesEvents(t *testing.T) {
+	broker := NewBroker(config.SSEConfig{Retry: 3 * time.Second, ReplaySize: 10, BufferSize: 10})
+	server := setupStreamServer(t, broker)
+
+	resp, reader := openStream(t, server.URL+"/events?topic=orders", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, ContentType, resp.Header.Get("Content-Type"))
+	assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+	assert.Equal(t, []string{"retry:3000"}, readUntil(t, reader, "retry:3000"))
+
+	waitForSubscribers(t, broker, "orders", 1)
+	require.NoError(t, broker.Publish(context.Background(), entities.Event{
+		Topic: "orders", Type: "created", Data: map[string]string{"id": "42"},
+	}))
+	assert.Equal(t, []string{"", "id:1", "event:created", `data:{"id":"42"}`}, readUntil(t, reader, "}"))
+}
+
+func TestStream_ResumesFromLastEventID(t *testing.T) {
+	broker := NewBroker(config.SSEConfig{ReplaySize: 10, ReplayTTL: time.Minute, BufferSize: 10})
+	server := setupStreamServer(t, broker)
+	for _, data := range []string{"first", "second", "third"} {
+		require.NoError(t, broker.Publish(context.Background(), entities.Event{Topic: "orders", Data: data}))
+	}
+
+	_, reader := openStream(t, server.URL+"/events?topic=orders", "1")
+
+	assert.Equal(t, []string{"id:2", "data:second", "", "id:3", "data:third"}, readUntil(t, reader, "data:third"))
+}
+
+func TestStream_SendsHeartbeats(t *testing.T) {
+	broker := NewBroker(config.SSEConfig{Heartbeat: 10 * time.Millisecond, BufferSize: 10})
+	server := setupStreamServer(t, broker)
+
+	_, reader := openStream(t, server.URL+"/events?topic=orders", "")
+
+	assert.Equal(t, []string{": heartbeat"}, readUntil(t, reader, ": heartbeat"))
+}
+
+func TestStream_CleansUpOnDisconnect(t *testing.T) {
+	broker := NewBroker(config.SSEConfig{BufferSize: 10})
+	server := setupStreamServer(t, broker)
+
+	resp, _ := openStream(t, server.URL+"/events?topic=orders", "")
+	waitForSubscribers(t, broker, "orders", 1)
+	resp.Body.Close()
+
+	waitForSubscribers(t, broker, "orders", 0)
+}
+
+func TestStream_EndsOnBrokerClose(t *testing.T) {
+	broker := NewBroker(config.SSEConfig{BufferSize: 10})
+	server := setupStreamServer(t, broker)
+
+	_, reader := openStream(t, server.URL+"/events?topic=orders", "")
+	waitForSubscribers(t, broker, "orders", 1)
+	broker.Close()
+
+	_, err := reader.ReadString('\n')
+	assert.Error(t, err, "the stream ends")
+
+	resp, _ := openStream(t, server.URL+"/events?topic=orders", "")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestStream_RequiresTopic(t *testing.T) {
+	server := setupStreamServer(t, NewBroker(config.SSEConfig{}))
+
+	resp, _ := openStream(t, server.URL+"/events", "")
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,204 @@
+package websocket
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	gorilla "github.com/gorilla/websocket"
+)
+
+// ErrSlowConsumer is returned by Send when the client does not keep up; the connection
+// is then closed. ErrClosed is returned once the connection is closing.
+var (
+	ErrSlowConsumer = errors.New("websocket client is not reading fast enough")
+	ErrClosed       = errors.New("websocket connection is closed")
+)
+
+// closeGracePeriod bounds the wait for the client to answer a close frame.
+const closeGracePeriod = time.Second
+
+// Reasons a connection was closed, the reason label of the disconnect metric.
+const (
+	reasonClientClosed  = "client_closed"
+	reasonServerClosed  = "server_closed"
+	reasonShutdown      = "shutdown"
+	reasonSlowConsumer  = "slow_consumer"
+	reasonMessageTooBig = "message_too_big"
+	reasonTimeout       = "timeout"
+	reasonError         = "error"
+)
+
+// Conn is an open WebSocket connection. Messages are read by Hub.Serve and written
+// by a goroutine of their own from the send buffer, so Send never blocks.
+type Conn struct {
+	hub   *Hub
+	ws    *gorilla.Conn
+	route string
+	send  chan Message
+
+	closeOnce sync.Once
+	closing   chan struct{}
+	// closeCode is the close frame to send, 0 when none is due.
+	closeCode int
+	reason    string
+}
+
+func newConn(h *Hub, ws *gorilla.Conn, route string) *Conn {
+	return &Conn{
+		hub:     h,
+		ws:      ws,
+		route:   route,
+		send:    make(chan Message, h.cfg.SendBuffer),
+		closing: make(chan struct{}),
+	}
+}
+
+// Send queues message for the client. When the send buffer is full the client is
+// too slow: the connection is closed with 1008 (policy violation).
+func (c *Conn) Send(message Message) error {
+	select {
+	case <-c.closing:
+		return ErrClosed
+	default:
+	}
+	select {
+	case c.send <- message:
+		return nil
+	default:
+		c.closeWith(gorilla.ClosePolicyViolation, reasonSlowConsumer)
+		return ErrSlowConsumer
+	}
+}
+
+// Close sends the queued messages, then closes the connection with 1000 (normal closure).
+func (c *Conn) Close() {
+	c.closeWith(gorilla.CloseNormalClosure, reasonServerClosed)
+}
+
+func (c *Conn) closeWith(code int, reason string) {
+	c.closeOnce.Do(func() {
+		c.closeCode = code
+		c.reason = reason
+		close(c.closing)
+	})
+}
+
+// run reads messages until the connection closes. The read deadline is extended by
+// every pong and message, so silent clients time out.
+func (c *Conn) run(onMessage func(conn *Conn, message Message)) {
+	cfg := c.hub.cfg
+	c.ws.SetReadLimit(cfg.MaxMessageSize)
+	alive := func() error {
+		if cfg.PongTimeout <= 0 {
+			return nil
+		}
+		return c.ws.SetReadDeadline(time.Now().Add(cfg.PongTimeout))
+	}
+	_ = alive()
+	c.ws.SetPongHandler(func(string) error { return alive() })
+
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		c.write()
+	}()
+	defer func() {
+		// a no-op unless onMessage panicked
+		c.closeWith(0, reasonError)
+		<-written
+		_ = c.ws.Close()
+	}()
+	for {
+		messageType, data, err := c.ws.ReadMessage()
+		if err != nil {
+			// the frame answering these, if any, was sent by gorilla already
+			c.closeWith(0, readErrorReason(err))
+			break
+		}
+		_ = alive()
+		onMessage(c, Message{Type: messageType, Data: data})
+	}
+}
+
+// write sends the queued messages and the pings, then the close frame.
+func (c *Conn) write() {
+	cfg := c.hub.cfg
+	var ping <-chan time.Time
+	if cfg.PingInterval > 0 {
+		ticker := time.NewTicker(cfg.PingInterval)
+		defer ticker.Stop()
+		ping = ticker.C
+	}
+	for {
+		select {
+		case message := <-c.send:
+			if err := c.writeMessage(message); err != nil {
+				c.abort()
+				return
+			}
+		case <-ping:
+			if err := c.ws.WriteControl(gorilla.PingMessage, nil, time.Now().Add(cfg.WriteTimeout)); err != nil {
+				c.abort()
+				return
+			}
+		case <-c.closing:
+			if c.closeCode == 0 {
+				return
+			}
+			if c.closeCode == gorilla.CloseNormalClosure {
+				c.flush()
+			}
+			message := gorilla.FormatCloseMessage(c.closeCode, c.reason)
+			if err := c.ws.WriteControl(gorilla.CloseMessage, message, time.Now().Add(cfg.WriteTimeout)); err != nil {
+				_ = c.ws.Close()
+				return
+			}
+			// the reader ends with the client's close frame, or the grace period
+			_ = c.ws.SetReadDeadline(time.Now().Add(closeGracePeriod))
+			return
+		}
+	}
+}
+
+func (c *Conn) writeMessage(message Message) error {
+	if err := c.ws.SetWriteDeadline(time.Now().Add(c.hub.cfg.WriteTimeout)); err != nil {
+		return err
+	}
+	return c.ws.WriteMessage(message.Type, message.Data)
+}
+
+// flush writes the messages still queued.
+func (c *Conn) flush() {
+	for {
+		select {
+		case message := <-c.send:
+			if c.writeMessage(message) != nil {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// abort closes the connection after a failed write, ending the reader.
+func (c *Conn) abort() {
+	c.closeWith(0, reasonError)
+	_ = c.ws.Close()
+}
+
+func readErrorReason(err error) string {
+	var closeErr *gorilla.CloseError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &closeErr):
+		return reasonClientClosed
+	case errors.Is(err, gorilla.ErrReadLimit):
+		return reasonMessageTooBig
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return reasonTimeout
+	}
+	return reasonError
+}
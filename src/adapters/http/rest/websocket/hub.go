@@ -0,0 +1,189 @@
+package websocket
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	gorilla "github.com/gorilla/websocket"
+	metrics "github.com/penglongli/gin-metrics/ginmetrics"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/pkg/log"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/http/rest/dto"
+)
+
+const (
+	TextMessage   = gorilla.TextMessage
+	BinaryMessage = gorilla.BinaryMessage
+
+	metricConnections      = "gin_websocket_connections"
+	metricConnectionsTotal = "gin_websocket_connections_total"
+	metricDisconnectsTotal = "gin_websocket_disconnects_total"
+)
+
+var (
+	sharedHub     *Hub
+	sharedHubOnce sync.Once
+)
+
+// RegisterMetrics adds the WebSocket connection metrics to the gin-metrics monitor.
+func RegisterMetrics() {
+	monitor := metrics.GetMonitor()
+	_ = monitor.AddMetric(&metrics.Metric{
+		Type:        metrics.Gauge,
+		Name:        metricConnections,
+		Description: "the open WebSocket connections.",
+		Labels:      []string{"route"},
+	})
+	_ = monitor.AddMetric(&metrics.Metric{
+		Type:        metrics.Counter,
+		Name:        metricConnectionsTotal,
+		Description: "the accepted WebSocket connections counter.",
+		Labels:      []string{"route"},
+	})
+	_ = monitor.AddMetric(&metrics.Metric{
+		Type:        metrics.Counter,
+		Name:        metricDisconnectsTotal,
+		Description: "the closed WebSocket connections counter.",
+		Labels:      []string{"route", "reason"},
+	})
+}
+
+// Message is a WebSocket data message, TextMessage or BinaryMessage.
+type Message struct {
+	Type int
+	Data []byte
+}
+
+// Hub upgrades requests to WebSocket connections and keeps track of them, so they
+// are counted and closed on shutdown.
+type Hub struct {
+	cfg      config.WebSocketConfig
+	upgrader gorilla.Upgrader
+
+	mu     sync.Mutex
+	conns  map[*Conn]struct{}
+	closed bool
+	active sync.WaitGroup
+}
+
+// NewHub returns a Hub for cfg.
+func NewHub(cfg config.WebSocketConfig) *Hub {
+	h := &Hub{cfg: cfg, conns: map[*Conn]struct{}{}}
+	h.upgrader = gorilla.Upgrader{CheckOrigin: checkOrigin(cfg.AllowedOrigins)}
+	return h
+}
+
+// SharedHub returns the process-wide hub, configured from the environment, which
+// StartServer shuts down.
+func SharedHub() *Hub {
+	sharedHubOnce.Do(func() {
+		sharedHub = NewHub(config.GetWebSocketConfig())
+	})
+	return sharedHub
+}
+
+// Serve upgrades the request and runs the connection until it closes, calling
+// onMessage for every message received, in order. Route middlewares, such as
+// authentication, have already run on the upgrade request.
+func (h *Hub) Serve(c *gin.Context, onMessage func(conn *Conn, message Message)) {
+	if h.isClosed() {
+		dto.Error(c, http.StatusServiceUnavailable, dto.ErrServiceUnavail, "The server is shutting down, retry later")
+		return
+	}
+	upgrader := h.upgrader
+	upgrader.Error = func(w http.ResponseWriter, r *http.Request, status int, reason error) {
+		code := dto.ErrBadRequest
+		if status == http.StatusForbidden {
+			code = dto.ErrForbidden
+		}
+		dto.Error(c, status, code, reason.Error())
+	}
+	ws, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	conn := newConn(h, ws, c.FullPath())
+	if !h.add(conn) {
+		conn.closeWith(gorilla.CloseGoingAway, reasonShutdown)
+	}
+	defer h.remove(conn)
+	conn.run(onMessage)
+}
+
+// Shutdown closes every connection with 1001 (going away) and rejects new ones,
+// then waits for the connections to end or ctx to expire.
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	h.closed = true
+	for conn := range h.conns {
+		conn.closeWith(gorilla.CloseGoingAway, reasonShutdown)
+	}
+	h.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		h.active.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (h *Hub) isClosed() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.closed
+}
+
+// add tracks conn; it is refused once the hub is shut down. The connection is
+// counted either way, remove is always called.
+func (h *Hub) add(conn *Conn) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.active.Add(1)
+	_ = metrics.GetMonitor().GetMetric(metricConnections).Inc([]string{conn.route})
+	_ = metrics.GetMonitor().GetMetric(metricConnectionsTotal).Inc([]string{conn.route})
+	if h.closed {
+		return false
+	}
+	h.conns[conn] = struct{}{}
+	return true
+}
+
+func (h *Hub) remove(conn *Conn) {
+	h.mu.Lock()
+	delete(h.conns, conn)
+	h.mu.Unlock()
+	_ = metrics.GetMonitor().GetMetric(metricConnections).Add([]string{conn.route}, -1)
+	_ = metrics.GetMonitor().GetMetric(metricDisconnectsTotal).Inc([]string{conn.route, conn.reason})
+	log.Debug("WebSocket connection closed", log.Fields{"route": conn.route, "reason": conn.reason})
+	h.active.Done()
+}
+
+// checkOrigin allows requests without an Origin (non-browser clients) and the allowed
+// origins. Without allowed origins, gorilla only allows the request host.
+func checkOrigin(allowed []string) func(r *http.Request) bool {
+	if len(allowed) == 0 {
+		return nil
+	}
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		for _, a := range allowed {
+			if a == "*" || strings.EqualFold(a, origin) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,167 @@
+package websocket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	gorilla "github.com/gorilla/websocket"
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig() config.WebSocketConfig {
+	return config.WebSocketConfig{
+		PingInterval:   time.Minute,
+		PongTimeout:    time.Minute,
+		WriteTimeout:   time.Second,
+		SendBuffer:     8,
+		MaxMessageSize: 16,
+	}
+}
+
+func setupEchoServer(t *testing.T, hub *Hub) string {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ws", func(c *gin.Context) {
+		hub.Serve(c, func(conn *Conn, message Message) {
+			if string(message.Data) == "bye" {
+				_ = conn.Send(message)
+				conn.Close()
+				return
+			}
+			_ = conn.Send(message)
+		})
+	})
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+}
+
+func dial(t *testing.T, url string, header http.Header) *gorilla.Conn {
+	ws, _, err := gorilla.DefaultDialer.Dial(url, header)
+	require.NoError(t, err)
+	t.Cleanup(func() { ws.Close() })
+	return ws
+}
+
+// waitForConnections waits until the hub tracks n connections.
+func waitForConnections(t *testing.T, hub *Hub, n int) {
+	require.Eventually(t, func() bool {
+		hub.mu.Lock()
+		defer hub.mu.Unlock()
+		return len(hub.conns) == n
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestHub_Echo(t *testing.T) {
+	hub := NewHub(testConfig())
+	ws := dial(t, setupEchoServer(t, hub), nil)
+
+	require.NoError(t, ws.WriteMessage(gorilla.TextMessage, []byte("hello")))
+	messageType, data, err := ws.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, gorilla.TextMessage, messageType)
+	assert.Equal(t, "hello", string(data))
+
+	// Close sends the queued reply first
+	require.NoError(t, ws.WriteMessage(gorilla.TextMessage, []byte("bye")))
+	_, data, err = ws.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "bye", string(data))
+	_, _, err = ws.ReadMessage()
+	assert.True(t, gorilla.IsCloseError(err, gorilla.CloseNormalClosure), err)
+	waitForConnections(t, hub, 0)
+}
+
+func TestHub_MessageSizeLimit(t *testing.T) {
+	hub := NewHub(testConfig())
+	ws := dial(t, setupEchoServer(t, hub), nil)
+
+	require.NoError(t, ws.WriteMessage(gorilla.TextMessage, []byte(strings.Repeat("x", 17))))
+
+	_, _, err := ws.ReadMessage()
+	assert.True(t, gorilla.IsCloseError(err, gorilla.CloseMessageTooBig), err)
+	waitForConnections(t, hub, 0)
+}
+
+func TestHub_PingPongKeepalive(t *testing.T) {
+	cfg := testConfig()
+	cfg.PingInterval = 10 * time.Millisecond
+	cfg.PongTimeout = 50 * time.Millisecond
+	hub := NewHub(cfg)
+	url := setupEchoServer(t, hub)
+
+	// a reading client answers the pings and stays connected
+	alive := dial(t, url, nil)
+	go func() {
+		for {
+			if _, _, err := alive.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	// a client that never reads sends no pongs
+	dial(t, url, nil)
+	waitForConnections(t, hub, 2)
+
+	waitForConnections(t, hub, 1)
+	time.Sleep(100 * time.Millisecond)
+	waitForConnections(t, hub, 1)
+}
+
+func TestConn_SlowConsumerIsDisconnected(t *testing.T) {
+	cfg := testConfig()
+	cfg.SendBuffer = 1
+	conn := newConn(NewHub(cfg), nil, "/ws")
+
+	require.NoError(t, conn.Send(Message{Type: TextMessage, Data: []byte("1")}))
+	assert.ErrorIs(t, conn.Send(Message{Type: TextMessage, Data: []byte("2")}), ErrSlowConsumer)
+
+	assert.Equal(t, gorilla.ClosePolicyViolation, conn.closeCode)
+	assert.Equal(t, reasonSlowConsumer, conn.reason)
+	assert.ErrorIs(t, conn.Send(Message{Type: TextMessage, Data: []byte("3")}), ErrClosed)
+}
+
+func TestHub_Shutdown(t *testing.T) {
+	hub := NewHub(testConfig())
+	url := setupEchoServer(t, hub)
+	ws := dial(t, url, nil)
+	waitForConnections(t, hub, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	go func() {
+		// answer the close frame like browsers do
+		_, _, _ = ws.ReadMessage()
+	}()
+	require.NoError(t, hub.Shutdown(ctx))
+	waitForConnections(t, hub, 0)
+
+	_, resp, err := gorilla.DefaultDialer.Dial(url, nil)
+	assert.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestHub_CheckOrigin(t *testing.T) {
+	url := setupEchoServer(t, NewHub(testConfig()))
+	_, resp, err := gorilla.DefaultDialer.Dial(url, http.Header{"Origin": {"https://evil.example.com"}})
+	assert.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode, "same origin only by default")
+
+	cfg := testConfig()
+	cfg.AllowedOrigins = []string{"https://app.example.com"}
+	url = setupEchoServer(t, NewHub(cfg))
+	dial(t, url, http.Header{"Origin": {"https://app.example.com"}})
+	_, resp, err = gorilla.DefaultDialer.Dial(url, http.Header{"Origin": {"https://evil.example.com"}})
+	assert.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
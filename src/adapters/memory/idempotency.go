@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/oswaldom-code/api-template-gin/src/application/system_services/ports"
+	"github.com/oswaldom-code/api-template-gin/src/domain/entities"
+)
+
+// idempotencyStore keeps idempotency records in process. Keys are per instance.
+type idempotencyStore struct {
+	mu        sync.Mutex
+	records   map[string]entities.IdempotencyRecord
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewIdempotencyStore returns an in-memory ports.IdempotencyStore.
+func NewIdempotencyStore() ports.IdempotencyStore {
+	return &idempotencyStore{
+		records: map[string]entities.IdempotencyRecord{},
+		now:     time.Now,
+	}
+}
+
+func (s *idempotencyStore) Reserve(_ context.Context, key, fingerprint string, ttl time.Duration) (entities.IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	if record, ok := s.records[key]; ok && now.Before(record.ExpiresAt) {
+		return record, false, nil
+	}
+	record := entities.IdempotencyRecord{Fingerprint: fingerprint, ExpiresAt: now.Add(ttl)}
+	s.records[key] = record
+	return record, true, nil
+}
+
+func (s *idempotencyStore) Complete(_ context.Context, key string, response entities.IdempotentResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record, ok := s.records[key]; ok {
+		record.Response = &response
+		s.records[key] = record
+	}
+	return nil
+}
+
+func (s *idempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record, ok := s.records[key]; ok && !record.Completed() {
+		delete(s.records, key)
+	}
+	return nil
+}
+
+// sweep drops expired keys, at most once per sweepInterval. Callers hold s.mu.
+func (s *idempotencyStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, record := range s.records {
+		if !now.Before(record.ExpiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
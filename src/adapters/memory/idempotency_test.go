@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oswaldom-code/api-template-gin/src/domain/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyStore_ReserveCompleteReplay(t *testing.T) {
+	store := NewIdempotencyStore()
+	ctx := context.Background()
+
+	record, reserved, err := store.Reserve(ctx, "p:key", "fp1", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+	assert.False(t, record.Completed())
+
+	record, reserved, _ = store.Reserve(ctx, "p:key", "fp2", time.Hour)
+	assert.False(t, reserved, "in progress")
+	assert.Equal(t, "fp1", record.Fingerprint)
+	assert.False(t, record.Completed())
+
+	require.NoError(t, store.Complete(ctx, "p:key", entities.IdempotentResponse{StatusCode: 201, Body: []byte("created")}))
+	record, reserved, _ = store.Reserve(ctx, "p:key", "fp1", time.Hour)
+	assert.False(t, reserved)
+	require.True(t, record.Completed())
+	assert.Equal(t, 201, record.Response.StatusCode)
+	assert.Equal(t, []byte("created"), record.Response.Body)
+
+	require.NoError(t, store.Release(ctx, "p:key"))
+	_, reserved, _ = store.Reserve(ctx, "p:key", "fp1", time.Hour)
+	assert.False(t, reserved, "completed records are not released")
+}
+
+func TestIdempotencyStore_ReleaseAllowsRetry(t *testing.T) {
+	store := NewIdempotencyStore()
+	ctx := context.Background()
+
+	_, _, _ = store.Reserve(ctx, "p:key", "fp1", time.Hour)
+	require.NoError(t, store.Release(ctx, "p:key"))
+
+	_, reserved, _ := store.Reserve(ctx, "p:key", "fp1", time.Hour)
+	assert.True(t, reserved)
+}
+
+func TestIdempotencyStore_Expires(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	store := NewIdempotencyStore().(*idempotencyStore)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, _, _ = store.Reserve(ctx, "p:key", "fp1", time.Minute)
+	_ = store.Complete(ctx, "p:key", entities.IdempotentResponse{StatusCode: 200})
+	now = now.Add(2 * time.Minute)
+
+	record, reserved, _ := store.Reserve(ctx, "p:key", "fp2", time.Minute)
+	assert.True(t, reserved, "expired keys can be reused")
+	assert.Equal(t, "fp2", record.Fingerprint)
+
+	now = now.Add(2 * sweepInterval)
+	_, _, _ = store.Reserve(ctx, "p:other", "fp", time.Minute)
+	assert.NotContains(t, store.records, "p:key")
+}
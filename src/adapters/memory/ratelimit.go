@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/oswaldom-code/api-template-gin/src/application/system_services/ports"
+	"github.com/oswaldom-code/api-template-gin/src/domain/entities"
+)
+
+// sweepInterval is how often idle keys are dropped.
+const sweepInterval = time.Minute
+
+type rateLimitEntry struct {
+	state     entities.RateLimitState
+	expiresAt time.Time
+}
+
+// rateLimitStore keeps rate limit state in process. Limits are per instance.
+type rateLimitStore struct {
+	mu        sync.Mutex
+	entries   map[string]*rateLimitEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewRateLimitStore returns an in-memory ports.RateLimitStore.
+func NewRateLimitStore() ports.RateLimitStore {
+	return &rateLimitStore{
+		entries: map[string]*rateLimitEntry{},
+		now:     time.Now,
+	}
+}
+
+func (s *rateLimitStore) Allow(_ context.Context, key string, policy entities.RateLimitPolicy) (entities.RateLimitDecision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = &rateLimitEntry{}
+		s.entries[key] = entry
+	}
+	decision := policy.Apply(&entry.state, now)
+	entry.expiresAt = policy.ExpiresAt(entry.state)
+	return decision, nil
+}
+
+// sweep drops expired keys, at most once per sweepInterval. Callers hold s.mu.
+func (s *rateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, entry := range s.entries {
+		if now.After(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
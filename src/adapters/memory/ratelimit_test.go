@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oswaldom-code/api-template-gin/src/domain/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitStore_CountsPerKey(t *testing.T) {
+	store := NewRateLimitStore()
+	policy := entities.RateLimitPolicy{Algorithm: entities.TokenBucket, Limit: 1, Window: time.Minute}
+
+	decision, err := store.Allow(context.Background(), "a", policy)
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+
+	decision, _ = store.Allow(context.Background(), "a", policy)
+	assert.False(t, decision.Allowed)
+
+	decision, _ = store.Allow(context.Background(), "b", policy)
+	assert.True(t, decision.Allowed)
+}
+
+func TestRateLimitStore_SweepsExpiredKeys(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	store := NewRateLimitStore().(*rateLimitStore)
+	store.now = func() time.Time { return now }
+	policy := entities.RateLimitPolicy{Algorithm: entities.SlidingWindow, Limit: 5, Window: time.Second}
+
+	_, _ = store.Allow(context.Background(), "idle", policy)
+	now = now.Add(2 * sweepInterval)
+	_, _ = store.Allow(context.Background(), "active", policy)
+
+	assert.NotContains(t, store.entries, "idle")
+	assert.Contains(t, store.entries, "active")
+}
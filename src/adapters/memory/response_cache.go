@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/config"
+	"github.com/oswaldom-code/api-template-gin/src/application/system_services/ports"
+	"github.com/oswaldom-code/api-template-gin/src/domain/entities"
+)
+
+var (
+	sharedResponseCache     ports.ResponseCache
+	sharedResponseCacheOnce sync.Once
+)
+
+type responseCacheEntry struct {
+	key      string
+	response entities.CachedResponse
+}
+
+// responseCache is an LRU of responses with a tag index. Entries are per instance.
+type responseCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	// order holds *responseCacheEntry, most recently used first
+	order   *list.List
+	entries map[string]*list.Element
+	tags    map[string]map[string]struct{}
+}
+
+// NewResponseCache returns an in-memory ports.ResponseCache evicting the least
+// recently used response beyond maxEntries.
+func NewResponseCache(maxEntries int) ports.ResponseCache {
+	return &responseCache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    map[string]*list.Element{},
+		tags:       map[string]map[string]struct{}{},
+	}
+}
+
+// SharedResponseCache returns the process-wide response cache, sized from the
+// configuration, so services invalidate what the HTTP middleware stored.
+func SharedResponseCache() ports.ResponseCache {
+	sharedResponseCacheOnce.Do(func() {
+		sharedResponseCache = NewResponseCache(config.GetResponseCacheConfig().MaxEntries)
+	})
+	return sharedResponseCache
+}
+
+func (s *responseCache) Get(_ context.Context, key string) (entities.CachedResponse, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.entries[key]
+	if !ok {
+		return entities.CachedResponse{}, false, nil
+	}
+	s.order.MoveToFront(element)
+	return element.Value.(*responseCacheEntry).response, true, nil
+}
+
+func (s *responseCache) Set(_ context.Context, key string, response entities.CachedResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, ok := s.entries[key]; ok {
+		s.remove(element)
+	}
+	s.entries[key] = s.order.PushFront(&responseCacheEntry{key: key, response: response})
+	for _, tag := range response.Tags {
+		if s.tags[tag] == nil {
+			s.tags[tag] = map[string]struct{}{}
+		}
+		s.tags[tag][key] = struct{}{}
+	}
+	for s.maxEntries > 0 && s.order.Len() > s.maxEntries {
+		s.remove(s.order.Back())
+	}
+	return nil
+}
+
+func (s *responseCache) InvalidateTags(_ context.Context, tags ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range s.tags[tag] {
+			if element, ok := s.entries[key]; ok {
+				s.remove(element)
+			}
+		}
+	}
+	return nil
+}
+
+// remove drops element and its tag index entries. Callers hold s.mu.
+func (s *responseCache) remove(element *list.Element) {
+	entry := s.order.Remove(element).(*responseCacheEntry)
+	delete(s.entries, entry.key)
+	for _, tag := range entry.response.Tags {
+		delete(s.tags[tag], entry.key)
+		if len(s.tags[tag]) == 0 {
+			delete(s.tags, tag)
+		}
+	}
+}
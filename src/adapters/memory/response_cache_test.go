@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oswaldom-code/api-template-gin/src/domain/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewResponseCache(2)
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "a", entities.CachedResponse{StatusCode: 200}))
+	require.NoError(t, cache.Set(ctx, "b", entities.CachedResponse{StatusCode: 200}))
+	_, found, _ := cache.Get(ctx, "a")
+	require.True(t, found)
+	require.NoError(t, cache.Set(ctx, "c", entities.CachedResponse{StatusCode: 200}))
+
+	_, found, _ = cache.Get(ctx, "b")
+	assert.False(t, found, "b was the least recently used")
+	_, found, _ = cache.Get(ctx, "a")
+	assert.True(t, found)
+	_, found, _ = cache.Get(ctx, "c")
+	assert.True(t, found)
+}
+
+func TestResponseCache_InvalidateTags(t *testing.T) {
+	cache := NewResponseCache(10)
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "order:1", entities.CachedResponse{Tags: []string{"orders", "order:1"}}))
+	require.NoError(t, cache.Set(ctx, "order:2", entities.CachedResponse{Tags: []string{"orders", "order:2"}}))
+	require.NoError(t, cache.Set(ctx, "users", entities.CachedResponse{Tags: []string{"users"}}))
+
+	require.NoError(t, cache.InvalidateTags(ctx, "order:1"))
+	_, found, _ := cache.Get(ctx, "order:1")
+	assert.False(t, found)
+	_, found, _ = cache.Get(ctx, "order:2")
+	assert.True(t, found)
+
+	require.NoError(t, cache.InvalidateTags(ctx, "orders"))
+	_, found, _ = cache.Get(ctx, "order:2")
+	assert.False(t, found)
+	_, found, _ = cache.Get(ctx, "users")
+	assert.True(t, found)
+
+	rc := cache.(*responseCache)
+	assert.NotContains(t, rc.tags, "orders", "empty tags are dropped")
+}
+
+func TestResponseCache_SetReplacesTags(t *testing.T) {
+	cache := NewResponseCache(10)
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "k", entities.CachedResponse{Tags: []string{"old"}}))
+	require.NoError(t, cache.Set(ctx, "k", entities.CachedResponse{Tags: []string{"new"}}))
+	require.NoError(t, cache.InvalidateTags(ctx, "old"))
+
+	_, found, _ := cache.Get(ctx, "k")
+	assert.True(t, found)
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/log"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/repository/models"
+	"github.com/oswaldom-code/api-template-gin/src/application/system_services/ports"
+	"github.com/oswaldom-code/api-template-gin/src/domain/entities"
+)
+
+// idempotencyStore keeps idempotency records in the database so retries can land on any instance.
+type idempotencyStore struct {
+	db *gorm.DB
+
+	mu          sync.Mutex
+	lastCleanup time.Time
+}
+
+// NewIdempotencyStore returns a database backed ports.IdempotencyStore, creating its table if needed.
+func NewIdempotencyStore() (ports.IdempotencyStore, error) {
+	store, err := NewRepository()
+	if err != nil {
+		return nil, err
+	}
+	db := store.(*repository).db
+	if err := db.AutoMigrate(&models.IdempotencyKey{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate idempotency key table: %w", err)
+	}
+	return &idempotencyStore{db: db}, nil
+}
+
+func (s *idempotencyStore) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (entities.IdempotencyRecord, bool, error) {
+	now := time.Now()
+	var record entities.IdempotencyRecord
+	reserved := false
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// an expired key is free again
+		if err := tx.Where("key = ? AND expires_at <= ?", key, now).Delete(&models.IdempotencyKey{}).Error; err != nil {
+			return err
+		}
+		row := models.IdempotencyKey{Key: key, Fingerprint: fingerprint, CreatedAt: now, ExpiresAt: now.Add(ttl)}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&row)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 1 {
+			reserved = true
+			record = entities.IdempotencyRecord{Fingerprint: fingerprint, ExpiresAt: row.ExpiresAt}
+			return nil
+		}
+
+		var existing models.IdempotencyKey
+		if err := tx.First(&existing, "key = ?", key).Error; err != nil {
+			return err
+		}
+		var err error
+		record, err = toIdempotencyRecord(existing)
+		return err
+	})
+	if err != nil {
+		return entities.IdempotencyRecord{}, false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	s.cleanup(now)
+	return record, reserved, nil
+}
+
+func (s *idempotencyStore) Complete(ctx context.Context, key string, response entities.IdempotentResponse) error {
+	header, err := json.Marshal(response.Header)
+	if err != nil {
+		return fmt.Errorf("failed to encode idempotent response header: %w", err)
+	}
+	err = s.db.WithContext(ctx).Model(&models.IdempotencyKey{}).Where("key = ?", key).Updates(map[string]interface{}{
+		"status_code": response.StatusCode,
+		"header":      header,
+		"body":        response.Body,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+func (s *idempotencyStore) Release(ctx context.Context, key string) error {
+	err := s.db.WithContext(ctx).Where("key = ? AND status_code IS NULL", key).Delete(&models.IdempotencyKey{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+func toIdempotencyRecord(row models.IdempotencyKey) (entities.IdempotencyRecord, error) {
+	record := entities.IdempotencyRecord{Fingerprint: row.Fingerprint, ExpiresAt: row.ExpiresAt}
+	if row.StatusCode == nil {
+		return record, nil
+	}
+	response := &entities.IdempotentResponse{StatusCode: *row.StatusCode, Body: row.Body}
+	if len(row.Header) > 0 {
+		if err := json.Unmarshal(row.Header, &response.Header); err != nil {
+			return record, fmt.Errorf("failed to decode idempotent response header: %w", err)
+		}
+	}
+	record.Response = response
+	return record, nil
+}
+
+// cleanup deletes expired rows in the background, at most once per cleanupInterval.
+func (s *idempotencyStore) cleanup(now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.lastCleanup) < cleanupInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastCleanup = now
+	s.mu.Unlock()
+
+	go func() {
+		if err := s.db.Where("expires_at < ?", now).Delete(&models.IdempotencyKey{}).Error; err != nil {
+			log.Warn("Failed to clean up idempotency keys", log.Fields{"error": err.Error()})
+		}
+	}()
+}
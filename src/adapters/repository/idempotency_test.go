@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/oswaldom-code/api-template-gin/src/adapters/repository/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToIdempotencyRecord(t *testing.T) {
+	record, err := toIdempotencyRecord(models.IdempotencyKey{Fingerprint: "fp"})
+	require.NoError(t, err)
+	assert.False(t, record.Completed(), "no status code while in progress")
+
+	status := 201
+	record, err = toIdempotencyRecord(models.IdempotencyKey{
+		Fingerprint: "fp",
+		StatusCode:  &status,
+		Header:      []byte(`{"Location":["/orders/1"]}`),
+		Body:        []byte(`{"id":1}`),
+	})
+	require.NoError(t, err)
+	require.True(t, record.Completed())
+	assert.Equal(t, 201, record.Response.StatusCode)
+	assert.Equal(t, []string{"/orders/1"}, record.Response.Header["Location"])
+	assert.Equal(t, []byte(`{"id":1}`), record.Response.Body)
+
+	_, err = toIdempotencyRecord(models.IdempotencyKey{StatusCode: &status, Header: []byte("{")})
+	assert.Error(t, err)
+}
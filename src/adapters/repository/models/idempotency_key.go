@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// IdempotencyKey is the persisted state of one Idempotency-Key.
+type IdempotencyKey struct {
+	Key         string `gorm:"primaryKey;size:512"`
+	Fingerprint string `gorm:"size:64"`
+	// StatusCode is nil while the first request is in progress.
+	StatusCode *int
+	// Header is the JSON encoded response header.
+	Header    []byte
+	Body      []byte
+	CreatedAt time.Time
+	ExpiresAt time.Time `gorm:"index"`
+}
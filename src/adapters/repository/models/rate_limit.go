@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// RateLimit is the persisted rate limit state of one key.
+type RateLimit struct {
+	Key         string `gorm:"primaryKey;size:255"`
+	Tokens      float64
+	WindowStart *time.Time
+	Count       int
+	PrevCount   int
+	// UpdatedAt is nil until the first request has been counted.
+	UpdatedAt *time.Time `gorm:"autoUpdateTime:false"`
+	ExpiresAt time.Time  `gorm:"index"`
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/oswaldom-code/api-template-gin/pkg/log"
+	"github.com/oswaldom-code/api-template-gin/src/adapters/repository/models"
+	"github.com/oswaldom-code/api-template-gin/src/application/system_services/ports"
+	"github.com/oswaldom-code/api-template-gin/src/domain/entities"
+)
+
+// cleanupInterval is how often expired rows are deleted.
+const cleanupInterval = 5 * time.Minute
+
+// rateLimitStore keeps rate limit state in the database so limits are shared across instances.
+type rateLimitStore struct {
+	db *gorm.DB
+
+	mu          sync.Mutex
+	lastCleanup time.Time
+}
+
+// NewRateLimitStore returns a database backed ports.RateLimitStore, creating its table if needed.
+func NewRateLimitStore() (ports.RateLimitStore, error) {
+	store, err := NewRepository()
+	if err != nil {
+		return nil, err
+	}
+	db := store.(*repository).db
+	if err := db.AutoMigrate(&models.RateLimit{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate rate limit table: %w", err)
+	}
+	return &rateLimitStore{db: db}, nil
+}
+
+func (s *rateLimitStore) Allow(ctx context.Context, key string, policy entities.RateLimitPolicy) (entities.RateLimitDecision, error) {
+	var decision entities.RateLimitDecision
+	now := time.Now()
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// make sure the row exists so concurrent first requests serialize on its lock
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.RateLimit{Key: key, ExpiresAt: now}).Error; err != nil {
+			return err
+		}
+
+		var row models.RateLimit
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&row, "key = ?", key).Error; err != nil {
+			return err
+		}
+
+		state := entities.RateLimitState{
+			Tokens:    row.Tokens,
+			Count:     row.Count,
+			PrevCount: row.PrevCount,
+		}
+		if row.WindowStart != nil {
+			state.WindowStart = *row.WindowStart
+		}
+		if row.UpdatedAt != nil {
+			state.UpdatedAt = *row.UpdatedAt
+		}
+		decision = policy.Apply(&state, now)
+
+		return tx.Model(&row).Updates(map[string]interface{}{
+			"tokens":       state.Tokens,
+			"window_start": state.WindowStart,
+			"count":        state.Count,
+			"prev_count":   state.PrevCount,
+			"updated_at":   state.UpdatedAt,
+			"expires_at":   policy.ExpiresAt(state),
+		}).Error
+	})
+	if err != nil {
+		return entities.RateLimitDecision{}, fmt.Errorf("failed to apply rate limit: %w", err)
+	}
+
+	s.cleanup(now)
+	return decision, nil
+}
+
+// cleanup deletes expired rows in the background, at most once per cleanupInterval.
+func (s *rateLimitStore) cleanup(now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.lastCleanup) < cleanupInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastCleanup = now
+	s.mu.Unlock()
+
+	go func() {
+		if err := s.db.Where("expires_at < ?", now).Delete(&models.RateLimit{}).Error; err != nil {
+			log.Warn("Failed to clean up rate limits", log.Fields{"error": err.Error()})
+		}
+	}()
+}
@@ -1,9 +1,11 @@
 package repository
 
-func (s *repository) TestDb() error {
-	db, err := s.db.DB()
+import "context"
+
+func (s *repository) TestDb(ctx context.Context) error {
+	db, err := s.db.WithContext(ctx).DB()
 	if err != nil {
 		return err
 	}
-	return db.Ping()
+	return db.PingContext(ctx)
 }
@@ -1,12 +1,14 @@
 package system_services
 
 import (
+	"context"
+
 	"github.com/oswaldom-code/api-template-gin/src/adapters/repository"
 	"github.com/oswaldom-code/api-template-gin/src/application/system_services/ports"
 )
 
 type Health interface {
-	TestDb() error
+	TestDb(ctx context.Context) error
 }
 type healthImp struct {
 	r ports.Store
@@ -20,6 +22,6 @@ func HealthService() (Health, error) {
 	return &healthImp{r: repo}, nil
 }
 
-func (p *healthImp) TestDb() error {
-	return p.r.TestDb()
+func (p *healthImp) TestDb(ctx context.Context) error {
+	return p.r.TestDb(ctx)
 }
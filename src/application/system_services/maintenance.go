@@ -0,0 +1,66 @@
+package system_services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/oswaldom-code/api-template-gin/src/domain/entities"
+)
+
+// Maintenance switches the API in and out of maintenance mode.
+type Maintenance interface {
+	Status() entities.MaintenanceMode
+	Enable(message string, retryAfter time.Duration) entities.MaintenanceMode
+	Disable() entities.MaintenanceMode
+}
+
+var (
+	sharedMaintenance     Maintenance
+	sharedMaintenanceOnce sync.Once
+)
+
+type maintenanceImp struct {
+	mu   sync.RWMutex
+	mode entities.MaintenanceMode
+	now  func() time.Time
+}
+
+// MaintenanceService returns the process-wide maintenance mode, shared by the HTTP
+// middleware, the admin endpoint and configuration reloads.
+func MaintenanceService() Maintenance {
+	sharedMaintenanceOnce.Do(func() {
+		sharedMaintenance = NewMaintenance()
+	})
+	return sharedMaintenance
+}
+
+// NewMaintenance returns a Maintenance starting disabled, for tests and tools that
+// need their own state.
+func NewMaintenance() Maintenance {
+	return &maintenanceImp{now: time.Now}
+}
+
+func (m *maintenanceImp) Status() entities.MaintenanceMode {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.mode
+}
+
+// Enable turns maintenance on or updates its message; Since is kept when it already was.
+func (m *maintenanceImp) Enable(message string, retryAfter time.Duration) entities.MaintenanceMode {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	since := m.mode.Since
+	if !m.mode.Enabled {
+		since = m.now()
+	}
+	m.mode = entities.MaintenanceMode{Enabled: true, Message: message, RetryAfter: retryAfter, Since: since}
+	return m.mode
+}
+
+func (m *maintenanceImp) Disable() entities.MaintenanceMode {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mode = entities.MaintenanceMode{}
+	return m.mode
+}
@@ -0,0 +1,25 @@
+package system_services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaintenance_EnableKeepsSince(t *testing.T) {
+	maintenance := NewMaintenance()
+	assert.False(t, maintenance.Status().Enabled)
+
+	first := maintenance.Enable("Migrating", time.Minute)
+	assert.True(t, first.Enabled)
+	assert.False(t, first.Since.IsZero())
+
+	updated := maintenance.Enable("Almost done", 10*time.Second)
+	assert.Equal(t, "Almost done", updated.Message)
+	assert.Equal(t, first.Since, updated.Since)
+	assert.Equal(t, updated, maintenance.Status())
+
+	assert.False(t, maintenance.Disable().Enabled)
+	assert.True(t, maintenance.Status().Since.IsZero())
+}
@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/oswaldom-code/api-template-gin/src/domain/entities"
+)
+
+// EventPublisher delivers events to the clients subscribed to their topic, e.g.
+// over Server-Sent Events. Services publish through it without knowing the transport.
+type EventPublisher interface {
+	// Publish sends event to the subscribers of event.Topic; ID and PublishedAt are set
+	// by the publisher.
+	Publish(ctx context.Context, event entities.Event) error
+}
@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/oswaldom-code/api-template-gin/src/domain/entities"
+)
+
+// IdempotencyStore keeps the first response of requests sent with an Idempotency-Key.
+type IdempotencyStore interface {
+	// Reserve claims key for a request with fingerprint until ttl expires. When the key
+	// is already taken it returns the existing record and false.
+	Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (entities.IdempotencyRecord, bool, error)
+	// Complete stores the response of a reserved key.
+	Complete(ctx context.Context, key string, response entities.IdempotentResponse) error
+	// Release drops a reservation without a response so the request can be retried.
+	Release(ctx context.Context, key string) error
+}
@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/oswaldom-code/api-template-gin/src/domain/entities"
+)
+
+// RateLimitStore counts requests per key. Allow must apply the policy atomically
+// so concurrent requests (and other instances, for shared stores) see each other.
+type RateLimitStore interface {
+	Allow(ctx context.Context, key string, policy entities.RateLimitPolicy) (entities.RateLimitDecision, error)
+}
@@ -1,5 +1,7 @@
 package ports
 
+import "context"
+
 type Store interface {
-	TestDb() error
+	TestDb(ctx context.Context) error
 }
@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/oswaldom-code/api-template-gin/src/domain/entities"
+)
+
+// ResponseCache stores GET responses for the HTTP cache middleware. Services use
+// InvalidateTags to drop the responses built from data they changed.
+type ResponseCache interface {
+	// Get returns the response stored for key, expired or not.
+	Get(ctx context.Context, key string) (entities.CachedResponse, bool, error)
+	Set(ctx context.Context, key string, response entities.CachedResponse) error
+	// InvalidateTags drops every response tagged with one of tags.
+	InvalidateTags(ctx context.Context, tags ...string) error
+}
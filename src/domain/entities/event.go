@@ -0,0 +1,14 @@
+package entities
+
+import "time"
+
+// Event is a message published to the subscribers of a topic.
+type Event struct {
+	// ID is assigned on publish and grows with every event, across topics.
+	ID    string
+	Topic string
+	// Type names the event for clients (the SSE event field), "message" when empty.
+	Type        string
+	Data        any
+	PublishedAt time.Time
+}
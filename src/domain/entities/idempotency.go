@@ -0,0 +1,23 @@
+package entities
+
+import "time"
+
+// IdempotentResponse is the response stored for an Idempotency-Key and replayed on retries.
+type IdempotentResponse struct {
+	StatusCode int
+	Header     map[string][]string
+	Body       []byte
+}
+
+// IdempotencyRecord is the state of an Idempotency-Key. Response is nil while the
+// first request is still in progress.
+type IdempotencyRecord struct {
+	Fingerprint string
+	Response    *IdempotentResponse
+	ExpiresAt   time.Time
+}
+
+// Completed reports whether the first request produced a response to replay.
+func (r IdempotencyRecord) Completed() bool {
+	return r.Response != nil
+}
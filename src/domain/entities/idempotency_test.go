@@ -0,0 +1,12 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyRecord_Completed(t *testing.T) {
+	assert.False(t, IdempotencyRecord{Fingerprint: "fp"}.Completed())
+	assert.True(t, IdempotencyRecord{Fingerprint: "fp", Response: &IdempotentResponse{StatusCode: 201}}.Completed())
+}
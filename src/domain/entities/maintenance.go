@@ -0,0 +1,13 @@
+package entities
+
+import "time"
+
+// MaintenanceMode tells whether the API answers 503 while operators work on it.
+type MaintenanceMode struct {
+	Enabled bool
+	// Message and RetryAfter are sent to clients, the configured ones when empty.
+	Message    string
+	RetryAfter time.Duration
+	// Since is when maintenance started, zero when disabled.
+	Since time.Time
+}
@@ -0,0 +1,120 @@
+package entities
+
+import (
+	"math"
+	"time"
+)
+
+type RateLimitAlgorithm string
+
+const (
+	TokenBucket   RateLimitAlgorithm = "token_bucket"
+	SlidingWindow RateLimitAlgorithm = "sliding_window"
+)
+
+// RateLimitPolicy allows Limit requests per Window.
+type RateLimitPolicy struct {
+	Algorithm RateLimitAlgorithm
+	Limit     int
+	Window    time.Duration
+}
+
+// RateLimitState is what a store keeps per key between requests.
+// Token bucket uses Tokens and UpdatedAt, sliding window uses WindowStart, Count and PrevCount.
+type RateLimitState struct {
+	Tokens      float64
+	WindowStart time.Time
+	Count       int
+	PrevCount   int
+	UpdatedAt   time.Time
+}
+
+// RateLimitDecision is the outcome of a request against a policy.
+type RateLimitDecision struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the limit is fully available again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request may be allowed (denied requests only).
+	RetryAfter time.Duration
+}
+
+// ExpiresAt is when an idle state no longer affects decisions and can be dropped.
+func (p RateLimitPolicy) ExpiresAt(state RateLimitState) time.Time {
+	return state.UpdatedAt.Add(2 * p.Window)
+}
+
+// Apply counts one request at now, updating state, and returns the decision.
+func (p RateLimitPolicy) Apply(state *RateLimitState, now time.Time) RateLimitDecision {
+	if p.Algorithm == SlidingWindow {
+		return p.applySlidingWindow(state, now)
+	}
+	return p.applyTokenBucket(state, now)
+}
+
+func (p RateLimitPolicy) applyTokenBucket(state *RateLimitState, now time.Time) RateLimitDecision {
+	capacity := float64(p.Limit)
+	rate := capacity / p.Window.Seconds() // tokens per second
+
+	if state.UpdatedAt.IsZero() {
+		state.Tokens = capacity
+	} else if elapsed := now.Sub(state.UpdatedAt).Seconds(); elapsed > 0 {
+		state.Tokens = math.Min(capacity, state.Tokens+elapsed*rate)
+	}
+	state.UpdatedAt = now
+
+	decision := RateLimitDecision{Limit: p.Limit}
+	if state.Tokens >= 1 {
+		state.Tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = seconds((1 - state.Tokens) / rate)
+	}
+	decision.Remaining = int(math.Floor(state.Tokens))
+	decision.Reset = seconds((capacity - state.Tokens) / rate)
+	return decision
+}
+
+// applySlidingWindow uses the sliding window counter approximation: the previous
+// fixed window is weighted by how much of it still overlaps the sliding window.
+func (p RateLimitPolicy) applySlidingWindow(state *RateLimitState, now time.Time) RateLimitDecision {
+	windowStart := now.Truncate(p.Window)
+	if !state.WindowStart.Equal(windowStart) {
+		if state.WindowStart.Equal(windowStart.Add(-p.Window)) {
+			state.PrevCount = state.Count
+		} else {
+			state.PrevCount = 0
+		}
+		state.Count = 0
+		state.WindowStart = windowStart
+	}
+	state.UpdatedAt = now
+
+	elapsed := now.Sub(windowStart)
+	weight := 1 - elapsed.Seconds()/p.Window.Seconds()
+	estimated := float64(state.PrevCount)*weight + float64(state.Count)
+	untilWindowEnd := p.Window - elapsed
+
+	decision := RateLimitDecision{Limit: p.Limit, Reset: untilWindowEnd}
+	if estimated+1 <= float64(p.Limit) {
+		state.Count++
+		decision.Allowed = true
+		estimated++
+	} else if state.Count+1 > p.Limit || state.PrevCount == 0 {
+		decision.RetryAfter = untilWindowEnd
+	} else {
+		// wait until the previous window's weight leaves room for one request
+		maxWeight := float64(p.Limit-1-state.Count) / float64(state.PrevCount)
+		decision.RetryAfter = seconds((1-maxWeight)*p.Window.Seconds() - elapsed.Seconds())
+	}
+	decision.Remaining = int(math.Max(0, float64(p.Limit)-math.Ceil(estimated)))
+	return decision
+}
+
+func seconds(value float64) time.Duration {
+	if value < 0 {
+		return 0
+	}
+	return time.Duration(value * float64(time.Second))
+}
@@ -0,0 +1,64 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket_AllowsBurstThenRefills(t *testing.T) {
+	policy := RateLimitPolicy{Algorithm: TokenBucket, Limit: 3, Window: 3 * time.Second}
+	state := RateLimitState{}
+	now := time.Unix(1700000000, 0)
+
+	for i := 2; i >= 0; i-- {
+		decision := policy.Apply(&state, now)
+		assert.True(t, decision.Allowed)
+		assert.Equal(t, i, decision.Remaining)
+	}
+
+	denied := policy.Apply(&state, now)
+	assert.False(t, denied.Allowed)
+	assert.Equal(t, time.Second, denied.RetryAfter)
+	assert.Equal(t, 3*time.Second, denied.Reset)
+
+	// one token per second is refilled
+	assert.True(t, policy.Apply(&state, now.Add(time.Second)).Allowed)
+	assert.False(t, policy.Apply(&state, now.Add(time.Second)).Allowed)
+}
+
+func TestSlidingWindow_LimitsWithinWindow(t *testing.T) {
+	policy := RateLimitPolicy{Algorithm: SlidingWindow, Limit: 2, Window: time.Minute}
+	state := RateLimitState{}
+	start := time.Unix(1700000040, 0).Truncate(time.Minute)
+
+	assert.True(t, policy.Apply(&state, start).Allowed)
+	assert.True(t, policy.Apply(&state, start.Add(10*time.Second)).Allowed)
+
+	denied := policy.Apply(&state, start.Add(20*time.Second))
+	assert.False(t, denied.Allowed)
+	assert.Equal(t, 0, denied.Remaining)
+	assert.Equal(t, 40*time.Second, denied.RetryAfter)
+}
+
+func TestSlidingWindow_WeightsPreviousWindow(t *testing.T) {
+	policy := RateLimitPolicy{Algorithm: SlidingWindow, Limit: 2, Window: time.Minute}
+	state := RateLimitState{}
+	start := time.Unix(1700000040, 0).Truncate(time.Minute)
+
+	policy.Apply(&state, start)
+	policy.Apply(&state, start.Add(30*time.Second))
+
+	// 15s into the next window the previous two requests still weigh 1.5
+	denied := policy.Apply(&state, start.Add(75*time.Second))
+	assert.False(t, denied.Allowed)
+	assert.Equal(t, 15*time.Second, denied.RetryAfter)
+
+	// after 30s their weight drops to 1, leaving room for one request
+	assert.True(t, policy.Apply(&state, start.Add(90*time.Second)).Allowed)
+
+	// two windows later the history is gone
+	assert.True(t, policy.Apply(&state, start.Add(200*time.Second)).Allowed)
+	assert.Equal(t, 0, state.PrevCount)
+}
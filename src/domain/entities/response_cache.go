@@ -0,0 +1,27 @@
+package entities
+
+import "time"
+
+// CachedResponse is a GET response served from the response cache until ExpiresAt.
+// Tags group responses so they can be invalidated together.
+type CachedResponse struct {
+	StatusCode int
+	Header     map[string][]string
+	Body       []byte
+	Tags       []string
+	StoredAt   time.Time
+	ExpiresAt  time.Time
+}
+
+// Fresh reports whether the response can still be served at now.
+func (r CachedResponse) Fresh(now time.Time) bool {
+	return now.Before(r.ExpiresAt)
+}
+
+// Age is how long the response has been cached at now, in the Age header's whole seconds.
+func (r CachedResponse) Age(now time.Time) time.Duration {
+	if now.Before(r.StoredAt) {
+		return 0
+	}
+	return now.Sub(r.StoredAt).Truncate(time.Second)
+}
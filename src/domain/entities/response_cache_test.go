@@ -0,0 +1,18 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedResponse_FreshAndAge(t *testing.T) {
+	stored := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	response := CachedResponse{StoredAt: stored, ExpiresAt: stored.Add(time.Minute)}
+
+	assert.True(t, response.Fresh(stored.Add(59*time.Second)))
+	assert.False(t, response.Fresh(stored.Add(time.Minute)))
+	assert.Equal(t, 42*time.Second, response.Age(stored.Add(42500*time.Millisecond)))
+	assert.Zero(t, response.Age(stored.Add(-time.Second)))
+}